@@ -0,0 +1,122 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Code is the kind of an error, which the controllers map to the status of the response.
+type Code string
+
+const (
+	// NotFound is the code of the error which is returned when the requested data doesn't exist.
+	NotFound Code = "not_found"
+	// Conflict is the code of the error which is returned when the data conflicts with the stored one,
+	// such as a duplicate name.
+	Conflict Code = "conflict"
+	// Validation is the code of the error which is returned when the given data is invalid.
+	Validation Code = "validation"
+	// Timeout is the code of the error which is returned when a query or a transaction runs out of time.
+	Timeout Code = "timeout"
+	// Internal is the code of the other errors, such as the failure of the database.
+	Internal Code = "internal"
+)
+
+var (
+	// ErrNotFound matches any error of NotFound by errors.Is.
+	ErrNotFound = &Error{Code: NotFound}
+	// ErrConflict matches any error of Conflict by errors.Is.
+	ErrConflict = &Error{Code: Conflict}
+	// ErrValidation matches any error of Validation by errors.Is.
+	ErrValidation = &Error{Code: Validation}
+	// ErrTimeout matches any error of Timeout by errors.Is.
+	ErrTimeout = &Error{Code: Timeout}
+	// ErrInternal matches any error of Internal by errors.Is.
+	ErrInternal = &Error{Code: Internal}
+)
+
+// Error is an error of this application, which has the code and may wrap the error which caused it.
+type Error struct {
+	Code    Code
+	Message string
+	Cause   error
+}
+
+// New is constructor.
+func New(code Code, msg string) *Error {
+	return &Error{Code: code, Message: msg}
+}
+
+// Wrap returns the error of the code which is caused by err. It returns nil when err is nil.
+func Wrap(err error, code Code, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: msg, Cause: err}
+}
+
+// Error returns the message followed by the message of the cause.
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = string(e.Code)
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the cause, so errors.Is and errors.As see through this error.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is the sentinel of the code of this error, such as ErrNotFound.
+// The errors with messages match only themselves.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Cause == nil && t.Code == e.Code
+}
+
+// LogFields returns the code and the messages of the chain of the causes, which logger.Err adds to the log.
+func (e *Error) LogFields() []zap.Field {
+	var causes []string
+	for cause := e.Unwrap(); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+	return []zap.Field{zap.String("code", string(e.Code)), zap.Strings("causes", causes)}
+}
+
+// CodeOf returns the code of the first Error in the chain of err, or of the sentinel which err matches,
+// such as model.ValidationErrors. It returns Internal when there is none.
+func CodeOf(err error) Code {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	for _, sentinel := range []*Error{ErrNotFound, ErrConflict, ErrValidation, ErrTimeout} {
+		if errors.Is(err, sentinel) {
+			return sentinel.Code
+		}
+	}
+	return Internal
+}
+
+// HTTPStatus returns the status of the response for the code.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case Validation:
+		return http.StatusBadRequest
+	case Timeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
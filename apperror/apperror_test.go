@@ -0,0 +1,61 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("record not found")
+	err := fmt.Errorf("service: %w", Wrap(cause, NotFound, "failed to find the category"))
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "service: failed to find the category: record not found", err.Error())
+}
+
+func TestError_IsNotOtherMessages(t *testing.T) {
+	inUse := New(Conflict, "the category is referenced by books")
+	duplicate := New(Conflict, "the name is used")
+
+	assert.ErrorIs(t, inUse, ErrConflict)
+	assert.ErrorIs(t, inUse, inUse)
+	assert.NotErrorIs(t, inUse, duplicate)
+}
+
+func TestError_As(t *testing.T) {
+	err := fmt.Errorf("service: %w", Wrap(errors.New("deadline"), Timeout, "query timeout"))
+
+	var appErr *Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, Timeout, appErr.Code)
+		assert.Equal(t, "query timeout", appErr.Message)
+	}
+}
+
+func TestWrap_Nil(t *testing.T) {
+	assert.NoError(t, Wrap(nil, Internal, "failed"))
+}
+
+func TestError_LogFields(t *testing.T) {
+	err := Wrap(fmt.Errorf("tx: %w", errors.New("UNIQUE constraint failed")), Conflict, "failed to create")
+
+	fields := err.(*Error).LogFields()
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, "code", fields[0].Key)
+		assert.Equal(t, "conflict", fields[0].String)
+		assert.Equal(t, "causes", fields[1].Key)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, NotFound, CodeOf(fmt.Errorf("wrapped: %w", New(NotFound, "missing"))))
+	assert.Equal(t, Internal, CodeOf(errors.New("unknown")))
+	assert.Equal(t, http.StatusConflict, Conflict.HTTPStatus())
+	assert.Equal(t, http.StatusInternalServerError, CodeOf(nil).HTTPStatus())
+}
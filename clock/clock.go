@@ -0,0 +1,78 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of the current time, such as Real and Fake.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the clock of the system, which is used unless another one is set by Use.
+type Real struct{}
+
+// Now returns the current time of the system.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Func is the adapter which allows the function returning the current time, such as Fake.Now, to be a Clock.
+type Func func() time.Time
+
+// Now returns the time returned by the function.
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// current is the clock which returns the current time. It is Real unless it is replaced by Use in the tests.
+var current Clock = Real{}
+
+// Now returns the current time of the clock, which the timestamp columns, the audit log and the sql logger use.
+func Now() time.Time {
+	return current.Now()
+}
+
+// Since returns the time elapsed since t by the current time of Now.
+func Since(t time.Time) time.Duration {
+	return current.Now().Sub(t)
+}
+
+// Use replaces the clock, such as a Fake, and returns the function which restores the previous one.
+// It isn't safe to call it while the time is read, so it is for the tests.
+func Use(c Clock) (restore func()) {
+	previous := current
+	current = c
+	return func() { current = previous }
+}
+
+// Set replaces the clock by the function which returns the current time like Use.
+func Set(fn func() time.Time) (restore func()) {
+	return Use(Func(fn))
+}
+
+// Fake is the clock for the tests, which stays at the same time until it is advanced.
+type Fake struct {
+	mutex   sync.Mutex
+	current time.Time
+}
+
+// NewFake is constructor for Fake which starts at the given time.
+func NewFake(start time.Time) *Fake {
+	return &Fake{current: start}
+}
+
+// Now returns the current time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.current
+}
+
+// Advance moves the fake clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.current = f.current.Add(d)
+}
@@ -0,0 +1,36 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake_Advance(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	restore := Set(fake.Now)
+	defer restore()
+
+	assert.Equal(t, start, Now())
+	fake.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), Now())
+	assert.Equal(t, time.Minute, Since(start))
+}
+
+func TestSet_Restore(t *testing.T) {
+	restore := Set(NewFake(time.Time{}).Now)
+	restore()
+
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+}
+
+func TestUse(t *testing.T) {
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	restore := Use(NewFake(start))
+
+	assert.Equal(t, start, Now())
+	restore()
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+}
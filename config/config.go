@@ -2,86 +2,136 @@ package config
 
 import (
 	"embed"
-	"flag"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/ybkuroki/go-webapp-sample/util"
-	"gopkg.in/yaml.v3"
 )
 
 // Config represents the composition of yml settings.
 type Config struct {
 	Database struct {
-		Dialect   string `default:"sqlite3"`
-		Host      string `default:"book.db"`
-		Port      string
-		Dbname    string
-		Username  string
-		Password  string
-		Migration bool `default:"false"`
-	}
+		Dialect   string `json:"dialect" yaml:"dialect" toml:"dialect" default:"sqlite3"`
+		Host      string `json:"host" yaml:"host" toml:"host" default:"book.db"`
+		Port      string `json:"port" yaml:"port" toml:"port"`
+		Dbname    string `json:"dbname" yaml:"dbname" toml:"dbname"`
+		Username  string `json:"username" yaml:"username" toml:"username"`
+		Password  string `json:"password" yaml:"password" toml:"password" mask:"true"`
+		DSN       string `json:"dsn" yaml:"dsn" toml:"dsn" mask:"true"`
+		Migration bool   `json:"migration" yaml:"migration" toml:"migration" default:"false"`
+		// ApplyMigrations applies the pending versioned migrations at startup. When it is disabled,
+		// the application refuses to start with the pending migrations in production.
+		ApplyMigrations bool `json:"apply_migrations" yaml:"apply_migrations" toml:"apply_migrations" default:"false"`
+		// SlowTransactionThreshold is the duration of a transaction from which it is logged as slow. It is 1s by default.
+		SlowTransactionThreshold Duration `json:"slow_transaction_threshold" yaml:"slow_transaction_threshold" toml:"slow_transaction_threshold" unit:"ms"` //nolint:lll
+		// QueryTimeout is the deadline of a statement whose context has no stricter one, such as 30s.
+		// Zero means no deadline.
+		QueryTimeout Duration `json:"query_timeout" yaml:"query_timeout" toml:"query_timeout" unit:"ms"`
+		// TransactionTimeout is the deadline of a transaction, which is usually longer than QueryTimeout.
+		// Zero means no deadline.
+		TransactionTimeout Duration `json:"transaction_timeout" yaml:"transaction_timeout" toml:"transaction_timeout" unit:"ms"` //nolint:lll
+		// SQLComment appends the route of the request to the statements as a comment, such as /* route='...' */,
+		// so the queries in the monitor of the database are attributed to the endpoints.
+		SQLComment bool `json:"sql_comment" yaml:"sql_comment" toml:"sql_comment" default:"false"`
+	} `json:"database" yaml:"database" toml:"database"`
 	Redis struct {
-		Enabled            bool `default:"false"`
-		ConnectionPoolSize int  `yaml:"connection_pool_size" default:"10"`
-		Host               string
-		Port               string
-	}
+		Enabled            bool   `json:"enabled" yaml:"enabled" toml:"enabled" default:"false"`
+		ConnectionPoolSize int    `json:"connection_pool_size" yaml:"connection_pool_size" toml:"connection_pool_size" default:"10"` //nolint:lll
+		Host               string `json:"host" yaml:"host" toml:"host"`
+		Port               string `json:"port" yaml:"port" toml:"port"`
+	} `json:"redis" yaml:"redis" toml:"redis"`
 	Extension struct {
-		MasterGenerator bool `yaml:"master_generator" default:"false"`
-		CorsEnabled     bool `yaml:"cors_enabled" default:"false"`
-		SecurityEnabled bool `yaml:"security_enabled" default:"false"`
-	}
+		MasterGenerator bool `json:"master_generator" yaml:"master_generator" toml:"master_generator" default:"false"`
+		CorsEnabled     bool `json:"cors_enabled" yaml:"cors_enabled" toml:"cors_enabled" default:"false"`
+		SecurityEnabled bool `json:"security_enabled" yaml:"security_enabled" toml:"security_enabled" default:"false"`
+		// DebugEndpoints serves the APIs under /api/admin/debug, which expose the internals such as the log files.
+		// Only the admin must be allowed to access them by security.admin_path.
+		DebugEndpoints bool `json:"debug_endpoints" yaml:"debug_endpoints" toml:"debug_endpoints" default:"false"`
+	} `json:"extension" yaml:"extension" toml:"extension"`
 	Log struct {
-		RequestLogFormat string `yaml:"request_log_format" default:"${remote_ip} ${account_name} ${uri} ${method} ${status}"`
-	}
+		RequestLogFormat string `json:"request_log_format" yaml:"request_log_format" toml:"request_log_format" default:"${remote_ip} ${account_name} ${uri} ${method} ${status}"` //nolint:lll
+		// AccessLog writes the structured access log of every request, in addition to the request log of the format.
+		AccessLog bool `json:"access_log" yaml:"access_log" toml:"access_log" default:"false"`
+	} `json:"log" yaml:"log" toml:"log"`
 	StaticContents struct {
-		Enabled bool `default:"false"`
-	}
+		Enabled bool `json:"enabled" yaml:"enabled" toml:"enabled" default:"false"`
+	} `json:"staticcontents" yaml:"staticcontents" toml:"staticcontents"`
 	Swagger struct {
-		Enabled bool `default:"false"`
-		Path    string
-	}
+		Enabled bool   `json:"enabled" yaml:"enabled" toml:"enabled" default:"false"`
+		Path    string `json:"path" yaml:"path" toml:"path"`
+	} `json:"swagger" yaml:"swagger" toml:"swagger"`
 	Security struct {
-		AuthPath    []string `yaml:"auth_path"`
-		ExculdePath []string `yaml:"exclude_path"`
-		UserPath    []string `yaml:"user_path"`
-		AdminPath   []string `yaml:"admin_path"`
-	}
+		AuthPath    []string `json:"auth_path" yaml:"auth_path" toml:"auth_path"`
+		ExculdePath []string `json:"exclude_path" yaml:"exclude_path" toml:"exclude_path"`
+		UserPath    []string `json:"user_path" yaml:"user_path" toml:"user_path"`
+		AdminPath   []string `json:"admin_path" yaml:"admin_path" toml:"admin_path"`
+		// IDEncoding writes the IDs of the categories in the responses as the opaque tokens encoded by IDEncodingKey,
+		// so the auto-increment IDs aren't exposed.
+		IDEncoding    bool   `json:"id_encoding" yaml:"id_encoding" toml:"id_encoding" default:"false"`
+		IDEncodingKey string `json:"id_encoding_key" yaml:"id_encoding_key" toml:"id_encoding_key" mask:"true"`
+	} `json:"security" yaml:"security" toml:"security"`
 }
 
 const (
 	// DEV represents development environment
 	DEV = "develop"
+	// TST represents test environment
+	TST = "test"
 	// PRD represents production environment
 	PRD = "production"
 	// DOC represents docker container
 	DOC = "docker"
+	// K8S represents kubernetes
+	K8S = "k8s"
 )
 
-// LoadAppConfig reads the settings written to the yml file
-func LoadAppConfig(yamlFile embed.FS) (*Config, string) {
-	var env *string
-	if value := os.Getenv("WEB_APP_ENV"); value != "" {
-		env = &value
-	} else {
-		env = flag.String("env", "develop", "To switch configurations.")
-		flag.Parse()
-	}
+// LoadAppConfig reads the settings written to the yml, json or toml file in the configuration directory,
+// or embedded into the binary. The file is registered to be reloaded.
+func LoadAppConfig(configFile fs.FS) (*Config, string) {
+	env := GetEnv()
 
-	file, err := yamlFile.ReadFile(fmt.Sprintf(AppConfigPath, *env))
+	config, name, err := loadAppConfig(configFile, env)
 	if err != nil {
-		fmt.Printf("Failed to read application.%s.yml: %s", *env, err)
+		fmt.Printf("Failed to read application.%s configuration: %s", env, err)
 		os.Exit(ErrExitStatus)
 	}
+	setLoaded(config)
+	AddReloadSource(DiskFile(name), config, func() (interface{}, error) {
+		reloaded, _, err := loadAppConfig(configFile, env)
+		if err != nil {
+			return nil, err
+		}
+		if errs := reloaded.Validate(); len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return reloaded, nil
+	})
 
+	return config, env
+}
+
+func loadAppConfig(configFile fs.FS, env string) (*Config, string, error) {
 	config := &Config{}
-	if err := yaml.Unmarshal(file, config); err != nil {
-		fmt.Printf("Failed to read application.%s.yml: %s", *env, err)
-		os.Exit(ErrExitStatus)
+	name, err := ReadConfig(configFile, fmt.Sprintf(AppConfigName, env), config)
+	if err != nil {
+		return nil, "", err
 	}
+	if err := OverrideWithEnv(config); err != nil {
+		return nil, "", fmt.Errorf("failed to override %s: %w", name, err)
+	}
+	config.resolvePaths(GetConfigDir())
+	return config, name, nil
+}
 
-	return config, *env
+// resolvePaths resolves the relative file paths in the settings against the given directory,
+// which are the database file of SQLite written in database.host or database.dsn.
+func (c *Config) resolvePaths(dir string) {
+	if c.Database.Dialect == "" || c.Database.Dialect == "sqlite3" {
+		c.Database.Host = resolvePath(dir, c.Database.Host)
+		c.Database.DSN = resolvePath(dir, c.Database.DSN)
+	}
 }
 
 // LoadMessagesConfig loads the messages.properties.
@@ -4,12 +4,12 @@ package config
 const ErrExitStatus int = 2
 
 const (
-	// AppConfigPath is the path of application.yml.
-	AppConfigPath = "resources/config/application.%s.yml"
+	// AppConfigName is the name of application.yml without the extension.
+	AppConfigName = "application.%s"
 	// MessagesConfigPath is the path of messages.properties.
 	MessagesConfigPath = "resources/config/messages.properties"
-	// LoggerConfigPath is the path of zaplogger.yml.
-	LoggerConfigPath = "resources/config/zaplogger.%s.yml"
+	// LoggerConfigName is the name of zaplogger.yml without the extension.
+	LoggerConfigName = "zaplogger.%s"
 )
 
 // PasswordHashCost is hash cost for a password.
@@ -44,4 +44,10 @@ const (
 const (
 	// APIHealth represents the API to get the status of this application.
 	APIHealth = API + "/health"
+	// APIAdmin represents the group of the API which only the admin can access.
+	APIAdmin = API + "/admin"
+	// APIDebugLogger represents the API to get the diagnostics of the logger.
+	APIDebugLogger = APIAdmin + "/debug/logger"
+	// APIDebugLogs represents the API to get the recent logs kept in memory.
+	APIDebugLogs = APIAdmin + "/debug/logs"
 )
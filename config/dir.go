@@ -0,0 +1,178 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// ConfigDirVariable is the environment variable to specify the directory of the configuration files.
+	ConfigDirVariable = "CONFIG_DIR"
+	// configDirFlagName is the name of the command-line flag to specify the directory of the configuration files.
+	configDirFlagName = "configdir"
+	// EmbeddedConfigDir is the directory of the configuration files embedded into the binary.
+	EmbeddedConfigDir = "config"
+	// ConfigSourceVariable is the environment variable to force the source of the configuration files.
+	ConfigSourceVariable = "CONFIG_SOURCE"
+	// SourceFile forces to read the configuration files from the configuration directory.
+	SourceFile = "file"
+	// SourceEmbedded forces to read the configuration files embedded into the binary.
+	SourceEmbedded = "embedded"
+	// embeddedPrefix is the prefix of the names of the embedded configuration files returned by ReadConfig.
+	embeddedPrefix = "embedded "
+)
+
+var (
+	configDirFlag = flag.String(configDirFlagName, "", "The directory of the configuration files.")
+
+	configDirOnce     sync.Once
+	resolvedConfigDir string
+)
+
+// GetConfigDir returns the absolute path of the directory of the configuration files.
+// The command-line flag takes precedence over the environment variable.
+// When neither is provided, it is the directory of the executable if the configuration files are there,
+// otherwise the current working directory.
+// The directory is resolved only once, so that every consumer sees the same value.
+func GetConfigDir() string {
+	configDirOnce.Do(func() {
+		env := GetEnv()
+		dir, err := resolveConfigDir(*configDirFlag, os.Getenv(ConfigDirVariable), env, defaultConfigDirs())
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(ErrExitStatus)
+		}
+		resolvedConfigDir = dir
+	})
+	return resolvedConfigDir
+}
+
+// defaultConfigDirs returns the candidates of the directory used when it isn't specified.
+func defaultConfigDirs() []string {
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		if exe, err = filepath.EvalSymlinks(exe); err == nil {
+			dirs = append(dirs, filepath.Dir(exe))
+		}
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, cwd)
+	}
+	return dirs
+}
+
+// resolveConfigDir decides the directory of the configuration files.
+// The specified directory must exist. Without it, the first candidate having the application configuration
+// of the environment is chosen, and the last candidate is used when none has it.
+func resolveConfigDir(flagValue string, envValue string, env string, candidates []string) (string, error) {
+	dir := flagValue
+	if dir == "" {
+		dir = envValue
+	}
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("the configuration directory %s doesn't exist", dir)
+		}
+		return filepath.Abs(dir)
+	}
+	if len(candidates) == 0 {
+		return filepath.Abs(".")
+	}
+	for _, candidate := range candidates {
+		if hasConfigFile(os.DirFS(candidate), fmt.Sprintf(AppConfigName, env)) {
+			return filepath.Abs(candidate)
+		}
+	}
+	return filepath.Abs(candidates[len(candidates)-1])
+}
+
+// ReadConfig reads the configuration file named name without the extension from the configuration directory.
+// When the file isn't in the directory, the one embedded into the binary is read instead,
+// so the files on the disk always take precedence. CONFIG_SOURCE can force either of them.
+// It returns where the file has been read from.
+func ReadConfig(embedded fs.FS, name string, out interface{}) (string, error) {
+	return readConfig(GetConfigDir(), os.Getenv(ConfigSourceVariable), embedded, name, out)
+}
+
+func readConfig(dir string, source string, embedded fs.FS, name string, out interface{}) (string, error) {
+	fsys := os.DirFS(dir)
+	switch source {
+	case "":
+		if !hasConfigFile(fsys, name) {
+			return readEmbeddedConfig(embedded, name, out)
+		}
+	case SourceEmbedded:
+		return readEmbeddedConfig(embedded, name, out)
+	case SourceFile:
+	default:
+		return "", fmt.Errorf("unknown %s: %s, it must be %s or %s",
+			ConfigSourceVariable, source, SourceFile, SourceEmbedded)
+	}
+	file, err := ReadConfigFile(fsys, name, out)
+	return filepath.Join(dir, file), err
+}
+
+func readEmbeddedConfig(embedded fs.FS, name string, out interface{}) (string, error) {
+	file, err := ReadConfigFile(embedded, path.Join(EmbeddedConfigDir, name), out)
+	return embeddedPrefix + file, err
+}
+
+// Checksum returns the SHA-256 checksum in hex of the content of the configuration file read by ReadConfig,
+// whose name is the path on the disk or the name of the file embedded into embedded.
+func Checksum(embedded fs.FS, name string) (string, error) {
+	var data []byte
+	var err error
+	if file, ok := strings.CutPrefix(name, embeddedPrefix); ok {
+		data, err = fs.ReadFile(embedded, path.Join(EmbeddedConfigDir, file))
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DiskFile returns the path of the file read by ReadConfig, or an empty string when it has been embedded.
+func DiskFile(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return ""
+}
+
+// hasConfigFile returns true when the configuration file named name exists with any supported extension.
+func hasConfigFile(fsys fs.FS, name string) bool {
+	for _, ext := range supportedExtensions() {
+		if _, err := fs.Stat(fsys, name+ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePath resolves the relative file path written in the configuration against the configuration directory.
+// The absolute paths, the special names such as stdout, and the URIs are returned as they are.
+func ResolvePath(p string) string {
+	return resolvePath(GetConfigDir(), p)
+}
+
+func resolvePath(dir string, p string) string {
+	switch {
+	case p == "", p == "stdout", p == "stderr", filepath.IsAbs(p):
+		return p
+	case strings.Contains(p, ":"):
+		// the URIs such as file:///tmp/app.log and the in-memory sqlite such as :memory:.
+		return p
+	}
+	return filepath.Join(dir, p)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfigDir_FlagWinsOverEnv(t *testing.T) {
+	flagDir, envDir := t.TempDir(), t.TempDir()
+
+	dir, err := resolveConfigDir(flagDir, envDir, TST, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, flagDir, dir)
+}
+
+func TestResolveConfigDir_Env(t *testing.T) {
+	envDir := t.TempDir()
+
+	dir, err := resolveConfigDir("", envDir, TST, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, envDir, dir)
+}
+
+func TestResolveConfigDir_NotExist(t *testing.T) {
+	_, err := resolveConfigDir(filepath.Join(t.TempDir(), "missing"), "", TST, nil)
+
+	assert.ErrorContains(t, err, "doesn't exist")
+}
+
+func TestResolveConfigDir_ExecutableDir(t *testing.T) {
+	exeDir, cwd := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(exeDir, "application.test.yml"), "swagger:\n  enabled: true")
+
+	dir, err := resolveConfigDir("", "", TST, []string{exeDir, cwd})
+
+	assert.NoError(t, err)
+	assert.Equal(t, exeDir, dir)
+}
+
+func TestResolveConfigDir_WorkingDir(t *testing.T) {
+	exeDir, cwd := t.TempDir(), t.TempDir()
+
+	dir, err := resolveConfigDir("", "", TST, []string{exeDir, cwd})
+
+	assert.NoError(t, err)
+	assert.Equal(t, cwd, dir)
+}
+
+func TestReadConfig_FromWorkingDir(t *testing.T) {
+	cwd := chdirTemp(t)
+	writeFile(t, filepath.Join(cwd, "application.test.yml"), "database:\n  dialect: sqlite3\n  host: book.db")
+
+	dir, err := resolveConfigDir("", "", TST, defaultConfigDirs())
+	require.NoError(t, err)
+	conf := &Config{}
+	name, err := readConfig(dir, "", fstest.MapFS{}, "application.test", conf)
+	require.NoError(t, err)
+	conf.resolvePaths(dir)
+
+	assert.Equal(t, filepath.Join(dir, "application.test.yml"), name)
+	assert.Equal(t, filepath.Join(dir, "book.db"), conf.Database.Host)
+}
+
+func TestReadConfig_Embedded(t *testing.T) {
+	dir := chdirTemp(t)
+	embedded := fstest.MapFS{
+		"config/application.test.yml": {Data: []byte("database:\n  host: file::memory:?cache=shared")},
+	}
+
+	conf := &Config{}
+	name, err := readConfig(dir, "", embedded, "application.test", conf)
+	require.NoError(t, err)
+	conf.resolvePaths(dir)
+
+	assert.Equal(t, "embedded application.test.yml", name)
+	assert.Equal(t, "file::memory:?cache=shared", conf.Database.Host)
+}
+
+func TestResolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		dialect, host, dsn string
+		expectedHost       string
+		expectedDSN        string
+	}{
+		{"sqlite3", "book.db", "", filepath.Join(dir, "book.db"), ""},
+		{"", "", "data/book.db", "", filepath.Join(dir, "data/book.db")},
+		{"sqlite3", "", "file::memory:?cache=shared", "", "file::memory:?cache=shared"},
+		{"sqlite3", "", "/var/lib/book.db", "", "/var/lib/book.db"},
+		{"postgres", "postgres-db", "host=postgres-db dbname=book", "postgres-db", "host=postgres-db dbname=book"},
+	}
+	for _, tt := range tests {
+		conf := &Config{}
+		conf.Database.Dialect, conf.Database.Host, conf.Database.DSN = tt.dialect, tt.host, tt.dsn
+
+		conf.resolvePaths(dir)
+
+		assert.Equal(t, tt.expectedHost, conf.Database.Host, tt.dialect)
+		assert.Equal(t, tt.expectedDSN, conf.Database.DSN, tt.dialect)
+	}
+}
+
+func TestReadConfig_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "application.test.yml"), "swagger:\n  path: /file/.*")
+	embedded := fstest.MapFS{
+		"config/application.test.yml": {Data: []byte("swagger:\n  path: /embedded/.*")},
+	}
+
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"", "/file/.*"},
+		{SourceFile, "/file/.*"},
+		{SourceEmbedded, "/embedded/.*"},
+	}
+	for _, tt := range tests {
+		conf := &Config{}
+		_, err := readConfig(dir, tt.source, embedded, "application.test", conf)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, conf.Swagger.Path, "source: %q", tt.source)
+	}
+}
+
+func TestReadConfig_ForceFileWithoutFile(t *testing.T) {
+	embedded := fstest.MapFS{
+		"config/application.test.yml": {Data: []byte("swagger:\n  path: /embedded/.*")},
+	}
+
+	_, err := readConfig(t.TempDir(), SourceFile, embedded, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "no configuration file found")
+}
+
+func TestReadConfig_UnknownSource(t *testing.T) {
+	_, err := readConfig(t.TempDir(), "remote", fstest.MapFS{}, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "unknown CONFIG_SOURCE: remote")
+}
+
+func TestResolvePath(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Equal(t, filepath.Join(dir, "logs/app.log"), resolvePath(dir, "logs/app.log"))
+	assert.Equal(t, "/var/log/app.log", resolvePath(dir, "/var/log/app.log"))
+	assert.Equal(t, "stdout", resolvePath(dir, "stdout"))
+	assert.Equal(t, ":memory:", resolvePath(dir, ":memory:"))
+}
+
+// chdirTemp changes the working directory to a temporary directory during the test.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+}
+
+func TestChecksum(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "application.test.yml"), "swagger:\n  path: /file/.*")
+	embedded := fstest.MapFS{
+		"config/application.test.yml": {Data: []byte("swagger:\n  path: /file/.*")},
+	}
+
+	fromFile, err := Checksum(embedded, filepath.Join(dir, "application.test.yml"))
+	require.NoError(t, err)
+	fromEmbedded, err := Checksum(embedded, "embedded application.test.yml")
+	require.NoError(t, err)
+	// sha256sum of the content.
+	assert.Equal(t, "cc62dc13a7a00c366ce7f9cf6a1418e83d63abe1932132bb4bd771a152f616ae", fromFile)
+	assert.Equal(t, fromFile, fromEmbedded)
+
+	_, err = Checksum(embedded, "embedded application.develop.yml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+const (
+	// EnvVariable is the environment variable to switch configurations.
+	EnvVariable = "APP_ENV"
+	// LegacyEnvVariable is the environment variable to switch configurations used in the previous versions.
+	LegacyEnvVariable = "WEB_APP_ENV"
+	// envFlagName is the name of the command-line flag to switch configurations.
+	envFlagName = "env"
+)
+
+var (
+	envFlag = flag.String(envFlagName, DEV, "To switch configurations.")
+
+	envOnce     sync.Once
+	resolvedEnv string
+)
+
+// Env is the environment which this application runs on, such as develop or production.
+type Env string
+
+// KnownEnvs returns the environments which this application can run on.
+func KnownEnvs() []string {
+	return []string{DEV, TST, DOC, K8S, PRD}
+}
+
+// ParseEnv returns the environment of the given name.
+// It returns an error when the name isn't one of KnownEnvs, such as prod instead of production.
+func ParseEnv(name string) (Env, error) {
+	env := Env(name)
+	if !env.Valid() {
+		return "", fmt.Errorf("unknown environment: %s, it must be one of %v", name, KnownEnvs())
+	}
+	return env, nil
+}
+
+// String returns the name of the environment.
+func (e Env) String() string {
+	return string(e)
+}
+
+// Valid reports whether the environment is one of KnownEnvs.
+func (e Env) Valid() bool {
+	return slices.Contains(KnownEnvs(), string(e))
+}
+
+// GetEnv returns the running environment.
+// The command-line flag takes precedence over the environment variable when it's explicitly passed,
+// and it defaults to develop when neither is provided.
+// The environment is resolved only once, so that every consumer sees the same value.
+func GetEnv() string {
+	envOnce.Do(func() {
+		if !flag.Parsed() {
+			flag.Parse()
+		}
+		env, warning, err := resolveEnv(lookupEnvFlag(), lookupEnvVariable())
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(ErrExitStatus)
+		}
+		if warning != "" {
+			fmt.Println(warning)
+		}
+		resolvedEnv = env
+	})
+	return resolvedEnv
+}
+
+// lookupEnvFlag returns the value of the command-line flag when it's explicitly passed.
+func lookupEnvFlag() string {
+	value := ""
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == envFlagName {
+			value = *envFlag
+		}
+	})
+	return value
+}
+
+// lookupEnvVariable returns the value of the environment variable.
+func lookupEnvVariable() string {
+	if value := os.Getenv(EnvVariable); value != "" {
+		return value
+	}
+	return os.Getenv(LegacyEnvVariable)
+}
+
+// resolveEnv decides the running environment from the command-line flag and the environment variable.
+// It returns a warning when neither is provided, and returns an error when the environment is unknown.
+func resolveEnv(flagValue string, envValue string) (string, string, error) {
+	env, warning := flagValue, ""
+	switch {
+	case flagValue != "":
+	case envValue != "":
+		env = envValue
+	default:
+		env = DEV
+		warning = fmt.Sprintf("The environment isn't specified by -%s or %s, so %s is used.",
+			envFlagName, EnvVariable, DEV)
+	}
+	if _, err := ParseEnv(env); err != nil {
+		return "", "", err
+	}
+	return env, warning, nil
+}
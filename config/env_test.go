@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveEnv_FlagWinsOverEnv(t *testing.T) {
+	env, warning, err := resolveEnv(DOC, PRD)
+
+	assert.NoError(t, err)
+	assert.Equal(t, DOC, env)
+	assert.Empty(t, warning)
+}
+
+func TestResolveEnv_EnvOnly(t *testing.T) {
+	env, warning, err := resolveEnv("", PRD)
+
+	assert.NoError(t, err)
+	assert.Equal(t, PRD, env)
+	assert.Empty(t, warning)
+}
+
+func TestResolveEnv_Default(t *testing.T) {
+	env, warning, err := resolveEnv("", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, DEV, env)
+	assert.NotEmpty(t, warning)
+}
+
+func TestResolveEnv_InvalidFlag(t *testing.T) {
+	_, _, err := resolveEnv("prod", "")
+
+	assert.EqualError(t, err, "unknown environment: prod, it must be one of [develop test docker k8s production]")
+}
+
+func TestResolveEnv_InvalidEnv(t *testing.T) {
+	_, _, err := resolveEnv("", "staging")
+
+	assert.Error(t, err)
+}
+
+func TestLookupEnvVariable(t *testing.T) {
+	t.Setenv(EnvVariable, "")
+	t.Setenv(LegacyEnvVariable, DOC)
+	assert.Equal(t, DOC, lookupEnvVariable())
+
+	t.Setenv(EnvVariable, K8S)
+	assert.Equal(t, K8S, lookupEnvVariable())
+}
+
+func TestGetEnv_Cached(t *testing.T) {
+	t.Setenv(EnvVariable, DOC)
+	first := GetEnv()
+
+	t.Setenv(EnvVariable, K8S)
+	assert.Equal(t, first, GetEnv())
+}
+
+func TestParseEnv_Known(t *testing.T) {
+	for _, name := range KnownEnvs() {
+		env, err := ParseEnv(name)
+
+		assert.NoError(t, err)
+		assert.True(t, env.Valid())
+		assert.Equal(t, name, env.String())
+	}
+}
+
+func TestParseEnv_Unknown(t *testing.T) {
+	for _, name := range []string{"prod", "Production", "", " develop"} {
+		env, err := ParseEnv(name)
+
+		assert.Error(t, err, name)
+		assert.False(t, env.Valid())
+	}
+}
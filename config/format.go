@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// decoders is the list of the decoders for each extension of the configuration files, in the order of the lookup.
+// The encoder of the same format is used to rebuild the file after the secret files are resolved.
+var decoders = []struct {
+	ext    string
+	decode func(data []byte, out interface{}) error
+	encode func(in interface{}) ([]byte, error)
+}{
+	{".yml", yaml.Unmarshal, yaml.Marshal},
+	{".yaml", yaml.Unmarshal, yaml.Marshal},
+	{".json", json.Unmarshal, json.Marshal},
+	{".toml", toml.Unmarshal, toml.Marshal},
+}
+
+// ReadConfigFile reads the configuration file whose path without the extension is the given path,
+// and decodes it by the parser selected by its extension: yml, yaml, json or toml.
+// It returns the name of the file which has been read.
+// It fails when no file or more than one file is found.
+func ReadConfigFile(fsys fs.FS, basePath string, out interface{}) (string, error) {
+	var found []string
+	for _, decoder := range decoders {
+		if _, err := fs.Stat(fsys, basePath+decoder.ext); err == nil {
+			found = append(found, basePath+decoder.ext)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no configuration file found for %s, the supported extensions are %s",
+			path.Base(basePath), strings.Join(supportedExtensions(), ", "))
+	case 1:
+	default:
+		return "", fmt.Errorf("more than one configuration file found for %s: %s, keep only one of them",
+			path.Base(basePath), strings.Join(found, ", "))
+	}
+
+	name := found[0]
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	for _, decoder := range decoders {
+		if !strings.HasSuffix(name, decoder.ext) {
+			continue
+		}
+		var doc map[string]interface{}
+		if err := decoder.decode(data, &doc); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path.Base(name), err)
+		}
+		if err := resolveSecretFiles(doc, ""); err != nil {
+			return "", fmt.Errorf("failed to resolve the secrets of %s: %w", path.Base(name), err)
+		}
+		if errs := normalizeUnits(doc, reflect.TypeOf(out), ""); len(errs) > 0 {
+			return "", fmt.Errorf("failed to parse %s: %w", path.Base(name), errors.Join(errs...))
+		}
+		if data, err = decoder.encode(doc); err != nil {
+			return "", err
+		}
+		if err := decoder.decode(data, out); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path.Base(name), err)
+		}
+	}
+	return path.Base(name), nil
+}
+
+func supportedExtensions() []string {
+	exts := make([]string, 0, len(decoders))
+	for _, decoder := range decoders {
+		exts = append(exts, decoder.ext)
+	}
+	return exts
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigFile_AllFormats(t *testing.T) {
+	configs := map[string]*Config{}
+	for _, format := range []string{"yml", "json", "toml"} {
+		conf := &Config{}
+		name, err := ReadConfigFile(os.DirFS("testdata/"+format), "application.test", conf)
+		require.NoError(t, err)
+		assert.Equal(t, "application.test."+format, name)
+		configs[format] = conf
+	}
+
+	assert.Equal(t, "postgres", configs["yml"].Database.Dialect)
+	assert.Equal(t, configs["yml"], configs["json"])
+	assert.Equal(t, configs["yml"], configs["toml"])
+}
+
+func TestReadConfigFile_MultipleCandidates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"application.test.yml":  {Data: []byte("swagger:\n  enabled: true")},
+		"application.test.toml": {Data: []byte("[swagger]\nenabled = true")},
+	}
+
+	_, err := ReadConfigFile(fsys, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "application.test.yml, application.test.toml")
+}
+
+func TestReadConfigFile_NotFound(t *testing.T) {
+	_, err := ReadConfigFile(fstest.MapFS{}, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "no configuration file found for application.test")
+}
+
+func TestReadConfigFile_InvalidFile(t *testing.T) {
+	fsys := fstest.MapFS{"application.test.json": {Data: []byte("{")}}
+
+	_, err := ReadConfigFile(fsys, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "failed to parse application.test.json")
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// OverrideWithEnv overrides the fields of the given configuration with the environment variables.
+// The name of the variable is derived from the yaml path of the field,
+// for example database.host is overridden by DATABASE_HOST and log_rotate.maxsize by LOG_ROTATE_MAXSIZE.
+// The given configuration must be a pointer to a struct.
+func OverrideWithEnv(config interface{}) error {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("the configuration must be a pointer to a struct, but %T", config)
+	}
+	return overrideStruct(value.Elem(), "")
+}
+
+func overrideStruct(value reflect.Value, prefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, inline := fieldKey(field)
+		if key == "-" {
+			continue
+		}
+		name := prefix
+		if !inline {
+			name = envName(prefix, key)
+		}
+		if err := overrideValue(value.Field(i), field, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func overrideValue(value reflect.Value, field reflect.StructField, name string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return overrideText(value, field, name)
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return overrideStruct(value, name)
+	case reflect.Ptr:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		return overrideStruct(value.Elem(), name)
+	case reflect.Slice:
+		if !isScalar(value.Type().Elem().Kind()) {
+			return nil
+		}
+	default:
+		if !isScalar(value.Kind()) {
+			return nil
+		}
+	}
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	if err := setValue(value, env); err != nil {
+		return fmt.Errorf("invalid value of the environment variable %s: %w", name, err)
+	}
+	return nil
+}
+
+// isScalar returns true when the value of the kind can be converted from a string.
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// overrideText sets the value by its UnmarshalText method.
+// A bare integer for a Duration or ByteSize field is read in the legacy unit of the field.
+func overrideText(value reflect.Value, field reflect.StructField, name string) error {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	env = withLegacyUnit(field, env)
+	if err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(env)); err != nil {
+		return fmt.Errorf("invalid value of the environment variable %s: %w", name, err)
+	}
+	return nil
+}
+
+// setValue converts the string to the type of the field and sets it.
+func setValue(value reflect.Value, env string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(env)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			d, err := time.ParseDuration(env)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(env, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(env, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(env, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		return setSlice(value, env)
+	}
+	return nil
+}
+
+// setSlice sets the comma separated values to the slice.
+func setSlice(value reflect.Value, env string) error {
+	items := strings.Split(env, ",")
+	slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+// fieldKey returns the key of the field in the yml file, and whether the field is inlined.
+func fieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	name, options, _ := strings.Cut(tag, ",")
+	if strings.Contains(options, "inline") {
+		return "", true
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, false
+}
+
+// envName returns the name of the environment variable for the key.
+func envName(prefix string, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
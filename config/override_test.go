@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type overrideTestConfig struct {
+	Name    string
+	Count   int           `yaml:"count"`
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"`
+	Paths   []string      `yaml:"paths"`
+	Nested  struct {
+		MaxSize int `yaml:"maxsize"`
+	} `yaml:"log_rotate"`
+	Ignored string `yaml:"-"`
+}
+
+func TestOverrideWithEnv_String(t *testing.T) {
+	t.Setenv("NAME", "overridden")
+	conf := &overrideTestConfig{Name: "original"}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, "overridden", conf.Name)
+}
+
+func TestOverrideWithEnv_Int(t *testing.T) {
+	t.Setenv("COUNT", "10")
+	conf := &overrideTestConfig{Count: 1}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, 10, conf.Count)
+}
+
+func TestOverrideWithEnv_Bool(t *testing.T) {
+	t.Setenv("ENABLED", "true")
+	conf := &overrideTestConfig{}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.True(t, conf.Enabled)
+}
+
+func TestOverrideWithEnv_NestedStruct(t *testing.T) {
+	t.Setenv("LOG_ROTATE_MAXSIZE", "5")
+	conf := &overrideTestConfig{}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, 5, conf.Nested.MaxSize)
+}
+
+func TestOverrideWithEnv_DurationAndSlice(t *testing.T) {
+	t.Setenv("TIMEOUT", "3s")
+	t.Setenv("PATHS", "/api/.*, /swagger/.*")
+	conf := &overrideTestConfig{}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, 3*time.Second, conf.Timeout)
+	assert.Equal(t, []string{"/api/.*", "/swagger/.*"}, conf.Paths)
+}
+
+func TestOverrideWithEnv_NotSet(t *testing.T) {
+	conf := &overrideTestConfig{Name: "original", Ignored: "original"}
+	t.Setenv("IGNORED", "overridden")
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, "original", conf.Name)
+	assert.Equal(t, "original", conf.Ignored)
+}
+
+func TestOverrideWithEnv_InvalidValue(t *testing.T) {
+	t.Setenv("LOG_ROTATE_MAXSIZE", "large")
+	conf := &overrideTestConfig{}
+
+	err := OverrideWithEnv(conf)
+
+	assert.ErrorContains(t, err, "LOG_ROTATE_MAXSIZE")
+}
+
+func TestOverrideWithEnv_AppConfig(t *testing.T) {
+	t.Setenv("DATABASE_HOST", "db.example.com")
+	t.Setenv("REDIS_CONNECTION_POOL_SIZE", "20")
+	conf := &Config{}
+
+	assert.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, "db.example.com", conf.Database.Host)
+	assert.Equal(t, 20, conf.Redis.ConnectionPoolSize)
+}
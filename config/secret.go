@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretFileSuffix is the suffix of the key whose value is the path of the file holding the secret value.
+// For example, password_file: /run/secrets/db_password sets the content of the file to password.
+const secretFileSuffix = "_file"
+
+var (
+	secretsMutex sync.RWMutex
+	secretPaths  = map[string]bool{}
+)
+
+// IsSecret returns true when the value of the given yaml path, such as database.password, has been read from a file.
+// The secret values must never be printed.
+func IsSecret(path string) bool {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	return secretPaths[path]
+}
+
+// resolveSecretFiles replaces the keys having the _file suffix in the document with the contents of the files.
+func resolveSecretFiles(doc map[string]interface{}, prefix string) error {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		switch value := doc[key].(type) {
+		case map[string]interface{}:
+			errs = append(errs, resolveSecretFiles(value, prefix+key+"."))
+		case string:
+			if strings.HasSuffix(key, secretFileSuffix) {
+				errs = append(errs, resolveSecretFile(doc, prefix, key, value))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func resolveSecretFile(doc map[string]interface{}, prefix string, key string, file string) error {
+	name := strings.TrimSuffix(key, secretFileSuffix)
+	if inline, ok := doc[name]; ok && inline != nil && inline != "" {
+		return NewFieldError(prefix+name, fmt.Sprintf("must not be set together with %s", key))
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return NewFieldError(prefix+key, err.Error())
+	}
+	secret := string(content)
+	if strings.HasSuffix(secret, "\n") {
+		secret = strings.TrimSuffix(strings.TrimSuffix(secret, "\n"), "\r")
+	}
+	if secret == "" {
+		return NewFieldError(prefix+key, fmt.Sprintf("the secret file %s is empty", file))
+	}
+
+	delete(doc, key)
+	doc[name] = secret
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secretPaths[prefix+name] = true
+	return nil
+}
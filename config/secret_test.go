@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigFile_SecretFile(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "db_password")
+	writeFile(t, secret, "p@ssw0rd\n\n")
+
+	for _, format := range []struct{ ext, content string }{
+		{"yml", "database:\n  password_file: %s"},
+		{"json", `{"database": {"password_file": %q}}`},
+		{"toml", "[database]\npassword_file = %q"},
+	} {
+		fsys := fstest.MapFS{"application.test." + format.ext: {Data: []byte(fmt.Sprintf(format.content, secret))}}
+
+		conf := &Config{}
+		_, err := ReadConfigFile(fsys, "application.test", conf)
+		require.NoError(t, err, format.ext)
+
+		assert.Equal(t, "p@ssw0rd\n", conf.Database.Password, format.ext)
+		assert.True(t, IsSecret("database.password"))
+	}
+}
+
+func TestReadConfigFile_SecretFileMissing(t *testing.T) {
+	fsys := fstest.MapFS{"application.test.yml": {Data: []byte("database:\n  password_file: /missing/db_password")}}
+
+	_, err := ReadConfigFile(fsys, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "database.password_file")
+	assert.ErrorContains(t, err, "no such file or directory")
+}
+
+func TestReadConfigFile_SecretFileEmpty(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "db_password")
+	writeFile(t, secret, "\n")
+	fsys := fstest.MapFS{"application.test.yml": {Data: []byte("database:\n  password_file: " + secret)}}
+
+	_, err := ReadConfigFile(fsys, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "database.password_file: the secret file "+secret+" is empty")
+}
+
+func TestReadConfigFile_SecretFileConflict(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "db_password")
+	writeFile(t, secret, "p@ssw0rd")
+	fsys := fstest.MapFS{
+		"application.test.yml": {Data: []byte("database:\n  password: inline\n  password_file: " + secret)},
+	}
+
+	_, err := ReadConfigFile(fsys, "application.test", &Config{})
+
+	assert.ErrorContains(t, err, "database.password: must not be set together with password_file")
+}
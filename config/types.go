@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// day is the unit of the durations written in days such as 7d.
+const day = 24 * time.Hour
+
+// Duration is a time.Duration written as a human friendly string in the configuration files, such as 500ms, 2h or 7d.
+// A bare integer is read in the legacy unit of the field, which is specified by the unit tag such as `unit:"ms"`.
+type Duration time.Duration
+
+// ParseDuration parses the string such as 500ms, 2h or 7d.
+func ParseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseInt(days, 10, 64)
+		if err != nil || n > int64(math.MaxInt64/day) {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return Duration(time.Duration(n) * day), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return Duration(d), nil
+}
+
+// Std returns the duration as time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns the duration in the format of time.Duration, or in days when it is a whole number of days.
+func (d Duration) String() string {
+	if d != 0 && time.Duration(d)%day == 0 {
+		return strconv.FormatInt(int64(time.Duration(d)/day), 10) + "d"
+	}
+	return time.Duration(d).String()
+}
+
+// MarshalText returns the duration as a string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText parses the duration from a string.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// ByteSize is a number of bytes written as a human friendly string in the configuration files, such as 100MB or 1GiB.
+// KB, MB and GB are the powers of 1000, and KiB, MiB and GiB are the powers of 1024.
+// A bare integer is read in the legacy unit of the field, which is specified by the unit tag such as `unit:"MiB"`.
+type ByteSize int64
+
+// byteUnits is the list of the units of ByteSize, from the largest one.
+var byteUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"GiB", 1 << 30}, {"GB", 1000 * 1000 * 1000},
+	{"MiB", 1 << 20}, {"MB", 1000 * 1000},
+	{"KiB", 1 << 10}, {"KB", 1000},
+	{"B", 1},
+}
+
+// ParseByteSize parses the string such as 100MB, 1GiB or 512B. A bare integer is read in bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	digits := strings.TrimRightFunc(s, unicode.IsLetter)
+	unit := strings.TrimSpace(s[len(digits):])
+	n, err := strconv.ParseInt(strings.TrimSpace(digits), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if unit == "" {
+		return ByteSize(n), nil
+	}
+	for _, u := range byteUnits {
+		if strings.EqualFold(u.name, unit) {
+			if n > math.MaxInt64/int64(u.size) {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return ByteSize(n) * u.size, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q", s)
+}
+
+// String returns the size in the largest unit which divides it.
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+	return "0B"
+}
+
+// MarshalText returns the size as a string.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText parses the size from a string.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	parsed, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+var (
+	durationConfigType = reflect.TypeOf(Duration(0))
+	byteSizeConfigType = reflect.TypeOf(ByteSize(0))
+)
+
+// isUnitType returns true when the type is Duration or ByteSize.
+func isUnitType(t reflect.Type) bool {
+	return t == durationConfigType || t == byteSizeConfigType
+}
+
+// withLegacyUnit adds the legacy unit of the field to the bare integer.
+func withLegacyUnit(field reflect.StructField, value string) string {
+	unit := field.Tag.Get("unit")
+	if unit == "" || !isUnitType(field.Type) {
+		return value
+	}
+	if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err != nil {
+		return value
+	}
+	return strings.TrimSpace(value) + unit
+}
+
+// normalizeUnits converts the values of Duration and ByteSize fields in the decoded document into the strings,
+// adding the legacy units to the bare integers, and checks that they can be parsed.
+func normalizeUnits(doc map[string]interface{}, t reflect.Type, prefix string) []error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, inline := fieldKey(field)
+		if !field.IsExported() || key == "-" {
+			continue
+		}
+		if inline {
+			errs = append(errs, normalizeUnits(doc, field.Type, prefix)...)
+			continue
+		}
+		value, ok := doc[key]
+		if !ok || value == nil {
+			continue
+		}
+		if isUnitType(field.Type) {
+			if err := normalizeUnitValue(doc, key, field, value); err != nil {
+				errs = append(errs, NewFieldError(yamlPath(prefix, key), err.Error()))
+			}
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			errs = append(errs, normalizeUnits(nested, field.Type, yamlPath(prefix, key))...)
+		}
+	}
+	return errs
+}
+
+// normalizeUnitValue replaces the value of the Duration or ByteSize field with the string which can be parsed.
+func normalizeUnitValue(doc map[string]interface{}, key string, field reflect.StructField, value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case int, int64, uint64:
+		text = withLegacyUnit(field, fmt.Sprint(v))
+	case float64:
+		if v != math.Trunc(v) {
+			return fmt.Errorf("%v is not an integer", v)
+		}
+		text = withLegacyUnit(field, strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Errorf("invalid value %v", v)
+	}
+	if err := reflect.New(field.Type).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+		return err
+	}
+	doc[key] = text
+	return nil
+}
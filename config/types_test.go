@@ -0,0 +1,162 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Duration
+		wantErr bool
+	}{
+		{input: "500ms", want: Duration(500 * time.Millisecond)},
+		{input: "2h", want: Duration(2 * time.Hour)},
+		{input: "1h30m", want: Duration(90 * time.Minute)},
+		{input: "7d", want: Duration(7 * 24 * time.Hour)},
+		{input: " 3s ", want: Duration(3 * time.Second)},
+		{input: "0", want: 0},
+		{input: "5", wantErr: true},
+		{input: "1.5d", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ByteSize
+		wantErr bool
+	}{
+		{input: "512", want: 512},
+		{input: "512B", want: 512},
+		{input: "100KB", want: 100 * 1000},
+		{input: "100MB", want: 100 * 1000 * 1000},
+		{input: "1GiB", want: 1 << 30},
+		{input: "3MiB", want: 3 << 20},
+		{input: "10 mb", want: 10 * 1000 * 1000},
+		{input: "1.5GB", wantErr: true},
+		{input: "-1MB", wantErr: true},
+		{input: "10XB", wantErr: true},
+		{input: "MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMarshalText_RoundTrip(t *testing.T) {
+	durations := []Duration{0, Duration(500 * time.Millisecond), Duration(90 * time.Minute), Duration(48 * time.Hour)}
+	for _, d := range durations {
+		text, err := d.MarshalText()
+		require.NoError(t, err)
+		var parsed Duration
+		require.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, d, parsed)
+	}
+	for _, b := range []ByteSize{0, 512, 100 * 1000 * 1000, 3 << 20, 1 << 30, 1500} {
+		text, err := b.MarshalText()
+		require.NoError(t, err)
+		var parsed ByteSize
+		require.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, b, parsed)
+	}
+	assert.Equal(t, "3MiB", ByteSize(3<<20).String())
+	assert.Equal(t, "2d", Duration(48*time.Hour).String())
+}
+
+// unitsConfig is the configuration which has the fields with the legacy units.
+type unitsConfig struct {
+	Rotate struct {
+		MaxSize ByteSize `json:"maxsize" yaml:"maxsize" toml:"maxsize" unit:"MiB"`
+		MaxAge  Duration `json:"maxage" yaml:"maxage" toml:"maxage" unit:"d"`
+	} `json:"rotate" yaml:"rotate" toml:"rotate"`
+	Timeout Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+}
+
+func TestReadConfigFile_Units(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		maxSize ByteSize
+		maxAge  Duration
+	}{
+		{name: "yml strings", file: "units.yml", data: "rotate:\n  maxsize: 100MB\n  maxage: 12h",
+			maxSize: 100 * 1000 * 1000, maxAge: Duration(12 * time.Hour)},
+		{name: "yml bare ints", file: "units.yml", data: "rotate:\n  maxsize: 3\n  maxage: 7",
+			maxSize: 3 << 20, maxAge: Duration(7 * 24 * time.Hour)},
+		{name: "json bare ints", file: "units.json", data: `{"rotate": {"maxsize": 3, "maxage": 7}}`,
+			maxSize: 3 << 20, maxAge: Duration(7 * 24 * time.Hour)},
+		{name: "toml strings", file: "units.toml", data: "[rotate]\nmaxsize = \"1GiB\"\nmaxage = 7",
+			maxSize: 1 << 30, maxAge: Duration(7 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &unitsConfig{}
+			_, err := ReadConfigFile(fstest.MapFS{tt.file: {Data: []byte(tt.data)}}, "units", conf)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.maxSize, conf.Rotate.MaxSize)
+			assert.Equal(t, tt.maxAge, conf.Rotate.MaxAge)
+		})
+	}
+}
+
+func TestReadConfigFile_InvalidUnits(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "garbage size", data: "rotate:\n  maxsize: lots", want: `rotate.maxsize: invalid size "lots"`},
+		{name: "garbage duration", data: "rotate:\n  maxage: 1 week", want: `rotate.maxage: invalid duration "1 week"`},
+		{name: "fraction", data: "rotate:\n  maxsize: 1.5", want: "rotate.maxsize: 1.5 is not an integer"},
+		{name: "no legacy unit", data: "timeout: 30", want: `timeout: invalid duration "30"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadConfigFile(fstest.MapFS{"units.yml": {Data: []byte(tt.data)}}, "units", &unitsConfig{})
+
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestOverrideWithEnv_Units(t *testing.T) {
+	t.Setenv("ROTATE_MAXSIZE", "5")
+	t.Setenv("ROTATE_MAXAGE", "36h")
+	conf := &unitsConfig{}
+
+	require.NoError(t, OverrideWithEnv(conf))
+	assert.Equal(t, ByteSize(5<<20), conf.Rotate.MaxSize)
+	assert.Equal(t, Duration(36*time.Hour), conf.Rotate.MaxAge)
+
+	t.Setenv("TIMEOUT", "soon")
+	assert.ErrorContains(t, OverrideWithEnv(conf), "TIMEOUT")
+}
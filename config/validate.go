@@ -0,0 +1,120 @@
+package config
+
+import (
+	"regexp"
+	"slices"
+)
+
+// supportedDialects is the list of the database dialects which this application supports.
+var supportedDialects = []string{"sqlite3", "postgres", "mysql"}
+
+// FieldError represents an invalid value in the configuration.
+type FieldError struct {
+	// Path is the yaml path of the invalid field such as database.host.
+	Path    string
+	Message string
+}
+
+// NewFieldError is constructor.
+func NewFieldError(path string, message string) *FieldError {
+	return &FieldError{Path: path, Message: message}
+}
+
+// Error returns the path and the message.
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks the whole configuration, and returns every problem found in it.
+func (c *Config) Validate() []error {
+	var errs []error
+	errs = append(errs, c.validateDatabase()...)
+	errs = append(errs, c.validateRedis()...)
+	errs = append(errs, c.validateSwagger()...)
+	errs = append(errs, c.validateSecurity()...)
+	if c.Log.RequestLogFormat == "" {
+		errs = append(errs, NewFieldError("log.request_log_format", "must not be empty"))
+	}
+	return errs
+}
+
+func (c *Config) validateDatabase() []error {
+	var errs []error
+	db := c.Database
+	if db.Dialect != "" && !slices.Contains(supportedDialects, db.Dialect) {
+		errs = append(errs, NewFieldError("database.dialect", "must be one of sqlite3, postgres and mysql"))
+	}
+	if db.SlowTransactionThreshold < 0 {
+		errs = append(errs, NewFieldError("database.slow_transaction_threshold", "must not be negative"))
+	}
+	if db.QueryTimeout < 0 {
+		errs = append(errs, NewFieldError("database.query_timeout", "must not be negative"))
+	}
+	if db.TransactionTimeout < 0 {
+		errs = append(errs, NewFieldError("database.transaction_timeout", "must not be negative"))
+	}
+	if db.DSN != "" {
+		return errs
+	}
+	if db.Host == "" {
+		errs = append(errs, NewFieldError("database.host", "must not be empty when database.dsn is empty"))
+	}
+	if db.Dialect == "postgres" || db.Dialect == "mysql" {
+		if db.Dbname == "" {
+			errs = append(errs, NewFieldError("database.dbname", "must not be empty when database.dsn is empty"))
+		}
+		if db.Username == "" {
+			errs = append(errs, NewFieldError("database.username", "must not be empty when database.dsn is empty"))
+		}
+	}
+	return errs
+}
+
+func (c *Config) validateRedis() []error {
+	var errs []error
+	if !c.Redis.Enabled {
+		return errs
+	}
+	if c.Redis.Host == "" {
+		errs = append(errs, NewFieldError("redis.host", "must not be empty when redis is enabled"))
+	}
+	if c.Redis.Port == "" {
+		errs = append(errs, NewFieldError("redis.port", "must not be empty when redis is enabled"))
+	}
+	if c.Redis.ConnectionPoolSize <= 0 {
+		errs = append(errs, NewFieldError("redis.connection_pool_size", "must be greater than 0"))
+	}
+	return errs
+}
+
+func (c *Config) validateSwagger() []error {
+	var errs []error
+	if c.Swagger.Enabled && c.Swagger.Path == "" {
+		errs = append(errs, NewFieldError("swagger.path", "must not be empty when swagger is enabled"))
+	}
+	return append(errs, validatePatterns("swagger.path", []string{c.Swagger.Path})...)
+}
+
+func (c *Config) validateSecurity() []error {
+	var errs []error
+	errs = append(errs, validatePatterns("security.auth_path", c.Security.AuthPath)...)
+	errs = append(errs, validatePatterns("security.exclude_path", c.Security.ExculdePath)...)
+	errs = append(errs, validatePatterns("security.user_path", c.Security.UserPath)...)
+	errs = append(errs, validatePatterns("security.admin_path", c.Security.AdminPath)...)
+	if c.Security.IDEncoding && c.Security.IDEncodingKey == "" {
+		errs = append(errs,
+			NewFieldError("security.id_encoding_key", "must not be empty when security.id_encoding is enabled"))
+	}
+	return errs
+}
+
+// validatePatterns checks the paths are valid regular expressions.
+func validatePatterns(path string, patterns []string) []error {
+	var errs []error
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, NewFieldError(path, "invalid regular expression: "+err.Error()))
+		}
+	}
+	return errs
+}
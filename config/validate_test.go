@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_Success(t *testing.T) {
+	conf := createValidConfig()
+
+	assert.Empty(t, conf.Validate())
+}
+
+func TestValidate_MultipleErrors(t *testing.T) {
+	conf := createValidConfig()
+	conf.Database.Dialect = "oracle"
+	conf.Redis.Enabled = true
+	conf.Redis.Host = "localhost"
+	conf.Redis.Port = "6379"
+	conf.Security.AuthPath = []string{"/api/(.*"}
+
+	errs := conf.Validate()
+
+	assert.Len(t, errs, 3)
+	assert.Equal(t, []string{"database.dialect", "redis.connection_pool_size", "security.auth_path"}, errorPaths(errs))
+}
+
+func TestValidate_DatabaseWithoutDSN(t *testing.T) {
+	conf := createValidConfig()
+	conf.Database.Dialect = "postgres"
+	conf.Database.Host = ""
+
+	errs := conf.Validate()
+
+	assert.Equal(t, []string{"database.host", "database.dbname", "database.username"}, errorPaths(errs))
+}
+
+func TestValidate_DatabaseWithDSN(t *testing.T) {
+	conf := createValidConfig()
+	conf.Database.Dialect = "postgres"
+	conf.Database.Host = ""
+	conf.Database.DSN = "host=localhost user=testusr dbname=testdb"
+
+	assert.Empty(t, conf.Validate())
+}
+
+func TestValidate_IDEncodingWithoutKey(t *testing.T) {
+	conf := createValidConfig()
+	conf.Security.IDEncoding = true
+
+	assert.Equal(t, []string{"security.id_encoding_key"}, errorPaths(conf.Validate()))
+	conf.Security.IDEncodingKey = "secret"
+	assert.Empty(t, conf.Validate())
+}
+
+func createValidConfig() *Config {
+	conf := &Config{}
+	conf.Database.Dialect = "sqlite3"
+	conf.Database.Host = "book.db"
+	conf.Log.RequestLogFormat = "${remote_ip} ${account_name} ${uri} ${method} ${status}"
+	conf.Swagger.Enabled = true
+	conf.Swagger.Path = "/swagger/.*"
+	conf.Security.AuthPath = []string{"/api/.*"}
+	return conf
+}
+
+func errorPaths(errs []error) []string {
+	paths := make([]string, 0, len(errs))
+	for _, err := range errs {
+		paths = append(paths, err.(*FieldError).Path)
+	}
+	return paths
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay is the delay to reload after the file is changed, to gather the events of a single save.
+const reloadDelay = 100 * time.Millisecond
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// ReloadReport represents the result of reloading the configuration files.
+type ReloadReport struct {
+	// Applied is the list of the changed paths which have been applied by the callbacks.
+	Applied []string
+	// RestartRequired is the list of the changed paths which aren't reloadable.
+	// A path is listed once until its value is changed again, so the same change isn't warned on every reload.
+	RestartRequired []string
+	// Rejected is the changed paths which can't be reloaded by design, with the reasons registered by RejectChange.
+	// They are listed once as well as RestartRequired.
+	Rejected map[string]string
+}
+
+// reloadSource is a configuration file which can be reloaded.
+type reloadSource struct {
+	file string
+	load func() (interface{}, error)
+	// values is the running values of the configuration flattened by the yaml paths.
+	values map[string]reflect.Value
+	// reported is the values of the changed paths which have been reported as not applied.
+	reported map[string]reflect.Value
+}
+
+// watcher reloads the configuration files, and notifies the changes to the callbacks.
+type watcher struct {
+	mutex     sync.Mutex
+	sources   []*reloadSource
+	callbacks map[string][]func(value interface{})
+	// rejected is the reasons of the paths, and the paths under them, which can't be reloaded.
+	rejected map[string]string
+}
+
+var defaultWatcher = newWatcher()
+
+func newWatcher() *watcher {
+	return &watcher{callbacks: map[string][]func(value interface{}){}, rejected: map[string]string{}}
+}
+
+// OnChange registers the callback to apply the new value when the value of the yaml path is changed by reloading,
+// for example zap_config.level or extension.cors_enabled. Only the registered paths are reloadable.
+func OnChange(path string, callback func(value interface{})) {
+	defaultWatcher.onChange(path, callback)
+}
+
+// RejectChange registers the yaml path, such as zap_config.sampling, whose change can't be applied without a restart
+// for the reason. Its change, and the change of the paths under it, is reported in Rejected with the reason.
+func RejectChange(path string, reason string) {
+	defaultWatcher.rejectChange(path, reason)
+}
+
+// AddReloadSource registers the configuration to be reloaded.
+// The file is watched when it is on the disk, and the load function reads, overrides and validates it again.
+func AddReloadSource(file string, current interface{}, load func() (interface{}, error)) {
+	defaultWatcher.addSource(file, current, load)
+}
+
+// Reload reads the configuration files again and applies the changes of the reloadable values.
+// When a file is invalid, the running configuration is kept, and the error is returned.
+func Reload() (*ReloadReport, error) {
+	return defaultWatcher.reload()
+}
+
+// Watch reloads the configuration files whenever they are changed on the disk, and reports the results.
+// It returns the function to stop watching.
+func Watch(report func(*ReloadReport, error)) (func() error, error) {
+	return defaultWatcher.watch(report)
+}
+
+func (w *watcher) onChange(path string, callback func(value interface{})) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.callbacks[path] = append(w.callbacks[path], callback)
+}
+
+func (w *watcher) rejectChange(path string, reason string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.rejected[path] = reason
+}
+
+func (w *watcher) addSource(file string, current interface{}, load func() (interface{}, error)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.sources = append(w.sources, &reloadSource{file: file, load: load, values: flatten(current),
+		reported: map[string]reflect.Value{}})
+}
+
+func (w *watcher) reload() (*ReloadReport, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	report := &ReloadReport{}
+	var errs []error
+	for _, source := range w.sources {
+		loaded, err := source.load()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values := flatten(loaded)
+		changed := changedPaths(source.values, values)
+		for path := range source.reported {
+			// the path whose value has been restored is reported again when it is changed next time.
+			if !slices.Contains(changed, path) {
+				delete(source.reported, path)
+			}
+		}
+		for _, path := range changed {
+			callbacks, ok := w.callbacks[path]
+			if !ok {
+				w.reportNotApplied(report, source, path, values[path])
+				continue
+			}
+			for _, callback := range callbacks {
+				callback(values[path].Interface())
+			}
+			source.values[path] = values[path]
+			report.Applied = append(report.Applied, path)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// reportNotApplied adds the changed path which can't be reloaded to the report,
+// unless the same value has already been reported.
+func (w *watcher) reportNotApplied(report *ReloadReport, source *reloadSource, path string, value reflect.Value) {
+	if reported, ok := source.reported[path]; ok && equalValue(reported, value) {
+		return
+	}
+	source.reported[path] = value
+	if reason, ok := w.rejectedReason(path); ok {
+		if report.Rejected == nil {
+			report.Rejected = map[string]string{}
+		}
+		report.Rejected[path] = reason
+		return
+	}
+	report.RestartRequired = append(report.RestartRequired, path)
+}
+
+// rejectedReason returns the reason registered by RejectChange for the path or the one which the path is under.
+func (w *watcher) rejectedReason(path string) (string, bool) {
+	for rejected, reason := range w.rejected {
+		if path == rejected || strings.HasPrefix(path, rejected+".") {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+func (w *watcher) watch(report func(*ReloadReport, error)) (func() error, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]bool{}
+	w.mutex.Lock()
+	for _, source := range w.sources {
+		if source.file == "" {
+			continue
+		}
+		files[filepath.Clean(source.file)] = true
+		// the directory is watched, because many editors replace the file instead of writing to it.
+		if err := notify.Add(filepath.Dir(source.file)); err != nil {
+			w.mutex.Unlock()
+			_ = notify.Close()
+			return nil, err
+		}
+	}
+	w.mutex.Unlock()
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-notify.Events:
+				if !ok {
+					return
+				}
+				if !files[filepath.Clean(event.Name)] || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDelay, func() { report(w.reload()) })
+			case err, ok := <-notify.Errors:
+				if !ok {
+					return
+				}
+				report(nil, err)
+			}
+		}
+	}()
+	return notify.Close, nil
+}
+
+// changedPaths returns the sorted paths whose values are different.
+func changedPaths(current map[string]reflect.Value, loaded map[string]reflect.Value) []string {
+	var paths []string
+	for path, value := range loaded {
+		if old, ok := current[path]; !ok || !equalValue(old, value) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func equalValue(a reflect.Value, b reflect.Value) bool {
+	if a.Kind() == reflect.Func {
+		return a.Pointer() == b.Pointer()
+	}
+	if text, ok := marshalText(a); ok {
+		other, _ := marshalText(b)
+		return text == other
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func marshalText(value reflect.Value) (string, bool) {
+	if !value.Type().Implements(textMarshalerType) {
+		return "", false
+	}
+	text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+	return string(text), err == nil
+}
+
+// flatten returns the values of the exported fields of the configuration by their yaml paths.
+func flatten(config interface{}) map[string]reflect.Value {
+	values := map[string]reflect.Value{}
+	value := reflect.ValueOf(config)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	flattenStruct(value, "", values)
+	return values
+}
+
+func flattenStruct(value reflect.Value, prefix string, values map[string]reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, inline := fieldKey(field)
+		if key == "-" {
+			continue
+		}
+		path := prefix
+		if !inline {
+			path = yamlPath(prefix, key)
+		}
+		flattenValue(value.Field(i), path, values)
+	}
+}
+
+func flattenValue(value reflect.Value, path string, values map[string]reflect.Value) {
+	switch {
+	case value.Type().Implements(textMarshalerType):
+		values[path] = value
+	case value.Kind() == reflect.Struct:
+		flattenStruct(value, path, values)
+	case value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Struct:
+		flattenStruct(value.Elem(), path, values)
+	default:
+		values[path] = value
+	}
+}
+
+func yamlPath(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
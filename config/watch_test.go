@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload_AppliesReloadableValues(t *testing.T) {
+	w, file := prepareForWatchTest(t, "extension:\n  cors_enabled: false")
+	var applied []interface{}
+	w.onChange("extension.cors_enabled", func(value interface{}) { applied = append(applied, value) })
+
+	writeFile(t, file, watchTestBase+"extension:\n  cors_enabled: true")
+	report, err := w.reload()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{true}, applied)
+	assert.Equal(t, []string{"extension.cors_enabled"}, report.Applied)
+	assert.Empty(t, report.RestartRequired)
+
+	// the applied value becomes the running one.
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.Len(t, applied, 1)
+}
+
+func TestReload_RestartRequired(t *testing.T) {
+	w, file := prepareForWatchTest(t, "swagger:\n  path: /swagger/.*")
+
+	writeFile(t, file, watchTestBase+"swagger:\n  path: /docs/.*")
+	report, err := w.reload()
+
+	assert.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.Equal(t, []string{"swagger.path"}, report.RestartRequired)
+
+	// the same change is reported once.
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Empty(t, report.RestartRequired)
+
+	writeFile(t, file, watchTestBase+"swagger:\n  path: /api-docs/.*")
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"swagger.path"}, report.RestartRequired)
+
+	// the restored value is reported again when it is changed next time.
+	writeFile(t, file, watchTestBase+"swagger:\n  path: /swagger/.*")
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Empty(t, report.RestartRequired)
+	writeFile(t, file, watchTestBase+"swagger:\n  path: /api-docs/.*")
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"swagger.path"}, report.RestartRequired)
+}
+
+func TestReload_RejectChange(t *testing.T) {
+	w, file := prepareForWatchTest(t, "swagger:\n  enabled: false\n  path: /swagger/.*")
+	w.rejectChange("swagger", "the routes are registered at startup")
+
+	writeFile(t, file, watchTestBase+"swagger:\n  enabled: true\n  path: /docs/.*")
+	report, err := w.reload()
+
+	assert.NoError(t, err)
+	assert.Empty(t, report.RestartRequired)
+	assert.Equal(t, map[string]string{"swagger.enabled": "the routes are registered at startup",
+		"swagger.path": "the routes are registered at startup"}, report.Rejected)
+
+	report, err = w.reload()
+	assert.NoError(t, err)
+	assert.Empty(t, report.Rejected)
+}
+
+func TestReload_InvalidContentKeepsRunningConfig(t *testing.T) {
+	w, file := prepareForWatchTest(t, "extension:\n  cors_enabled: false")
+	called := false
+	w.onChange("extension.cors_enabled", func(interface{}) { called = true })
+
+	writeFile(t, file, watchTestBase+"extension:\n  cors_enabled: [")
+	_, err := w.reload()
+	assert.Error(t, err)
+	assert.False(t, called)
+
+	writeFile(t, file, watchTestBase+"extension:\n  cors_enabled: false")
+	report, err := w.reload()
+	assert.NoError(t, err)
+	assert.Empty(t, report.Applied)
+	assert.False(t, called)
+}
+
+func TestReload_ValidationError(t *testing.T) {
+	w, file := prepareForWatchTest(t, "swagger:\n  path: /swagger/.*")
+	w.onChange("swagger.path", func(interface{}) { t.Fatal("the invalid value must not be applied") })
+
+	writeFile(t, file, watchTestBase+"swagger:\n  path: /swagger/(.*")
+	_, err := w.reload()
+
+	assert.ErrorContains(t, err, "swagger.path")
+}
+
+func TestWatch_ReloadsChangedFile(t *testing.T) {
+	w, file := prepareForWatchTest(t, "extension:\n  cors_enabled: false")
+	applied := make(chan interface{}, 1)
+	w.onChange("extension.cors_enabled", func(value interface{}) { applied <- value })
+
+	stop, err := w.watch(func(*ReloadReport, error) {})
+	require.NoError(t, err)
+	defer func() { _ = stop() }()
+
+	writeFile(t, file, watchTestBase+"extension:\n  cors_enabled: true")
+	select {
+	case value := <-applied:
+		assert.Equal(t, true, value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the changed file wasn't reloaded")
+	}
+}
+
+// watchTestBase is the valid settings shared by the configuration files of the tests.
+const watchTestBase = "database:\n  host: book.db\nlog:\n  request_log_format: ${uri}\n"
+
+// prepareForWatchTest writes the configuration file, and registers it to a new watcher.
+func prepareForWatchTest(t *testing.T, content string) (*watcher, string) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.test.yml")
+	writeFile(t, file, watchTestBase+content)
+	load := func() (interface{}, error) {
+		conf := &Config{}
+		if _, err := ReadConfigFile(os.DirFS(dir), "application.test", conf); err != nil {
+			return nil, err
+		}
+		if errs := conf.Validate(); len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return conf, nil
+	}
+	current, err := load()
+	require.NoError(t, err)
+
+	w := newWatcher()
+	w.addSource(file, current, load)
+	return w, file
+}
@@ -1,6 +1,8 @@
 package container
 
 import (
+	"context"
+
 	"github.com/ybkuroki/go-webapp-sample/config"
 	"github.com/ybkuroki/go-webapp-sample/logger"
 	"github.com/ybkuroki/go-webapp-sample/repository"
@@ -15,6 +17,7 @@ type Container interface {
 	GetMessages() map[string]string
 	GetLogger() logger.Logger
 	GetEnv() string
+	Shutdown(ctx context.Context) error
 }
 
 // container struct is for sharing data which such as database setting,
@@ -64,3 +67,25 @@ func (c *container) GetLogger() logger.Logger {
 func (c *container) GetEnv() string {
 	return c.env
 }
+
+// Shutdown closes the repository and flushes the logs, in this order so the logs of closing the repository
+// are flushed too. Every step is attempted even when the previous one fails, and the first error is returned.
+// It returns the error of the context when the steps don't finish by its deadline.
+func (c *container) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		var first error
+		for _, step := range []func() error{c.rep.Close, c.logger.Sync} {
+			if err := step(); err != nil && first == nil {
+				first = err
+			}
+		}
+		done <- first
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+)
+
+// closingRepository is the repository which records its Close.
+type closingRepository struct {
+	repository.Repository
+	err    error
+	closed bool
+	block  chan struct{}
+}
+
+func (r *closingRepository) Close() error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.closed = true
+	return r.err
+}
+
+// syncingLogger is the logger which records its Sync.
+type syncingLogger struct {
+	logger.Logger
+	err    error
+	synced bool
+}
+
+func (l *syncingLogger) Sync() error {
+	l.synced = true
+	return l.err
+}
+
+func TestShutdown(t *testing.T) {
+	errClose, errSync := errors.New("close"), errors.New("sync")
+	tests := []struct {
+		name     string
+		closeErr error
+		syncErr  error
+		want     error
+	}{
+		{"success", nil, nil, nil},
+		{"close fails", errClose, nil, errClose},
+		{"sync fails", nil, errSync, errSync},
+		{"both fail", errClose, errSync, errClose},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, log := &closingRepository{err: tt.closeErr}, &syncingLogger{err: tt.syncErr}
+			container := NewContainer(rep, nil, nil, nil, log, "test")
+
+			err := container.Shutdown(context.Background())
+
+			assert.Equal(t, tt.want, err)
+			assert.True(t, rep.closed)
+			assert.True(t, log.synced)
+		})
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	rep := &closingRepository{block: make(chan struct{})}
+	defer close(rep.block)
+	container := NewContainer(rep, nil, nil, nil, &syncingLogger{}, "test")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := container.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -83,7 +83,8 @@ func (controller *accountController) Login(c echo.Context) error {
 		return c.JSON(http.StatusOK, account)
 	}
 
-	authenticate, a := controller.service.AuthenticateByUsernameAndPassword(dto.UserName, dto.Password)
+	authenticate, a := controller.service.AuthenticateByUsernameAndPassword(
+		c.Request().Context(), dto.UserName, dto.Password)
 	if authenticate {
 		_ = sess.SetAccount(c, a)
 		_ = sess.Save(c)
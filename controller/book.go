@@ -40,7 +40,7 @@ func NewBookController(container container.Container) BookController {
 // @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
 // @Router /books/{book_id} [get]
 func (controller *bookController) GetBook(c echo.Context) error {
-	book, err := controller.service.FindByID(c.Param("id"))
+	book, err := controller.service.FindByID(c.Request().Context(), c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
@@ -61,7 +61,8 @@ func (controller *bookController) GetBook(c echo.Context) error {
 // @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
 // @Router /books [get]
 func (controller *bookController) GetBookList(c echo.Context) error {
-	book, err := controller.service.FindBooksByTitle(c.QueryParam("query"), c.QueryParam("page"), c.QueryParam("size"))
+	book, err := controller.service.FindBooksByTitle(
+		c.Request().Context(), c.QueryParam("query"), c.QueryParam("page"), c.QueryParam("size"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
@@ -84,7 +85,7 @@ func (controller *bookController) CreateBook(c echo.Context) error {
 	if err := c.Bind(dto); err != nil {
 		return c.JSON(http.StatusBadRequest, dto)
 	}
-	book, result := controller.service.CreateBook(dto)
+	book, result := controller.service.CreateBook(c.Request().Context(), dto)
 	if result != nil {
 		return c.JSON(http.StatusBadRequest, result)
 	}
@@ -108,7 +109,7 @@ func (controller *bookController) UpdateBook(c echo.Context) error {
 	if err := c.Bind(dto); err != nil {
 		return c.JSON(http.StatusBadRequest, dto)
 	}
-	book, result := controller.service.UpdateBook(dto, c.Param("id"))
+	book, result := controller.service.UpdateBook(c.Request().Context(), dto, c.Param("id"))
 	if result != nil {
 		return c.JSON(http.StatusBadRequest, result)
 	}
@@ -127,7 +128,7 @@ func (controller *bookController) UpdateBook(c echo.Context) error {
 // @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
 // @Router /books/{book_id} [delete]
 func (controller *bookController) DeleteBook(c echo.Context) error {
-	book, result := controller.service.DeleteBook(c.Param("id"))
+	book, result := controller.service.DeleteBook(c.Request().Context(), c.Param("id"))
 	if result != nil {
 		return c.JSON(http.StatusBadRequest, result)
 	}
@@ -248,7 +248,7 @@ func TestDeleteBook_Failure(t *testing.T) {
 }
 
 func setUpTestData(container container.Container) {
-	model := model.NewBook("Test1", "123-123-123-1", 1, 1)
+	model := model.NewBook("Test1", "978-4-87311-842-0", 1, 1)
 	repo := container.GetRepository()
 	_, _ = model.Create(repo)
 }
@@ -256,7 +256,7 @@ func setUpTestData(container container.Container) {
 func createBookForCreate() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 	}
@@ -274,7 +274,7 @@ func createBookForValidationError() *dto.BookDto {
 func createBookForBindError() *BookDtoForBindError {
 	return &BookDtoForBindError{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: "Test",
 		FormatID:   "Test",
 	}
@@ -283,7 +283,7 @@ func createBookForBindError() *BookDtoForBindError {
 func createResultForBindError() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 0,
 		FormatID:   0,
 	}
@@ -303,7 +303,7 @@ func createResultForDeleteError() map[string]string {
 func createBookForUpdate() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test2",
-		Isbn:       "123-123-123-2",
+		Isbn:       "978-4-87311-843-7",
 		CategoryID: 2,
 		FormatID:   2,
 	}
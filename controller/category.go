@@ -33,5 +33,5 @@ func NewCategoryController(container container.Container) CategoryController {
 // @Failure 401 {string} false "Failed to the authentication."
 // @Router /categories [get]
 func (controller *categoryController) GetCategoryList(c echo.Context) error {
-	return c.JSON(http.StatusOK, controller.service.FindAllCategories())
+	return c.JSON(http.StatusOK, controller.service.FindAllCategories(c.Request().Context()))
 }
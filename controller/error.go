@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
 	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/logger"
 )
 
 // APIError has a error code and a message.
@@ -29,13 +32,17 @@ func NewErrorController(container container.Container) ErrorController {
 
 // JSONError is cumstomize error handler
 func (controller *errorController) JSONError(err error, c echo.Context) {
-	logger := controller.container.GetLogger()
+	log := controller.container.GetLogger()
 	code := http.StatusInternalServerError
 	msg := http.StatusText(code)
 
+	var appErr *apperror.Error
 	if he, ok := err.(*echo.HTTPError); ok {
 		code = he.Code
 		msg = he.Message.(string)
+	} else if errors.As(err, &appErr) {
+		code = appErr.Code.HTTPStatus()
+		msg = http.StatusText(code)
 	}
 
 	var apierr APIError
@@ -44,8 +51,8 @@ func (controller *errorController) JSONError(err error, c echo.Context) {
 
 	if !c.Response().Committed {
 		if reserr := c.JSON(code, apierr); reserr != nil {
-			logger.GetZapLogger().Errorf(reserr.Error())
+			log.GetZapLogger().Errorf(reserr.Error())
 		}
 	}
-	logger.GetZapLogger().Debugf(err.Error())
+	log.GetZapLogger().Debugw(err.Error(), logger.Err(err))
 }
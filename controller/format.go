@@ -33,5 +33,5 @@ func NewFormatController(container container.Container) FormatController {
 // @Failure 401 {string} false "Failed to the authentication."
 // @Router /formats [get]
 func (controller *formatController) GetFormatList(c echo.Context) error {
-	return c.JSON(http.StatusOK, controller.service.FindAllFormats())
+	return c.JSON(http.StatusOK, controller.service.FindAllFormats(c.Request().Context()))
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/service"
 )
 
 // HealthController is a controller returns the current status of this application.
@@ -14,22 +15,29 @@ type HealthController interface {
 
 type healthController struct {
 	container container.Container
+	service   service.HealthService
 }
 
 // NewHealthController is constructor.
 func NewHealthController(container container.Container) HealthController {
-	return &healthController{container: container}
+	return &healthController{container: container, service: service.NewHealthService(container)}
 }
 
-// GetHealthCheck returns whether this application is alive or not.
+// GetHealthCheck returns the status of the logger and the database of this application.
+// It returns 503 when any of them is unhealthy, so the instance is taken out of the load balancer.
 // @Summary Get the status of this application
 // @Description Get the status of this application
 // @Tags Health
 // @Accept  json
 // @Produce  json
-// @Success 200 {string} message "healthy: This application is started."
-// @Failure 404 {string} message "None: This application is stopped."
+// @Success 200 {object} service.HealthStatus "This application is healthy."
+// @Failure 503 {object} service.HealthStatus "The logger or the database is unhealthy."
 // @Router /health [get]
 func (controller *healthController) GetHealthCheck(c echo.Context) error {
-	return c.JSON(http.StatusOK, "healthy")
+	status, err := controller.service.HealthStatus(c.Request().Context())
+	if err != nil {
+		controller.container.GetLogger().GetZapLogger().Warnf("The health check failed: %s", err)
+		return c.JSON(http.StatusServiceUnavailable, status)
+	}
+	return c.JSON(http.StatusOK, status)
 }
@@ -1,13 +1,20 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"github.com/ybkuroki/go-webapp-sample/service"
 	"github.com/ybkuroki/go-webapp-sample/test"
 )
 
@@ -23,5 +30,38 @@ func TestGetHealthCheck(t *testing.T) {
 	router.ServeHTTP(rec, req)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.JSONEq(t, `"healthy"`, rec.Body.String())
+	var status service.HealthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.True(t, status.Healthy)
+	assert.True(t, status.Logger.Healthy)
+	assert.True(t, status.Database.Healthy)
+}
+
+func TestGetHealthCheck_Unhealthy(t *testing.T) {
+	router, c := test.PrepareForControllerTest(false)
+	unreachable := container.NewContainer(unreachableRepository{c.GetRepository()}, c.GetSession(), c.GetConfig(),
+		c.GetMessages(), c.GetLogger(), c.GetEnv())
+
+	health := NewHealthController(unreachable)
+	router.GET(config.APIHealth, func(c echo.Context) error { return health.GetHealthCheck(c) })
+
+	req := httptest.NewRequest("GET", config.APIHealth, nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var status service.HealthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "connection refused", status.Database.Error)
+}
+
+// unreachableRepository is the repository whose database can't be reached.
+type unreachableRepository struct {
+	repository.Repository
+}
+
+func (unreachableRepository) Ping(context.Context) error {
+	return errors.New("connection refused")
 }
@@ -8,8 +8,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/logger"
 	"github.com/ybkuroki/go-webapp-sample/test"
 	"github.com/ybkuroki/go-webapp-sample/util"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 )
 
@@ -34,6 +36,64 @@ func TestLogging(t *testing.T) {
 	assert.True(t, assertLogger("[gorm] ", allLogs))
 }
 
+func TestLogging_RequestID(t *testing.T) {
+	router, container, logs := test.PrepareForLoggerTest()
+
+	category := NewCategoryController(container)
+	router.GET(config.APICategories, func(c echo.Context) error { return category.GetCategoryList(c) })
+
+	req := httptest.NewRequest("GET", config.APICategories, nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1234")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1234", rec.Header().Get(echo.HeaderXRequestID))
+	withID := logs.FilterField(zap.String(logger.RequestIDKey, "req-1234")).All()
+	assert.True(t, assertLogger("[gorm] SELECT", withID))
+	assert.True(t, assertLogger("/api/categories GET 200", withID))
+}
+
+func TestLogging_ContextFields(t *testing.T) {
+	router, container, logs := test.PrepareForLoggerTest()
+
+	category := NewCategoryController(container)
+	withTenant := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.WithFields(req.Context(), "tenant_id", "tenant-1")))
+			return next(c)
+		}
+	}
+	router.GET(config.APICategories, func(c echo.Context) error { return category.GetCategoryList(c) }, withTenant)
+
+	req := httptest.NewRequest("GET", config.APICategories, nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	withTenantID := logs.FilterField(zap.String("tenant_id", "tenant-1")).All()
+	assert.True(t, assertLogger("[gorm] SELECT", withTenantID))
+	assert.True(t, assertLogger("/api/categories GET 200", withTenantID))
+}
+
+func TestLogging_GeneratedRequestID(t *testing.T) {
+	router, container, _ := test.PrepareForLoggerTest()
+
+	category := NewCategoryController(container)
+	router.GET(config.APICategories, func(c echo.Context) error { return category.GetCategoryList(c) })
+
+	req := httptest.NewRequest("GET", config.APICategories, nil)
+	req.Header.Set(echo.HeaderXRequestID, "broken\nline")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(echo.HeaderXRequestID)
+	assert.NotEmpty(t, id)
+	assert.NotEqual(t, "broken\nline", id)
+}
+
 func assertLogger(message string, logs []observer.LoggedEntry) bool {
 	for _, l := range logs {
 		if strings.Contains(l.Message, message) {
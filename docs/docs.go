@@ -461,15 +461,15 @@ var doc = `{
                 "summary": "Get the status of this application",
                 "responses": {
                     "200": {
-                        "description": "healthy: This application is started.",
+                        "description": "This application is healthy.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.HealthStatus"
                         }
                     },
-                    "404": {
-                        "description": "None: This application is stopped.",
+                    "503": {
+                        "description": "The logger or the database is unhealthy.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.HealthStatus"
                         }
                     }
                 }
@@ -619,6 +619,36 @@ var doc = `{
                     "type": "integer"
                 }
             }
+        },
+        "service.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "latency": {
+                    "description": "Latency is the time taken by the check of the component.",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.HealthStatus": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "$ref": "#/definitions/service.ComponentStatus"
+                },
+                "healthy": {
+                    "description": "Healthy is true when all components are healthy.",
+                    "type": "boolean"
+                },
+                "logger": {
+                    "$ref": "#/definitions/service.ComponentStatus"
+                }
+            }
         }
     }
 }`
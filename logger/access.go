@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// accessMessage is the message of the access log, which is written once per request.
+const accessMessage = "request completed"
+
+// AccessFields is the contents of the access log of a request.
+type AccessFields struct {
+	Method string
+	// Path is the path of the request URL, such as /api/books/1.
+	Path     string
+	Status   int
+	Duration time.Duration
+	// Bytes is the size of the response body.
+	Bytes     int64
+	RequestID string
+}
+
+// Access writes the access log of a request as a structured info log, whose fields have the same names
+// for every request, such as method, path, status, duration, bytes and request_id, so they can be queried.
+func (log *logger) Access(fields AccessFields) {
+	log.Zap.Desugar().Info(accessMessage,
+		zap.String("method", fields.Method),
+		zap.String("path", fields.Path),
+		zap.Int("status", fields.Status),
+		zap.Duration("duration", fields.Duration),
+		zap.Int64("bytes", fields.Bytes),
+		zap.String(RequestIDKey, fields.RequestID),
+	)
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccess(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.Access(AccessFields{Method: "GET", Path: "/api/books/1", Status: 200, Duration: 15 * time.Millisecond,
+		Bytes: 128, RequestID: "abc"})
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, zap.InfoLevel, entries[0].Level)
+		assert.Equal(t, "request completed", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{"method": "GET", "path": "/api/books/1", "status": int64(200),
+			"duration": 15 * time.Millisecond, "bytes": int64(128), "request_id": "abc"}, entries[0].ContextMap())
+	}
+}
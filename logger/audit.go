@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditConfig represents the setting for the audit log.
+type AuditConfig struct {
+	// OutputPaths is the list of the destinations of the audit log. The audit log is disabled when it is empty.
+	OutputPaths []string `json:"output_paths" yaml:"output_paths"`
+	// Journal is the write-ahead journal of the audit log, which keeps the entries which haven't been delivered
+	// to the destinations, such as by a crash, until they are replayed at the next startup.
+	Journal JournalConfig `json:"journal" yaml:"journal"`
+	// FlushInterval is the interval of the batches of the audit entries, such as 1s, which are buffered
+	// and written and synced together, instead of one by one. Sync and the shutdown write all of the buffered ones,
+	// but a crash loses the ones buffered since the last batch. Zero means every entry is written at once.
+	// It can't be used with the journal, which syncs every entry.
+	FlushInterval config.Duration `json:"flush_interval" yaml:"flush_interval" unit:"ms"`
+}
+
+// newAuditLogger creates the logger for the audit log, and returns its journal and the function which closes its files.
+// It is never sampled and never rotated, and records every entry regardless of the level of the main log.
+// The journal is nil when it isn't enabled.
+func newAuditLogger(cfg *AuditConfig) (*zap.Logger, *journal, func(), error) {
+	if len(cfg.OutputPaths) == 0 {
+		return zap.NewNop(), nil, func() {}, nil
+	}
+	writer, closeAudit, err := zap.Open(cfg.OutputPaths...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cfg.FlushInterval > 0 {
+		batch := newBatchWriter(writer, cfg.FlushInterval.Std())
+		return newJSONLogger(batch, "event"), nil, func() { _ = batch.close(); closeAudit() }, nil
+	}
+	if cfg.Journal.Path == "" {
+		return newJSONLogger(writer, "event"), nil, closeAudit, nil
+	}
+	j, err := openJournal(&cfg.Journal, writer)
+	if err != nil {
+		closeAudit()
+		return nil, nil, nil, fmt.Errorf("failed to open the journal: %w", err)
+	}
+	return newJSONLogger(j, "event"), j, func() { _ = j.close(); closeAudit() }, nil
+}
+
+// newJSONSinkLogger creates the logger which writes every entry to the paths by newJSONLogger,
+// and returns the function which closes its files. It discards the entries without paths.
+func newJSONSinkLogger(paths []string, messageKey string) (*zap.Logger, func(), error) {
+	if len(paths) == 0 {
+		return zap.NewNop(), func() {}, nil
+	}
+	writer, closeSink, err := zap.Open(paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newJSONLogger(writer, messageKey), closeSink, nil
+}
+
+// newJSONLogger creates the logger which writes every entry as a json line whose message has the given key,
+// such as the audit log.
+func newJSONLogger(writer zapcore.WriteSyncer, messageKey string) *zap.Logger {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "time",
+		MessageKey:     messageKey,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	core := zapcore.NewCore(enc, writer, zap.LevelEnablerFunc(func(zapcore.Level) bool { return true }))
+	return zap.New(core, zap.WithClock(auditClock{}))
+}
+
+// auditClock stamps the audit entries and the metrics by clock.Now, so the tests can fix their time.
+type auditClock struct{}
+
+// Now returns the current time of clock.Now.
+func (auditClock) Now() time.Time {
+	return clock.Now()
+}
+
+// NewTicker returns a ticker of the real time.
+func (auditClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+// Audit writes an audit entry to the audit log.
+func (log *logger) Audit(event string, fields ...zap.Field) {
+	if log.audit == nil {
+		return
+	}
+	log.audit.Info(event, fields...)
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAudit_WritesToAuditSink(t *testing.T) {
+	mainCore, mainLogs := observer.New(zapcore.ErrorLevel)
+	auditCore, auditLogs := observer.New(zapcore.InfoLevel)
+	log := &logger{Zap: zap.New(mainCore).Sugar(), config: createTestConfig(), audit: zap.New(auditCore)}
+
+	log.Audit("book.create", zap.Uint("id", 1))
+
+	require.Equal(t, 1, auditLogs.Len())
+	assert.Equal(t, "book.create", auditLogs.All()[0].Message)
+	assert.Equal(t, map[string]interface{}{"id": uint64(1)}, auditLogs.All()[0].ContextMap())
+	assert.Equal(t, 0, mainLogs.Len())
+}
+
+func TestAudit_WritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, _, closeAudit, err := newAuditLogger(&AuditConfig{OutputPaths: []string{path}})
+	require.NoError(t, err)
+	t.Cleanup(closeAudit)
+	log := &logger{Zap: zap.NewNop().Sugar(), config: createTestConfig(), audit: audit}
+
+	t.Cleanup(clock.Set(clock.NewFake(time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)).Now))
+
+	log.Audit("book.delete", zap.Uint("id", 2))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"time":"2024-04-01T09:30:00.000Z","event":"book.delete","id":2`)
+}
+
+func TestAudit_Disabled(t *testing.T) {
+	log := NewLogger(zap.NewNop().Sugar())
+
+	assert.NotPanics(t, func() { log.Audit("book.update") })
+}
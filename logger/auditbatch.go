@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// auditBatchSize is the size of the buffered audit entries from which they are written without waiting
+// for audit.flush_interval, so a burst doesn't hold too many entries in memory.
+const auditBatchSize = 256 << 10
+
+// batchWriter is the writer of the audit log which buffers the entries, and writes and syncs them to the sink
+// as a batch every interval, so a burst of entries, such as by a bulk operation, costs a sync per batch
+// instead of per entry. No entry is dropped: Sync and close write all of the buffered ones,
+// so the entries are lost only by a crash, at most the ones buffered since the last batch.
+type batchWriter struct {
+	mu   sync.Mutex
+	buf  []byte
+	sink zapcore.WriteSyncer
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newBatchWriter creates the writer which writes the buffered entries to the sink every interval.
+func newBatchWriter(sink zapcore.WriteSyncer, interval time.Duration) *batchWriter {
+	w := &batchWriter{sink: sink, stop: make(chan struct{}), done: make(chan struct{})}
+	go w.run(interval)
+	return w
+}
+
+// run writes the buffered entries every interval until the writer is closed.
+func (w *batchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			_ = w.Sync()
+		}
+	}
+}
+
+// Write buffers the entry. The buffer is written at once when it reaches auditBatchSize.
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= auditBatchSize {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Sync writes the buffered entries to the sink and syncs it.
+func (w *batchWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// flush writes the buffered entries and syncs the sink. The entries are kept for the next flush
+// when the write fails, so they are retried instead of being dropped.
+func (w *batchWriter) flush() error {
+	if len(w.buf) > 0 {
+		if _, err := w.sink.Write(w.buf); err != nil {
+			return err
+		}
+		w.buf = w.buf[:0]
+	}
+	return w.sink.Sync()
+}
+
+// close stops the periodic writes, and writes the buffered entries.
+func (w *batchWriter) close() error {
+	close(w.stop)
+	<-w.done
+	return w.Sync()
+}
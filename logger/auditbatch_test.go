@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+)
+
+func TestBatchWriter_Burst(t *testing.T) {
+	sink := &auditSink{}
+	w := newBatchWriter(sink, time.Hour)
+	audit := newJSONLogger(w, "event")
+
+	for i := 0; i < 1000; i++ {
+		audit.Info("book.create")
+	}
+	assert.Empty(t, sink.events())
+	require.NoError(t, audit.Sync())
+
+	assert.Len(t, sink.events(), 1000)
+	assert.Equal(t, 1, sink.written)
+	audit.Info("book.delete")
+	require.NoError(t, w.close())
+	assert.Len(t, sink.events(), 1001)
+}
+
+func TestBatchWriter_RetriesFailedBatch(t *testing.T) {
+	sink := &auditSink{failAfter: 1, written: 1}
+	w := newBatchWriter(sink, time.Hour)
+	t.Cleanup(func() { _ = w.close() })
+	audit := newJSONLogger(w, "event")
+
+	audit.Info("book.create")
+	assert.Error(t, w.Sync())
+	sink.failAfter = 0
+	audit.Info("book.update")
+	require.NoError(t, w.Sync())
+
+	assert.Equal(t, []string{"book.create", "book.update"}, sink.events())
+}
+
+func TestNew_AuditFlushInterval(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, "app.log")}
+	cfg.Audit.OutputPaths = []string{filepath.Join(dir, "audit.log")}
+	cfg.Audit.FlushInterval = config.Duration(time.Hour)
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+
+	for i := 0; i < 500; i++ {
+		log.Audit("book.create")
+	}
+	require.NoError(t, log.Sync())
+
+	content, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 500, strings.Count(string(content), "\n"))
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// BackpressureBlock writes the logs synchronously, so the caller waits for a slow sink. It is the default.
+	BackpressureBlock = "block"
+	// BackpressureDropOldest queues the logs, and drops the oldest queued one when the queue is full.
+	BackpressureDropOldest = "drop_oldest"
+	// BackpressureDropNewest queues the logs, and drops the new one when the queue is full.
+	BackpressureDropNewest = "drop_newest"
+
+	// defaultSinkQueueSize is the size of the queue used when it isn't specified in the setting.
+	defaultSinkQueueSize = 1024
+	// droppedReportInterval is the interval of the warnings of the dropped logs.
+	droppedReportInterval = time.Minute
+	droppedReportFormat   = "dropped %d entries in the last minute"
+)
+
+// droppedEntriesTotal is the number of the log entries dropped by all loggers, which is published by expvar.
+var droppedEntriesTotal = expvar.NewInt("logger_dropped_entries_total")
+
+// countDropped counts a dropped log entry.
+func countDropped(dropped *atomic.Uint64) {
+	dropped.Add(1)
+	droppedEntriesTotal.Add(1)
+}
+
+// queueWriter is the zapcore.WriteSyncer which queues the writes, and writes them by a dedicated goroutine,
+// so the caller isn't blocked by a slow sink. When the queue is full, the oldest or the newest write is dropped.
+type queueWriter struct {
+	writer     zapcore.WriteSyncer
+	dropOldest bool
+	size       int
+	dropped    *atomic.Uint64
+
+	mutex   sync.Mutex
+	changed *sync.Cond
+	queue   [][]byte
+	writing bool
+	// closed stops the goroutine after the queued writes are done, and done is closed when it has returned.
+	closed bool
+	done   chan struct{}
+}
+
+// newQueueWriter wraps the writer by the queue of the given size. The dropped writes are counted to dropped.
+func newQueueWriter(writer zapcore.WriteSyncer, policy string, size int, dropped *atomic.Uint64) *queueWriter {
+	if size <= 0 {
+		size = defaultSinkQueueSize
+	}
+	w := &queueWriter{writer: writer, dropOldest: policy == BackpressureDropOldest, size: size, dropped: dropped,
+		done: make(chan struct{})}
+	w.changed = sync.NewCond(&w.mutex)
+	go w.run()
+	return w
+}
+
+// run writes the queued data until the writer is closed and its queue is empty.
+func (w *queueWriter) run() {
+	defer close(w.done)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for {
+		for len(w.queue) == 0 {
+			if w.closed {
+				return
+			}
+			w.changed.Wait()
+		}
+		data := w.queue[0]
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+		w.writing = true
+		w.mutex.Unlock()
+
+		_, _ = w.writer.Write(data)
+
+		w.mutex.Lock()
+		w.writing = false
+		w.changed.Broadcast()
+	}
+}
+
+// Write queues the data. It never blocks, and drops a write when the queue is full.
+func (w *queueWriter) Write(p []byte) (int, error) {
+	// the buffer is copied because it is reused by the caller after returning.
+	data := append([]byte(nil), p...)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if len(w.queue) >= w.size {
+		countDropped(w.dropped)
+		if !w.dropOldest {
+			return len(p), nil
+		}
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+	}
+	w.queue = append(w.queue, data)
+	w.changed.Broadcast()
+	return len(p), nil
+}
+
+// Sync waits until the queued writes are done, and flushes the writer.
+func (w *queueWriter) Sync() error {
+	w.mutex.Lock()
+	for len(w.queue) > 0 || w.writing {
+		w.changed.Wait()
+	}
+	w.mutex.Unlock()
+	return w.writer.Sync()
+}
+
+// close writes the queued data, and stops the goroutine writing them.
+func (w *queueWriter) close() {
+	w.mutex.Lock()
+	w.closed = true
+	w.changed.Broadcast()
+	w.mutex.Unlock()
+	<-w.done
+}
+
+// droppedReporter warns the number of the log entries dropped since the last report, so drops are never silent.
+type droppedReporter struct {
+	log      *zap.Logger
+	dropped  *atomic.Uint64
+	reported uint64
+}
+
+// reportDropped warns the dropped entries every minute until done is closed.
+func reportDropped(log *zap.Logger, dropped *atomic.Uint64, done <-chan struct{}) {
+	reporter := &droppedReporter{log: log, dropped: dropped}
+	ticker := time.NewTicker(droppedReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reporter.report()
+		case <-done:
+			return
+		}
+	}
+}
+
+// report warns the entries dropped since the last report. It writes nothing when none was dropped.
+func (r *droppedReporter) report() {
+	total := r.dropped.Load()
+	if count := total - r.reported; count > 0 {
+		r.log.Sugar().Warnf(droppedReportFormat, count)
+	}
+	r.reported = total
+}
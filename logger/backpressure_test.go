@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestQueueWriter_DropNewest(t *testing.T) {
+	lines, dropped := writeToStalledQueue(t, BackpressureDropNewest)
+
+	assert.Equal(t, []string{"0", "1", "2", "3"}, lines)
+	assert.Equal(t, uint64(6), dropped)
+}
+
+func TestQueueWriter_DropOldest(t *testing.T) {
+	lines, dropped := writeToStalledQueue(t, BackpressureDropOldest)
+
+	assert.Equal(t, []string{"0", "7", "8", "9"}, lines)
+	assert.Equal(t, uint64(6), dropped)
+}
+
+func TestWrapSink_Block(t *testing.T) {
+	slow := &slowWriter{release: make(chan struct{})}
+	close(slow.release)
+	dropped := &atomic.Uint64{}
+
+	writer := wrapSink(slow, "application.log", &SinkConfig{Backpressure: BackpressureBlock}, dropped, &diagnostics{})
+	for i := 0; i < 10; i++ {
+		_, err := writer.Write([]byte(fmt.Sprintf("%d\n", i)))
+		require.NoError(t, err)
+	}
+
+	assert.Same(t, slow, writer)
+	assert.Len(t, strings.Fields(slow.buf.String()), 10)
+	assert.Zero(t, dropped.Load())
+}
+
+func TestWrapSink_Queue(t *testing.T) {
+	cfg := &SinkConfig{Backpressure: BackpressureDropOldest, QueueSize: 8}
+
+	writer := wrapSink(&slowWriter{}, "application.log", cfg, &atomic.Uint64{}, &diagnostics{})
+
+	if queue, ok := writer.(*queueWriter); assert.True(t, ok) {
+		assert.Equal(t, 8, queue.size)
+		assert.True(t, queue.dropOldest)
+	}
+	stdout := &slowWriter{}
+	assert.Same(t, stdout, wrapSink(stdout, "stdout", cfg, &atomic.Uint64{}, &diagnostics{}))
+}
+
+func TestWrapSink_Outputs(t *testing.T) {
+	cfg := &SinkConfig{Backpressure: BackpressureDropNewest, QueueSize: 8, Outputs: map[string]SinkOutputConfig{
+		"audit.log":   {Backpressure: BackpressureBlock},
+		"metrics.log": {QueueSize: 16},
+	}}
+
+	audit := &slowWriter{}
+	assert.Same(t, audit, wrapSink(audit, "audit.log", cfg, &atomic.Uint64{}, &diagnostics{}))
+	metrics := wrapSink(&slowWriter{}, "metrics.log", cfg, &atomic.Uint64{}, &diagnostics{})
+	if queue, ok := metrics.(*queueWriter); assert.True(t, ok) {
+		assert.Equal(t, 16, queue.size)
+		assert.False(t, queue.dropOldest)
+	}
+	application := wrapSink(&slowWriter{}, "application.log", cfg, &atomic.Uint64{}, &diagnostics{})
+	if queue, ok := application.(*queueWriter); assert.True(t, ok) {
+		assert.Equal(t, 8, queue.size)
+	}
+}
+
+func TestQueueWriter_Close(t *testing.T) {
+	slow := &slowWriter{release: make(chan struct{})}
+	close(slow.release)
+	queue := newQueueWriter(slow, BackpressureDropNewest, 8, &atomic.Uint64{})
+	_, err := queue.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	queue.close()
+
+	select {
+	case <-queue.done:
+	default:
+		t.Fatal("the goroutine of the queue didn't exit")
+	}
+	slow.mutex.Lock()
+	defer slow.mutex.Unlock()
+	assert.Equal(t, "first\n", slow.buf.String())
+}
+
+func TestDroppedReporter(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	dropped := &atomic.Uint64{}
+	reporter := &droppedReporter{log: zap.New(core), dropped: dropped}
+	total := droppedEntriesTotal.Value()
+
+	reporter.report()
+	countDropped(dropped)
+	countDropped(dropped)
+	reporter.report()
+	reporter.report()
+	countDropped(dropped)
+	reporter.report()
+
+	messages := []string{}
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"dropped 2 entries in the last minute", "dropped 1 entries in the last minute"}, messages)
+	assert.Equal(t, total+3, droppedEntriesTotal.Value())
+}
+
+// writeToStalledQueue writes 10 lines to the queue of 3 lines by the policy while its sink is stalled,
+// and returns the lines written after the sink is released and the queue is synced.
+func writeToStalledQueue(t *testing.T, policy string) ([]string, uint64) {
+	slow := &slowWriter{release: make(chan struct{})}
+	dropped := &atomic.Uint64{}
+	queue := newQueueWriter(slow, policy, 3, dropped)
+
+	_, err := queue.Write([]byte("0\n"))
+	require.NoError(t, err)
+	// the first line is being written to the stalled sink, so it isn't in the queue.
+	require.Eventually(t, func() bool {
+		queue.mutex.Lock()
+		defer queue.mutex.Unlock()
+		return queue.writing
+	}, time.Second, time.Millisecond)
+	for i := 1; i < 10; i++ {
+		start := time.Now()
+		_, err := queue.Write([]byte(fmt.Sprintf("%d\n", i)))
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	}
+	close(slow.release)
+	require.NoError(t, queue.Sync())
+
+	slow.mutex.Lock()
+	defer slow.mutex.Unlock()
+	return strings.Fields(slow.buf.String()), dropped.Load()
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// zapPackage is the prefix of the functions of zap, which are skipped to find the caller of a log.
+const zapPackage = "go.uber.org/zap"
+
+// callerCore is the zapcore.Core which adds the caller only to the entries of minLevel or higher,
+// instead of zap.AddCaller which looks up the caller of every entry, including the frequent debug logs.
+// It must be the outermost core, because the caller is found from the stack of its Check.
+type callerCore struct {
+	zapcore.Core
+	minLevel zapcore.Level
+}
+
+func newCallerCore(core zapcore.Core, minLevel zapcore.Level) zapcore.Core {
+	return &callerCore{Core: core, minLevel: minLevel}
+}
+
+// With adds structured context to the core.
+func (c *callerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &callerCore{Core: c.Core.With(fields), minLevel: c.minLevel}
+}
+
+// Check determines whether the entry should be logged, and adds its caller when its level is minLevel or higher.
+func (c *callerCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked = c.Core.Check(entry, checked)
+	if checked != nil && entry.Level >= c.minLevel && !checked.Caller.Defined {
+		checked.Caller = findCaller()
+	}
+	return checked
+}
+
+// findCaller returns the first frame outside of zap above Check, which is the caller found by zap.AddCaller.
+func findCaller() zapcore.EntryCaller {
+	var pcs [16]uintptr
+	// skip runtime.Callers, findCaller and Check.
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, zapPackage+".") && !strings.HasPrefix(frame.Function, zapPackage+"/") {
+			return zapcore.EntryCaller{Defined: frame.PC != 0, PC: frame.PC, File: frame.File, Line: frame.Line,
+				Function: frame.Function}
+		}
+		if !more {
+			return zapcore.EntryCaller{}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCallerCore(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := zap.New(newCallerCore(core, zap.WarnLevel)).With(zap.String("component", "test"))
+	want, logged := observer.New(zap.DebugLevel)
+	wantLog := zap.New(want, zap.AddCaller())
+
+	log.Debug("debug")
+	log.Warn("warn")
+	log.Sugar().Errorf("error %d", 1)
+	wantLog.Warn("warn")
+	wantLog.Sugar().Errorf("error %d", 1)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 3) {
+		assert.False(t, entries[0].Caller.Defined)
+		for i, entry := range entries[1:] {
+			expected := logged.All()[i].Caller
+			assert.True(t, entry.Caller.Defined)
+			assert.Equal(t, expected.File, entry.Caller.File)
+			// the logs of wantLog are written two lines after the ones of log.
+			assert.Equal(t, expected.Line-2, entry.Caller.Line)
+			assert.Equal(t, expected.Function, entry.Caller.Function)
+		}
+	}
+}
+
+func TestNew_CallerMinLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := createTestConfig()
+	cfg.ZapConfig.EncoderConfig.CallerKey = "caller"
+	cfg.ZapConfig.EncoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{path}
+	warn := zapcore.WarnLevel
+	cfg.CallerMinLevel = &warn
+	log, err := New(cfg)
+	require.NoError(t, err)
+
+	log.GetZapLogger().Debugf("debug")
+	log.GetZapLogger().Warnf("warn")
+	require.NoError(t, log.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	entries := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &entries[i]))
+	}
+	if assert.Len(t, entries, 2) {
+		assert.NotContains(t, entries[0], "caller")
+		assert.Contains(t, entries[1]["caller"], "logger/caller_test.go:")
+	}
+}
+
+func BenchmarkCaller_Debug(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		log  *zap.Logger
+	}{
+		{"AddCaller", zap.New(newBenchmarkCore(), zap.AddCaller())},
+		{"CallerMinLevelWarn", zap.New(newCallerCore(newBenchmarkCore(), zap.WarnLevel))},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.log.Debug("SELECT * FROM `book`", zap.Int64("rows", 3))
+			}
+		})
+	}
+}
+
+func newBenchmarkCore() zapcore.Core {
+	cfg := createTestConfig().ZapConfig.EncoderConfig
+	cfg.CallerKey = "caller"
+	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+	return zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(io.Discard), zap.DebugLevel)
+}
@@ -0,0 +1,23 @@
+package logger
+
+import "context"
+
+// fieldsKey is the context key of the fields of the logs written with the context.
+type fieldsKey struct{}
+
+// WithFields returns the context which has the given key-value pairs, such as "tenant_id", 42, in addition
+// to the ones of ctx. They are added to the logs written with the context by WithContextFields,
+// so the sql logs of the statements run with the context of a request are attributed to its account or tenant.
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	fields := FieldsFromContext(ctx)
+	return context.WithValue(ctx, fieldsKey{}, append(fields[:len(fields):len(fields)], keysAndValues...))
+}
+
+// FieldsFromContext returns the key-value pairs of the context added by WithFields. It returns nil when there is none.
+func FieldsFromContext(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	return fields
+}
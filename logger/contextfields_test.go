@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithFields(t *testing.T) {
+	ctx := WithFields(context.Background(), "tenant_id", 42)
+	parent := WithFields(ctx, "account_name", "test")
+	child := WithFields(ctx, "job", "import")
+
+	assert.Equal(t, []interface{}{"tenant_id", 42, "account_name", "test"}, FieldsFromContext(parent))
+	assert.Equal(t, []interface{}{"tenant_id", 42, "job", "import"}, FieldsFromContext(child))
+	assert.Nil(t, FieldsFromContext(context.Background()))
+}
+
+func TestTrace_ContextFields(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := &Config{}
+	cfg.ZapConfig.Encoding = jsonEncoding
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	ctx := WithFields(WithRequestID(context.Background(), "abc"), "tenant_id", 42)
+	ctx = context.WithValue(ctx, statementKey{}, &statement{sql: "SELECT * FROM `book`", dialect: "sqlite"})
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT * FROM `book`", 3 }, nil)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, int64(42), entries[0].ContextMap()["tenant_id"])
+		assert.Equal(t, "abc", entries[0].ContextMap()[RequestIDKey])
+	}
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// SinkTypeStdout, SinkTypeStderr and SinkTypeFile are the types of the destinations of the logs.
+	SinkTypeStdout = "stdout"
+	SinkTypeStderr = "stderr"
+	SinkTypeFile   = "file"
+)
+
+// DiagnosticsReport is the current state of the logger, which shows which files are written and whether they fail.
+type DiagnosticsReport struct {
+	// ConfigFile is the path of the loaded configuration file.
+	ConfigFile string            `json:"configFile"`
+	Level      string            `json:"level"`
+	Rotation   RotateConfig      `json:"rotation"`
+	Sinks      []SinkDiagnostics `json:"sinks"`
+	// Preflight is the results of the check of the log files at startup.
+	Preflight []ProbeResult `json:"preflight,omitempty"`
+	// Build is the build and the runtime logged at startup.
+	Build *BuildInfo `json:"build,omitempty"`
+}
+
+// SinkDiagnostics is the state of a destination of the logs.
+type SinkDiagnostics struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	// BytesWritten is the number of the bytes written since the start.
+	BytesWritten uint64     `json:"bytesWritten"`
+	LastError    string     `json:"lastError,omitempty"`
+	LastErrorAt  *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// diagnostics collects the state of the destinations of a logger.
+type diagnostics struct {
+	configFile string
+	// probes is the results of the check of the log files at startup.
+	probes []ProbeResult
+	mutex  sync.Mutex
+	sinks  []*sinkRecorder
+	// callbacks are called with the internal errors of zap.
+	callbacks []func(error)
+	// stops stop the goroutines of the destinations, such as the queue of drop_newest, before their files are closed.
+	stops []func()
+}
+
+// record wraps the writer of the path by the recorder of its state.
+func (d *diagnostics) record(writer zapcore.WriteSyncer, path string) zapcore.WriteSyncer {
+	kind := SinkTypeFile
+	if path == SinkTypeStdout || path == SinkTypeStderr {
+		kind = path
+	}
+	recorder := &sinkRecorder{WriteSyncer: writer, path: path, kind: kind}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.sinks = append(d.sinks, recorder)
+	return recorder
+}
+
+// report returns the state of the destinations.
+func (d *diagnostics) report() []SinkDiagnostics {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	sinks := make([]SinkDiagnostics, 0, len(d.sinks))
+	for _, sink := range d.sinks {
+		sinks = append(sinks, sink.report())
+	}
+	return sinks
+}
+
+// onClose registers the function which stops a goroutine of the destinations when they are closed.
+func (d *diagnostics) onClose(stop func()) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stops = append(d.stops, stop)
+}
+
+// close stops the goroutines of the destinations in reverse order of their registration, so the outer writer
+// writes its queue to the inner one first, and closes the files. stdout and stderr are left open.
+func (d *diagnostics) close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := len(d.stops) - 1; i >= 0; i-- {
+		d.stops[i]()
+	}
+	d.stops = nil
+	var err error
+	for _, sink := range d.sinks {
+		if closer, ok := sink.WriteSyncer.(io.Closer); ok && sink.kind == SinkTypeFile {
+			err = errors.Join(err, closer.Close())
+		}
+	}
+	return err
+}
+
+// sinkRecorder is the zapcore.WriteSyncer which counts the written bytes and records the last failure,
+// instead of discarding it.
+type sinkRecorder struct {
+	zapcore.WriteSyncer
+	path    string
+	kind    string
+	written atomic.Uint64
+
+	mutex     sync.Mutex
+	lastErr   error
+	lastErrAt time.Time
+}
+
+// Write writes the data to the wrapped writer, and records the result.
+func (w *sinkRecorder) Write(p []byte) (int, error) {
+	n, err := w.WriteSyncer.Write(p)
+	w.written.Add(uint64(n))
+	if err != nil {
+		w.fail(err)
+	}
+	return n, err
+}
+
+// Sync flushes the wrapped writer, and records the failure of the files.
+// The failures of stdout and stderr are ignored, because they can't be synced when they are terminals.
+func (w *sinkRecorder) Sync() error {
+	err := w.WriteSyncer.Sync()
+	if err != nil && w.kind == SinkTypeFile {
+		w.fail(err)
+	}
+	return err
+}
+
+func (w *sinkRecorder) fail(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.lastErr = err
+	w.lastErrAt = clock.Now()
+}
+
+func (w *sinkRecorder) report() SinkDiagnostics {
+	sink := SinkDiagnostics{Path: w.path, Type: w.kind, BytesWritten: w.written.Load()}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.lastErr != nil {
+		at := w.lastErrAt
+		sink.LastError = w.lastErr.Error()
+		sink.LastErrorAt = &at
+	}
+	return sink
+}
+
+// Diagnostics returns the current state of the logger, such as the written files and their failures.
+func (log *logger) Diagnostics() DiagnosticsReport {
+	report := DiagnosticsReport{
+		Level:    zapcore.LevelOf(log.Zap.Desugar().Core()).String(),
+		Rotation: log.config.LogRotate,
+		Sinks:    []SinkDiagnostics{},
+		Build:    log.startup.Load(),
+	}
+	if log.diagnostics != nil {
+		report.ConfigFile = log.diagnostics.configFile
+		report.Sinks = log.diagnostics.report()
+		report.Preflight = log.diagnostics.probes
+	}
+	return report
+}
+
+// DiagnosticsHandler returns the handler which renders the diagnostics of the logger as JSON.
+func DiagnosticsHandler(log Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(log.Diagnostics())
+	}
+}
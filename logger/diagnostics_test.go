@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// failingSink fails every write and sync with the error.
+type failingSink struct {
+	err error
+}
+
+func (w failingSink) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func (w failingSink) Sync() error {
+	return w.err
+}
+
+func TestDiagnostics_FailingSink(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	t.Cleanup(clock.Set(clock.NewFake(at).Now))
+	diag := &diagnostics{configFile: "zaplogger.test.yml"}
+	sink := diag.record(failingSink{err: errors.New("no space left on device")}, "/var/log/app.log")
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(createTestConfig().ZapConfig.EncoderConfig), sink, zap.InfoLevel)
+	log := &logger{Zap: zap.New(core, zap.ErrorOutput(zapcore.AddSync(io.Discard))).Sugar(), config: createTestConfig(),
+		diagnostics: diag}
+
+	log.GetZapLogger().Info("lost")
+	report := log.Diagnostics()
+
+	assert.Equal(t, "zaplogger.test.yml", report.ConfigFile)
+	assert.Equal(t, "info", report.Level)
+	if assert.Len(t, report.Sinks, 1) {
+		assert.Equal(t, "/var/log/app.log", report.Sinks[0].Path)
+		assert.Equal(t, SinkTypeFile, report.Sinks[0].Type)
+		assert.Zero(t, report.Sinks[0].BytesWritten)
+		assert.Equal(t, "no space left on device", report.Sinks[0].LastError)
+		assert.Equal(t, &at, report.Sinks[0].LastErrorAt)
+	}
+}
+
+func TestDiagnostics_BytesWritten(t *testing.T) {
+	cfg := createTestConfig()
+	path := filepath.Join(t.TempDir(), "application.log")
+	cfg.ZapConfig.OutputPaths = []string{"stdout", path}
+	cfg.LogRotate.MaxSize = 10 * megabyte
+	diag := &diagnostics{}
+	zap, err := build(cfg, nil, nil, &atomic.Uint64{}, diag, nil)
+	require.NoError(t, err)
+	log := &logger{Zap: zap.Sugar(), config: cfg, diagnostics: diag}
+
+	log.GetZapLogger().Debug("written")
+	report := log.Diagnostics()
+
+	assert.Equal(t, "debug", report.Level)
+	if assert.Len(t, report.Sinks, 2) {
+		assert.Equal(t, SinkTypeStdout, report.Sinks[0].Type)
+		assert.Equal(t, path, report.Sinks[1].Path)
+		assert.Equal(t, report.Sinks[0].BytesWritten, report.Sinks[1].BytesWritten)
+		assert.NotZero(t, report.Sinks[1].BytesWritten)
+		assert.Empty(t, report.Sinks[1].LastError)
+	}
+}
+
+func TestDiagnosticsHandler(t *testing.T) {
+	log := NewLogger(zap.NewNop().Sugar())
+	rec := httptest.NewRecorder()
+
+	DiagnosticsHandler(log).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/debug/logger", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var report DiagnosticsReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Empty(t, report.Sinks)
+}
+
+func TestOnInternalError(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createTestConfig()
+	logDir := filepath.Join(dir, "logs")
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(logDir, "app.log")}
+	cfg.ZapConfig.ErrorOutputPaths = []string{filepath.Join(dir, "error.log")}
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.Preflight.CreateDirs = true
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+	// the log file can't be created under a regular file, so every write fails.
+	require.NoError(t, os.Remove(logDir))
+	require.NoError(t, os.WriteFile(logDir, nil, 0o600))
+
+	var internalErrs []error
+	log.OnInternalError(func(err error) { internalErrs = append(internalErrs, err) })
+	log.GetZapLogger().Info("lost")
+
+	if assert.Len(t, internalErrs, 1) {
+		assert.Contains(t, internalErrs[0].Error(), "write error")
+	}
+	errorLog, err := os.ReadFile(cfg.ZapConfig.ErrorOutputPaths[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(errorLog), "write error")
+}
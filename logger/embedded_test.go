@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/resources"
+)
+
+func TestEmbeddedDevelopConfig(t *testing.T) {
+	cfg := &Config{}
+	name, err := config.ReadConfigFile(resources.ConfigFiles, "config/zaplogger.develop", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "zaplogger.develop.yml", name)
+	require.NoError(t, cfg.Validate())
+
+	path := filepath.Join(t.TempDir(), "develop.log")
+	cfg.ZapConfig.OutputPaths = []string{path}
+	log, err := build(cfg, nil, nil, &atomic.Uint64{}, &diagnostics{}, nil)
+	require.NoError(t, err)
+	log.Info("embedded configuration")
+	_ = log.Sync()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "embedded configuration")
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldsError is the error which has its own fields in the logs, such as apperror.Error.
+type fieldsError interface {
+	LogFields() []zap.Field
+}
+
+// Err returns the field of the error for the logs, such as Errorw("failed to fetch data", logger.Err(err)).
+// When an error in the chain of err has its own fields, such as the code of apperror.Error,
+// they are added next to the error.
+func Err(err error) zap.Field {
+	var fe fieldsError
+	if err == nil || !errors.As(err, &fe) {
+		return zap.Error(err)
+	}
+	return zap.Inline(errorFields{err: err, fields: fe.LogFields()})
+}
+
+// errorFields is the error and its own fields, which are inlined into the log.
+type errorFields struct {
+	err    error
+	fields []zap.Field
+}
+
+// MarshalLogObject adds the error and its fields.
+func (f errorFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	zap.Error(f.err).AddTo(enc)
+	for _, field := range f.fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErr_AppError(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	cause := fmt.Errorf("tx: %w", errors.New("UNIQUE constraint failed"))
+	err := apperror.Wrap(cause, apperror.Conflict, "failed to create the category")
+
+	log.GetZapLogger().Errorw("failed", Err(fmt.Errorf("service: %w", err)))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "service: failed to create the category: tx: UNIQUE constraint failed", fields["error"])
+		assert.Equal(t, "conflict", fields["code"])
+		assert.Equal(t, []interface{}{"tx: UNIQUE constraint failed", "UNIQUE constraint failed"}, fields["causes"])
+	}
+}
+
+func TestErr_PlainError(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.GetZapLogger().Errorw("failed", Err(errors.New("failed to fetch data")))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "failed to fetch data", fields["error"])
+		assert.NotContains(t, fields, "code")
+	}
+}
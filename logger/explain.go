@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	explainCallbackName = "logger:explain"
+	explainMessage      = logTitle + "EXPLAIN"
+	sqliteDialect       = "sqlite"
+)
+
+// explainQuery runs EXPLAIN on the executed SELECT, and attaches the plan to the statement,
+// which Trace logs alongside the sql. It does nothing unless sql.explain_queries is enabled
+// and the debug logs are written, because it doubles the number of the queries.
+func (log *logger) explainQuery(db *gorm.DB) {
+	if !log.config.SQL.ExplainQueries || db.Error != nil || !log.Zap.Desugar().Core().Enabled(zap.DebugLevel) {
+		return
+	}
+	stmt, ok := db.Statement.Context.Value(statementKey{}).(*statement)
+	if !ok || !isSelect(stmt.sql) {
+		return
+	}
+	plan, err := queryPlan(db.Statement.Context, db.Statement.ConnPool, stmt)
+	if err != nil {
+		plan = []string{fmt.Sprintf("failed to explain: %s", err)}
+	}
+	stmt.plan = plan
+}
+
+// queryPlan returns the rows of the plan, whose columns are joined by spaces.
+// SQLite uses EXPLAIN QUERY PLAN, which is readable unlike its EXPLAIN.
+func queryPlan(ctx context.Context, pool gorm.ConnPool, stmt *statement) ([]string, error) {
+	explain := "EXPLAIN "
+	if stmt.dialect == sqliteDialect {
+		explain = "EXPLAIN QUERY PLAN "
+	}
+	rows, err := pool.QueryContext(ctx, explain+stmt.sql, stmt.vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var plan []string
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		line := make([]string, 0, len(values))
+		for _, value := range values {
+			if value.Valid {
+				line = append(line, value.String)
+			}
+		}
+		plan = append(plan, strings.Join(line, " "))
+	}
+	return plan, rows.Err()
+}
+
+// logPlan logs the plan of the statement at the debug level when it has been explained.
+func logPlan(ctx context.Context, sugar *zap.SugaredLogger, sql string) {
+	if stmt, ok := ctx.Value(statementKey{}).(*statement); ok && stmt.plan != nil {
+		sugar.Debugw(explainMessage, "sql", sql, "plan", stmt.plan)
+	}
+}
+
+// isSelect reports whether the sql is a SELECT statement.
+func isSelect(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	return len(sql) >= 6 && strings.EqualFold(sql[:6], "SELECT")
+}
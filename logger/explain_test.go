@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+type explainTestRecord struct {
+	ID   uint
+	Name string
+}
+
+func TestExplainQueries_Select(t *testing.T) {
+	db, logs := prepareForExplainTest(t, true)
+
+	require.NoError(t, db.Create(&explainTestRecord{Name: "test"}).Error)
+	assert.Zero(t, logs.FilterMessage(explainMessage).Len())
+
+	var records []explainTestRecord
+	require.NoError(t, db.Where("name = ?", "test").Find(&records).Error)
+
+	entries := logs.FilterMessage(explainMessage).All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, zap.DebugLevel, entries[0].Level)
+		assert.Contains(t, fields["sql"], "WHERE name = 'test'")
+		assert.Contains(t, fields["plan"], "2 0 0 SCAN explain_test_records")
+	}
+}
+
+func TestExplainQueries_Disabled(t *testing.T) {
+	db, logs := prepareForExplainTest(t, false)
+
+	var records []explainTestRecord
+	require.NoError(t, db.Find(&records).Error)
+
+	assert.Zero(t, logs.FilterMessage(explainMessage).Len())
+}
+
+func TestIsSelect(t *testing.T) {
+	assert.True(t, isSelect(" select * from book"))
+	assert.False(t, isSelect("INSERT INTO book (title) VALUES (?)"))
+	assert.False(t, isSelect("SEL"))
+}
+
+func prepareForExplainTest(t *testing.T, explain bool) (*gorm.DB, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := &Config{}
+	cfg.SQL.ExplainQueries = explain
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"),
+		&gorm.Config{Logger: log, Plugins: map[string]gorm.Plugin{log.Name(): log}})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&explainTestRecord{}))
+	return db, logs
+}
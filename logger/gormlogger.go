@@ -2,9 +2,17 @@ package logger
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 	gormUtils "gorm.io/gorm/utils"
 )
@@ -18,43 +26,256 @@ const (
 	sqlFormat     = logTitle + "%s"
 	messageFormat = logTitle + "%s, %s"
 	errorFormat   = logTitle + "%s, %s, %s"
-	slowThreshold = 200
+	pluginName    = "logger"
+	callbackName  = "logger:statement"
+	nullValue     = "NULL"
+	omittedFormat = "%s /* %d values omitted */"
+	timeFormat    = "2006-01-02 15:04:05.000"
+	// defaultSlowThreshold is the elapsed time from which a sql is logged as slow when it isn't configured.
+	defaultSlowThreshold = 200 * time.Millisecond
+	// postgresDialect is the name of the dialector which uses the numbered placeholders such as $1.
+	postgresDialect = "postgres"
 )
 
+// statementKey is the context key of the statement which was executed.
+type statementKey struct{}
+
+// statement represents the sql and its values which were executed.
+type statement struct {
+	sql     string
+	vars    []interface{}
+	dialect string
+	// rows is the number of the rows which the statement returned or affected.
+	rows int64
+	// plan is the result of EXPLAIN, which is attached when sql.explain_queries is enabled.
+	plan []string
+}
+
 // LogMode The log level of gorm logger is overwrited by the log level of Zap logger.
 func (log *logger) LogMode(_ gormLogger.LogLevel) gormLogger.Interface {
 	return log
 }
 
 // Info prints a information log.
-func (log *logger) Info(_ context.Context, msg string, data ...interface{}) {
-	log.Zap.Infof(messageFormat, append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)...)
+func (log *logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	args := append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)
+	WithContextFields(ctx, log.Zap).Infof(messageFormat, args...)
 }
 
 // Warn prints a warning log.
-func (log *logger) Warn(_ context.Context, msg string, data ...interface{}) {
-	log.Zap.Warnf(messageFormat, append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)...)
+func (log *logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	args := append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)
+	WithContextFields(ctx, log.Zap).Warnf(messageFormat, args...)
 }
 
 // Error prints a error log.
-func (log *logger) Error(_ context.Context, msg string, data ...interface{}) {
-	log.Zap.Errorf(messageFormat, append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)...)
+func (log *logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	args := append([]interface{}{msg, gormUtils.FileWithLineNum()}, data...)
+	WithContextFields(ctx, log.Zap).Errorf(messageFormat, args...)
 }
 
 // Trace prints a trace log such as sql, source file and error.
-func (log *logger) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
+// The request ID of the context is added to the log, so the sqls can be found with the request.
+// The json logs have the statement, its values, its table and its operation as the separated sql field,
+// and the other encodings have the human-readable line which the values are embedded in.
+//
+// The elapsed time is measured by the real clock even when the clock is replaced, because gorm passes
+// the begin of the statement by time.Now.
+func (log *logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	elapsed := time.Since(begin)
+	countStatement(ctx, fc)
+	sugar := WithContextFields(ctx, log.GetZapLogger())
+	notFound := errors.Is(err, gorm.ErrRecordNotFound)
+	if sqlLoggingPaused(ctx) && (err == nil || notFound) && elapsed <= log.slowThreshold() {
+		return
+	}
 
 	switch {
-	case err != nil:
-		sql, _ := fc()
-		log.GetZapLogger().Errorf(errorFormat, gormUtils.FileWithLineNum(), err, sql)
-	case elapsed > slowThreshold*time.Millisecond && slowThreshold*time.Millisecond != 0:
-		sql, _ := fc()
-		slowLog := fmt.Sprintf("SLOW SQL >= %v", slowThreshold)
-		log.GetZapLogger().Warnf(errorFormat, gormUtils.FileWithLineNum(), slowLog, sql)
+	case err != nil && !notFound:
+		log.logSQLError(ctx, sugar, fc, err, elapsed)
+	case log.structuredSQL():
+		fields := log.newSQLFields(ctx, fc)
+		rows := zap.Int64("rows", statementRows(ctx, fc))
+		if elapsed > log.slowThreshold() {
+			slowLog := fmt.Sprintf("SLOW SQL >= %v", log.slowThreshold())
+			logStructuredSQL(sugar, zap.WarnLevel, logTitle+slowLog, fields, rows,
+				zap.Duration("elapsed", elapsed), zap.String("source", gormUtils.FileWithLineNum()))
+		} else {
+			logStructuredSQL(sugar, zap.DebugLevel, sqlMessage, fields, rows)
+		}
+		logPlan(ctx, sugar, fields.statement)
+	case elapsed > log.slowThreshold():
+		sql := log.explain(ctx, fc)
+		slowLog := fmt.Sprintf("SLOW SQL >= %v", log.slowThreshold())
+		sugar.Warnw(fmt.Sprintf(errorFormat, gormUtils.FileWithLineNum(), slowLog, sql), "rows", statementRows(ctx, fc))
+		logPlan(ctx, sugar, sql)
 	default:
+		sql := log.explain(ctx, fc)
+		sugar.Debugw(fmt.Sprintf(sqlFormat, sql), "rows", statementRows(ctx, fc))
+		logPlan(ctx, sugar, sql)
+	}
+	if notFound && !log.config.SQL.DisableNotFoundHints {
+		log.logNotFound(ctx, sugar, fc)
+	}
+}
+
+// slowThreshold returns the elapsed time from which a sql is logged as slow,
+// which is the one reloaded by setSlowThreshold when sql.slow_threshold has been changed.
+func (log *logger) slowThreshold() time.Duration {
+	if threshold := log.reloadedSlowThreshold.Load(); threshold != 0 {
+		return time.Duration(threshold)
+	}
+	return log.config.SQL.slowThreshold()
+}
+
+// setSlowThreshold applies the reloaded sql.slow_threshold. Zero is the default.
+func (log *logger) setSlowThreshold(threshold config.Duration) {
+	log.reloadedSlowThreshold.Store(int64((&SQLConfig{SlowThreshold: threshold}).slowThreshold()))
+}
+
+// Name returns the name of this logger as a gorm plugin.
+func (log *logger) Name() string {
+	return pluginName
+}
+
+// Initialize registers the callbacks which attach the executed statement to its context,
+// so that Trace can format the sql and its values by itself.
+func (log *logger) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().After("*").Register(callbackName, withStatement),
+		callback.Query().After("*").Register(callbackName, withStatement),
+		callback.Update().After("*").Register(callbackName, withStatement),
+		callback.Delete().After("*").Register(callbackName, withStatement),
+		callback.Row().After("*").Register(callbackName, withStatement),
+		callback.Raw().After("*").Register(callbackName, withStatement),
+		callback.Query().After(callbackName).Register(explainCallbackName, log.explainQuery),
+	)
+}
+
+// withStatement attaches the executed sql and its values to the context of the statement.
+func withStatement(db *gorm.DB) {
+	if db.Statement.SQL.Len() == 0 {
+		return
+	}
+	stmt := &statement{sql: db.Statement.SQL.String(), vars: db.Statement.Vars, dialect: db.Dialector.Name(),
+		rows: db.RowsAffected}
+	db.Statement.Context = context.WithValue(db.Statement.Context, statementKey{}, stmt)
+}
+
+// explain returns the sql which the values are embedded in. The values of the redacted columns are redacted.
+// It falls back to the sql explained by gorm when the statement isn't attached to the context.
+func (log *logger) explain(ctx context.Context, fc func() (string, int64)) string {
+	stmt, ok := ctx.Value(statementKey{}).(*statement)
+	if !ok {
 		sql, _ := fc()
-		log.GetZapLogger().Debugf(sqlFormat, sql)
+		return sql
+	}
+	values := getFormattedValues(stmt.vars, log.config.SQL.MaxFormattedValues, log.config.SQL.quoteStyle(stmt.dialect))
+	values = redactParams(stmt.sql, stmt.dialect, values, log.config.Redact.Keys)
+	return createSQL(stmt.sql, stmt.dialect, values, len(stmt.vars)-len(values), log.config.SQL.inListThreshold())
+}
+
+// createSQL embeds the formatted values in the placeholders of the sql.
+// The placeholders are "$1, $2, ..." for PostgreSQL and "?" for the other dialects.
+// The placeholders which have no value are left as they are, and the number of omitted values is noted.
+// When the number of the values doesn't match the placeholders, it is flagged rather than guessed.
+// The IN list which has more values than inListThreshold is shortened, such as IN (1, 2, 3, … +47 more).
+// A zero inListThreshold writes every value.
+func createSQL(sql string, dialect string, values []string, omitted int, inListThreshold int) string {
+	var builder strings.Builder
+	builder.Grow(len(sql))
+
+	placeholders := findPlaceholders(sql, dialect)
+	last := 0
+	for i := 0; i < len(placeholders); i++ {
+		p := placeholders[i]
+		builder.WriteString(sql[last:p.start])
+		if n := inListLength(sql, placeholders[i:]); inListThreshold > 0 && n > inListThreshold {
+			writeInList(&builder, sql, placeholders[i:i+n], values, inListThreshold)
+			last = placeholders[i+n-1].end
+			i += n - 1
+			continue
+		}
+		if p.index < len(values) {
+			builder.WriteString(values[p.index])
+		} else {
+			builder.WriteString(sql[p.start:p.end])
+		}
+		last = p.end
+	}
+	builder.WriteString(sql[last:])
+
+	result := builder.String()
+	if omitted > 0 {
+		result = fmt.Sprintf(omittedFormat, result, omitted)
+	}
+	if placeholderMismatch(placeholders, dialect, len(values)+omitted) {
+		result = fmt.Sprintf(mismatchFormat, result)
+	}
+	return result
+}
+
+// getFormattedValues returns the values formatted for the sql log, whose strings are quoted in the style.
+// When limit is positive, only the first limit values are formatted
+// so that logging a huge batch doesn't allocate a formatted copy of every value.
+func getFormattedValues(values []interface{}, limit int, style string) []string {
+	if limit > 0 && len(values) > limit {
+		values = values[:limit]
+	}
+	formattedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		formattedValues = append(formattedValues, formatValue(value, style))
+	}
+	return formattedValues
+}
+
+// formatValue returns the string representation of a value in the sql, whose strings are quoted in the style.
+func formatValue(value interface{}, style string) string {
+	switch v := value.(type) {
+	case nil:
+		return nullValue
+	case string:
+		return quoteAs(v, style)
+	case []byte:
+		if str := string(v); isPrintable(str) {
+			return quoteAs(str, style)
+		}
+		return quote("<binary>")
+	case time.Time:
+		return quote(v.Format(timeFormat))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nullValue
+		}
+		if inner, err := v.Value(); err == nil {
+			return formatValue(inner, style)
+		}
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nullValue
+		}
+		return formatValue(rv.Elem().Interface(), style)
+	}
+	return quoteAs(fmt.Sprint(value), style)
+}
+
+// quote encloses a string value in single quotes, doubling the single quotes in it.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// isPrintable judges whether a given string consists of printable characters only.
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,294 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+const largeBatchSize = 50000
+
+func TestGetFormattedValues_Limited(t *testing.T) {
+	values := createLargeValues()
+
+	result := getFormattedValues(values, 100, QuoteStandard)
+
+	assert.Len(t, result, 100)
+	assert.Equal(t, "'name0'", result[0])
+	assert.Equal(t, "'name99'", result[99])
+}
+
+func TestGetFormattedValues_Unlimited(t *testing.T) {
+	values := []interface{}{"test", 1, nil, []byte("abc"), true}
+
+	result := getFormattedValues(values, 0, QuoteStandard)
+
+	assert.Equal(t, []string{"'test'", "1", "NULL", "'abc'", "true"}, result)
+}
+
+func TestGetFormattedValues_BoundedAllocation(t *testing.T) {
+	values := createLargeValues()
+
+	limited := testing.AllocsPerRun(10, func() { _ = getFormattedValues(values, 100, QuoteStandard) })
+	unlimited := testing.AllocsPerRun(10, func() { _ = getFormattedValues(values, 0, QuoteStandard) })
+
+	assert.LessOrEqual(t, limited, float64(2*100+1))
+	assert.Less(t, limited*100, unlimited)
+}
+
+func TestCreateSQL_Omitted(t *testing.T) {
+	sql := "INSERT INTO category_master (name) VALUES (?),(?),(?)"
+
+	result := createSQL(sql, "sqlite", []string{"'a'", "'b'"}, 1, 0)
+
+	assert.Equal(t, "INSERT INTO category_master (name) VALUES ('a'),('b'),(?) /* 1 values omitted */", result)
+}
+
+func TestCreateSQL_NoOmitted(t *testing.T) {
+	sql := "SELECT * FROM category_master WHERE id = ? AND name = ?"
+
+	result := createSQL(sql, "sqlite", []string{"1", "'test'"}, 0, 0)
+
+	assert.Equal(t, "SELECT * FROM category_master WHERE id = 1 AND name = 'test'", result)
+}
+
+func TestCreateSQL_Postgres(t *testing.T) {
+	sql := `SELECT * FROM "category_master" WHERE name = $2 AND id = $1 OR id = $10`
+
+	result := createSQL(sql, "postgres", []string{"1", "'test'"}, 0, 0)
+
+	assert.Equal(t,
+		`SELECT * FROM "category_master" WHERE name = 'test' AND id = 1 OR id = $10 [param count mismatch]`, result)
+}
+
+func TestCreateSQL_Quoted(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		dialect string
+		want    string
+	}{
+		{name: "like", sql: "SELECT * FROM category_master WHERE name LIKE 'what?%' AND id = ? AND name = ?",
+			want: "SELECT * FROM category_master WHERE name LIKE 'what?%' AND id = 1 AND name = 'test'"},
+		{name: "escaped quote", sql: "SELECT * FROM category_master WHERE name = 'it''s?' AND id = ? AND name = ?",
+			want: "SELECT * FROM category_master WHERE name = 'it''s?' AND id = 1 AND name = 'test'"},
+		{name: "backslash", sql: `SELECT * FROM category_master WHERE name = 'it\'s?' AND id = ? AND name = ?`,
+			dialect: "mysql", want: `SELECT * FROM category_master WHERE name = 'it\'s?' AND id = 1 AND name = 'test'`},
+		{name: "identifier", sql: `SELECT "why?" FROM category_master WHERE id = ? AND name = ?`,
+			want: `SELECT "why?" FROM category_master WHERE id = 1 AND name = 'test'`},
+		{name: "backquote", sql: "SELECT `why?` FROM category_master WHERE id = ? AND name = ?",
+			dialect: "mysql", want: "SELECT `why?` FROM category_master WHERE id = 1 AND name = 'test'"},
+		{name: "line comment", sql: "SELECT * FROM category_master -- why?\nWHERE id = ? AND name = ?",
+			want: "SELECT * FROM category_master -- why?\nWHERE id = 1 AND name = 'test'"},
+		{name: "block comment", sql: "SELECT /* why? */ * FROM category_master WHERE id = ? AND name = ?",
+			want: "SELECT /* why? */ * FROM category_master WHERE id = 1 AND name = 'test'"},
+		{name: "postgres literal", sql: `SELECT * FROM "category_master" WHERE name LIKE '$1%' AND id = $1 AND name = $2`,
+			dialect: "postgres", want: `SELECT * FROM "category_master" WHERE name LIKE '$1%' AND id = 1 AND name = 'test'`},
+		{name: "postgres comment",
+			sql:     `SELECT /* $1 */ * FROM "category_master" -- $2` + "\n" + `WHERE id = $1 AND name = $2`,
+			dialect: "postgres",
+			want:    `SELECT /* $1 */ * FROM "category_master" -- $2` + "\n" + `WHERE id = 1 AND name = 'test'`},
+		{name: "postgres json operator", sql: `SELECT * FROM "category_master" WHERE data ? 'name' AND id = $1 AND name = $2`,
+			dialect: "postgres", want: `SELECT * FROM "category_master" WHERE data ? 'name' AND id = 1 AND name = 'test'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect := tt.dialect
+			if dialect == "" {
+				dialect = "sqlite"
+			}
+
+			assert.Equal(t, tt.want, createSQL(tt.sql, dialect, []string{"1", "'test'"}, 0, 0))
+		})
+	}
+}
+
+func TestCreateSQL_Mismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		dialect string
+		values  []string
+		omitted int
+		want    string
+	}{
+		{name: "too few values", sql: "SELECT * FROM category_master WHERE id = ? AND name = ?", dialect: "sqlite",
+			values: []string{"1"}, want: "SELECT * FROM category_master WHERE id = 1 AND name = ? [param count mismatch]"},
+		{name: "too many values", sql: "SELECT * FROM category_master WHERE id = ?", dialect: "sqlite",
+			values: []string{"1", "2"}, want: "SELECT * FROM category_master WHERE id = 1 [param count mismatch]"},
+		{name: "omitted", sql: "SELECT * FROM category_master WHERE id IN (?,?,?)", dialect: "sqlite",
+			values: []string{"1", "2"}, omitted: 2,
+			want: "SELECT * FROM category_master WHERE id IN (1,2,?) /* 2 values omitted */ [param count mismatch]"},
+		{name: "postgres", sql: `SELECT * FROM "category_master" WHERE id = $1 OR parent_id = $1`, dialect: "postgres",
+			values: []string{"1"}, want: `SELECT * FROM "category_master" WHERE id = 1 OR parent_id = 1`},
+		{name: "postgres too many values", sql: `SELECT * FROM "category_master" WHERE id = $1`, dialect: "postgres",
+			values: []string{"1", "2"}, want: `SELECT * FROM "category_master" WHERE id = 1 [param count mismatch]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, createSQL(tt.sql, tt.dialect, tt.values, tt.omitted, 0))
+		})
+	}
+}
+
+func TestCreateSQL_MySQL(t *testing.T) {
+	sql := "SELECT * FROM `category_master` WHERE id = ? AND name = ?"
+
+	result := createSQL(sql, "mysql", []string{"1", "'test'"}, 0, 0)
+
+	assert.Equal(t, "SELECT * FROM `category_master` WHERE id = 1 AND name = 'test'", result)
+}
+
+func TestTrace_SlowSQL(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	// The clock replaced by the tests doesn't affect the elapsed time, because gorm passes the real begin.
+	t.Cleanup(clock.Set(clock.NewFake(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)).Now))
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	log.Trace(context.Background(), time.Now(), sql, nil)
+	log.Trace(context.Background(), time.Now().Add(-2*defaultSlowThreshold), sql, nil)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, zap.DebugLevel, entries[0].Level)
+		assert.Equal(t, zap.WarnLevel, entries[1].Level)
+		assert.Contains(t, entries[1].Message, "SLOW SQL >= 200ms")
+	}
+}
+
+func TestTrace_ReloadedSlowThreshold(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar()).(*logger)
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	log.setSlowThreshold(config.Duration(time.Second))
+	log.Trace(context.Background(), time.Now().Add(-2*defaultSlowThreshold), sql, nil)
+	log.setSlowThreshold(0)
+	log.Trace(context.Background(), time.Now().Add(-2*defaultSlowThreshold), sql, nil)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, zap.DebugLevel, entries[0].Level)
+		assert.Equal(t, zap.WarnLevel, entries[1].Level)
+	}
+}
+
+func BenchmarkGetFormattedValues_Limited(b *testing.B) {
+	values := createLargeValues()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = getFormattedValues(values, 100, QuoteStandard)
+	}
+}
+
+func BenchmarkGetFormattedValues_Unlimited(b *testing.B) {
+	values := createLargeValues()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = getFormattedValues(values, 0, QuoteStandard)
+	}
+}
+
+func createLargeValues() []interface{} {
+	values := make([]interface{}, 0, largeBatchSize)
+	for i := 0; i < largeBatchSize; i++ {
+		values = append(values, "name"+strconv.Itoa(i))
+	}
+	return values
+}
+
+func TestTrace_NotFoundHintsDisabled(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := &Config{}
+	cfg.SQL.DisableNotFoundHints = true
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	ctx := context.WithValue(context.Background(), statementKey{}, &statement{
+		sql: "SELECT * FROM `book` WHERE id = ? LIMIT 1", vars: []interface{}{1}, dialect: "sqlite"})
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "", 0 }, gorm.ErrRecordNotFound)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "[gorm] SELECT * FROM `book` WHERE id = 1 LIMIT 1", entries[0].Message)
+		assert.Equal(t, int64(0), entries[0].ContextMap()["rows"])
+	}
+}
+
+func TestWhereClause(t *testing.T) {
+	assert.Equal(t, "id = 1", whereClause("SELECT * FROM `book` WHERE id = 1 ORDER BY `book`.`id` LIMIT 1"))
+	assert.Equal(t, "name = 'a' AND id > 2", whereClause("SELECT * FROM book WHERE name = 'a' AND id > 2"))
+	assert.Equal(t, "", whereClause("SELECT * FROM book"))
+}
+
+func TestCreateSQL_InList(t *testing.T) {
+	inList := func(n int, dialect string) (string, []string) {
+		placeholders := make([]string, n)
+		values := make([]string, n)
+		for i := range placeholders {
+			placeholders[i] = "?"
+			if dialect == postgresDialect {
+				placeholders[i] = fmt.Sprintf("$%d", i+2)
+			}
+			values[i] = fmt.Sprint(i + 1)
+		}
+		return strings.Join(placeholders, ","), values
+	}
+
+	three, values := inList(3, "sqlite")
+	assert.Equal(t, "SELECT * FROM `book` WHERE id IN (1,2,3) AND title = 'Go'",
+		createSQL("SELECT * FROM `book` WHERE id IN ("+three+") AND title = ?", "sqlite",
+			append(values, "'Go'"), 0, defaultInListThreshold))
+
+	fifty, values := inList(50, "sqlite")
+	assert.Equal(t, "SELECT * FROM `book` WHERE id IN (1, 2, 3, … +47 more) AND title = 'Go'",
+		createSQL("SELECT * FROM `book` WHERE id IN ("+fifty+") AND title = ?", "sqlite",
+			append(values, "'Go'"), 0, 3))
+	assert.Equal(t, "SELECT * FROM `book` WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10, … +40 more)",
+		createSQL("SELECT * FROM `book` WHERE id IN ("+fifty+")", "sqlite", values, 0, defaultInListThreshold))
+	assert.Contains(t, createSQL("SELECT * FROM `book` WHERE id IN ("+fifty+")", "sqlite", values, 0, 0),
+		"IN (1,2,3,4,5,6,7,8,9,10,11,")
+
+	fifty, values = inList(50, postgresDialect)
+	assert.Equal(t, `SELECT * FROM "book" WHERE title = 'Go' AND id IN (1, 2, 3, … +47 more)`,
+		createSQL(`SELECT * FROM "book" WHERE title = $1 AND id IN (`+fifty+`)`, postgresDialect,
+			append([]string{"'Go'"}, values...), 0, 3))
+
+	// gorm writes an empty list as (NULL), which is logged as it is executed.
+	assert.Equal(t, "SELECT * FROM `book` WHERE id IN (NULL) AND title = 'Go'",
+		createSQL("SELECT * FROM `book` WHERE id IN (NULL) AND title = ?", "sqlite", []string{"'Go'"}, 0, 3))
+	// the values which aren't a list, such as the ones of an INSERT, are never shortened.
+	assert.Equal(t, "INSERT INTO `book` (`a`,`b`,`c`,`d`) VALUES (1,2,3,4)",
+		createSQL("INSERT INTO `book` (`a`,`b`,`c`,`d`) VALUES (?,?,?,?)", "sqlite",
+			[]string{"1", "2", "3", "4"}, 0, 3))
+}
+
+func TestTrace_InListStructuredValues(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := createTestConfig()
+	cfg.SQL.Interpolated = true
+	cfg.SQL.InListThreshold = 3
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	ctx := context.WithValue(context.Background(), statementKey{}, &statement{
+		sql: "SELECT * FROM `book` WHERE id IN (?,?,?,?,?)", vars: []interface{}{1, 2, 3, 4, 5}, dialect: "sqlite"})
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "", 5 }, nil)
+
+	if assert.Equal(t, 1, logs.Len()) {
+		sql := logs.All()[0].ContextMap()["sql"].(map[string]interface{})
+		assert.Equal(t, "SELECT * FROM `book` WHERE id IN (1, 2, 3, … +2 more)", sql["interpolated"])
+		assert.Equal(t, []interface{}{"1", "2", "3", "4", "5"}, sql["values"])
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// errorOutputWriter is the writer of zap_config.errorOutputPaths,
+// which passes the internal errors of zap to the callbacks before writing them.
+type errorOutputWriter struct {
+	zapcore.WriteSyncer
+	diag *diagnostics
+}
+
+// Write calls the callbacks with the internal error, and writes it to the wrapped writer.
+func (w *errorOutputWriter) Write(p []byte) (int, error) {
+	w.diag.notifyInternalError(errors.New(strings.TrimSpace(string(p))))
+	return w.WriteSyncer.Write(p)
+}
+
+// watchErrorOutput wraps the writer of the internal errors of zap, such as the failures of writing the logs,
+// so the callbacks registered by onInternalError are called with them.
+func (d *diagnostics) watchErrorOutput(writer zapcore.WriteSyncer) zapcore.WriteSyncer {
+	return &errorOutputWriter{WriteSyncer: writer, diag: d}
+}
+
+// onInternalError registers the callback of the internal errors of zap.
+func (d *diagnostics) onInternalError(fn func(error)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.callbacks = append(d.callbacks, fn)
+}
+
+func (d *diagnostics) notifyInternalError(err error) {
+	d.mutex.Lock()
+	callbacks := d.callbacks
+	d.mutex.Unlock()
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
+
+// OnInternalError registers the callback which is called with the internal errors of the logger,
+// such as the failure of writing a log file, so the application can react to the failing logs,
+// for example by counting them in a metric. The errors are still written to zap_config.errorOutputPaths.
+// The callback must not log by this logger, and it is never called for the logger built by NewLogger.
+func (log *logger) OnInternalError(fn func(err error)) {
+	if log.diagnostics != nil {
+		log.diagnostics.onInternalError(fn)
+	}
+}
@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultJournalMaxSize is the size of a journal file from which the next file is started when it isn't configured.
+	defaultJournalMaxSize = 10 * megabyte
+	// journalHeaderSize is the size of the header of a record, which is the length and the checksum of the entry.
+	journalHeaderSize = 8
+	// journalAckSize is the size of the acknowledged position, which is the number of the file and the offset in it.
+	journalAckSize = 16
+	// journalAckSuffix is the suffix of the file which has the acknowledged position.
+	journalAckSuffix = ".ack"
+	// tornRecordMessage is the warning of the record which is broken, such as by a crash in the middle of its write.
+	tornRecordMessage = "Skipped the broken records of the audit journal"
+)
+
+// JournalConfig represents the setting for the write-ahead journal of the audit log.
+type JournalConfig struct {
+	// Path is the base path of the journal files, such as ./log/audit.journal, which are numbered as audit.journal.1.
+	// The journal is disabled when it is empty.
+	Path string `json:"path" yaml:"path"`
+	// MaxSize is the size of a journal file from which the next file is started, such as 10MB. It is 10MB by default.
+	MaxSize config.ByteSize `json:"max_size" yaml:"max_size" unit:"MiB"`
+}
+
+// journal is the writer of the audit log which appends every entry to the journal file and syncs it,
+// before it writes the entry to the destinations of the audit log. The position of the last entry delivered to them
+// is acknowledged, so the entries journaled but not delivered, such as by a crash, are re-emitted by replay.
+// The entries may be delivered twice, but never lost.
+type journal struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	sink    zapcore.WriteSyncer
+	// file is the journal file which the entries are appended to. It is nil until the first entry.
+	file *os.File
+	// seq is the number of the journal file, and size is its size.
+	seq  int
+	size int64
+	ack  *os.File
+	// pending is true while some entries before the acknowledged position haven't been delivered,
+	// such as the ones of the previous process or the ones whose delivery failed, so the position stays until replay.
+	pending bool
+}
+
+// openJournal opens the journal of the base path, whose entries are delivered to the sink.
+func openJournal(cfg *JournalConfig, sink zapcore.WriteSyncer) (*journal, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, err
+	}
+	ack, err := os.OpenFile(cfg.Path+journalAckSuffix, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	seqs, err := journalFiles(cfg.Path)
+	if err != nil {
+		_ = ack.Close()
+		return nil, err
+	}
+	j := &journal{path: cfg.Path, maxSize: int64(cfg.MaxSize), sink: sink, ack: ack, pending: len(seqs) > 0}
+	if j.maxSize <= 0 {
+		j.maxSize = int64(defaultJournalMaxSize)
+	}
+	if len(seqs) > 0 {
+		j.seq = seqs[len(seqs)-1]
+	}
+	return j, nil
+}
+
+// journalFiles returns the numbers of the journal files of the base path in ascending order.
+func journalFiles(path string) ([]int, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var seqs []int
+	for _, match := range matches {
+		if seq, err := strconv.Atoi(strings.TrimPrefix(match, path+".")); err == nil && seq > 0 {
+			seqs = append(seqs, seq)
+		}
+	}
+	slices.Sort(seqs)
+	return seqs, nil
+}
+
+func (j *journal) fileName(seq int) string {
+	return j.path + "." + strconv.Itoa(seq)
+}
+
+// Write journals the entry, and then delivers it to the sink.
+func (j *journal) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil || j.size >= j.maxSize {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	record := make([]byte, journalHeaderSize+len(p))
+	binary.BigEndian.PutUint32(record, uint32(len(p)))
+	binary.BigEndian.PutUint32(record[4:], crc32.ChecksumIEEE(p))
+	copy(record[journalHeaderSize:], p)
+	if _, err := j.file.Write(record); err != nil {
+		return 0, fmt.Errorf("failed to journal the audit entry: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to journal the audit entry: %w", err)
+	}
+	j.size += int64(len(record))
+
+	if _, err := j.sink.Write(p); err != nil {
+		j.pending = true
+		return 0, err
+	}
+	if j.pending {
+		return len(p), nil
+	}
+	return len(p), j.acknowledge(j.seq, j.size)
+}
+
+// Sync flushes the sink.
+func (j *journal) Sync() error {
+	return j.sink.Sync()
+}
+
+// rotate starts the next journal file. The current one is removed when all of its entries have been acknowledged.
+func (j *journal) rotate() error {
+	if j.file != nil {
+		err := j.file.Close()
+		if !j.pending {
+			err = errors.Join(err, os.Remove(j.file.Name()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(j.fileName(j.seq+1), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file, j.seq, j.size = file, j.seq+1, 0
+	return nil
+}
+
+// acknowledge records the position up to which the entries have been delivered.
+// It isn't synced, because an entry whose acknowledgement is lost is only delivered twice.
+func (j *journal) acknowledge(seq int, offset int64) error {
+	var position [journalAckSize]byte
+	binary.BigEndian.PutUint64(position[:], uint64(seq))
+	binary.BigEndian.PutUint64(position[8:], uint64(offset))
+	_, err := j.ack.WriteAt(position[:], 0)
+	return err
+}
+
+// acknowledged returns the position up to which the entries have been delivered.
+func (j *journal) acknowledged() (int, int64, error) {
+	var position [journalAckSize]byte
+	if _, err := j.ack.ReadAt(position[:], 0); errors.Is(err, io.EOF) {
+		return 0, 0, nil
+	} else if err != nil {
+		return 0, 0, err
+	}
+	return int(binary.BigEndian.Uint64(position[:])), int64(binary.BigEndian.Uint64(position[8:])), nil
+}
+
+// replay delivers the entries journaled after the acknowledged position again, and removes their journal files.
+// The following entries are journaled to a new file. A broken record, such as the one torn by a crash,
+// is skipped with the rest of its file, which is warned to warn. It returns the number of the delivered entries.
+func (j *journal) replay(warn *zap.SugaredLogger) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ackSeq, ackOffset, err := j.acknowledged()
+	if err != nil {
+		return 0, err
+	}
+	if err := j.rotate(); err != nil {
+		return 0, err
+	}
+	seqs, err := journalFiles(j.path)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	for _, seq := range seqs {
+		if seq >= j.seq {
+			break
+		}
+		var offset int64
+		switch {
+		case seq == ackSeq:
+			offset = ackOffset
+		case seq < ackSeq:
+			offset = -1
+		}
+		n, err := j.replayFile(j.fileName(seq), offset, warn)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+		if err := os.Remove(j.fileName(seq)); err != nil {
+			return replayed, err
+		}
+	}
+	j.pending = false
+	return replayed, errors.Join(j.sink.Sync(), j.acknowledge(j.seq, 0))
+}
+
+// replayFile delivers the entries of the journal file after the offset. A negative offset skips the whole file.
+func (j *journal) replayFile(name string, offset int64, warn *zap.SugaredLogger) (int, error) {
+	if offset < 0 {
+		return 0, nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	for offset < int64(len(data)) {
+		entry, ok := readRecord(data[offset:])
+		if !ok {
+			warn.Warnw(tornRecordMessage, "file", name, "offset", offset, "bytes", int64(len(data))-offset)
+			break
+		}
+		if _, err := j.sink.Write(entry); err != nil {
+			return replayed, err
+		}
+		replayed++
+		offset += int64(journalHeaderSize + len(entry))
+	}
+	return replayed, nil
+}
+
+// readRecord returns the entry of the record at the head of data. It returns false when the record is broken,
+// such as when it is shorter than its length or its checksum doesn't match.
+func readRecord(data []byte) ([]byte, bool) {
+	if len(data) < journalHeaderSize {
+		return nil, false
+	}
+	length := int64(binary.BigEndian.Uint32(data))
+	if int64(len(data)-journalHeaderSize) < length {
+		return nil, false
+	}
+	entry := data[journalHeaderSize : journalHeaderSize+length]
+	return entry, crc32.ChecksumIEEE(entry) == binary.BigEndian.Uint32(data[4:])
+}
+
+// close closes the journal files.
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	err := j.ack.Close()
+	if j.file != nil {
+		err = errors.Join(err, j.file.Close())
+	}
+	return err
+}
+
+// ReplayJournal delivers the audit entries which have been journaled but not delivered again,
+// such as the ones of the process which crashed, and returns their number. It is called by InitLogger and New
+// at startup, and it does nothing when the journal isn't enabled by audit.journal.path.
+func (log *logger) ReplayJournal() (int, error) {
+	if log.journal == nil {
+		return 0, nil
+	}
+	return log.journal.replay(log.Zap)
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// auditSink is the destination of the audit log which fails the writes after failAfter entries.
+type auditSink struct {
+	bytes.Buffer
+	failAfter int
+	written   int
+}
+
+func (s *auditSink) Write(p []byte) (int, error) {
+	if s.failAfter > 0 && s.written >= s.failAfter {
+		return 0, errors.New("the destination is down")
+	}
+	s.written++
+	return s.Buffer.Write(p)
+}
+
+func (s *auditSink) Sync() error {
+	return nil
+}
+
+// events returns the events of the audit entries written to the sink.
+func (s *auditSink) events() []string {
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(s.String()), "\n") {
+		if _, event, ok := strings.Cut(line, `"event":"`); ok {
+			events = append(events, event[:strings.Index(event, `"`)])
+		}
+	}
+	return events
+}
+
+func TestJournal_DeliversAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.journal")
+	sink := &auditSink{}
+	// every entry is larger than the journal file, so every entry starts a new file.
+	j, err := openJournal(&JournalConfig{Path: path, MaxSize: 1}, sink)
+	require.NoError(t, err)
+	audit := newJSONLogger(j, "event")
+
+	for _, event := range []string{"book.create", "book.update", "book.delete"} {
+		audit.Info(event)
+	}
+	require.NoError(t, j.close())
+
+	assert.Equal(t, []string{"book.create", "book.update", "book.delete"}, sink.events())
+	seqs, err := journalFiles(path)
+	require.NoError(t, err)
+	// the acknowledged files are removed when the next one is started.
+	assert.Equal(t, []int{3}, seqs)
+
+	j, err = openJournal(&JournalConfig{Path: path}, sink)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = j.close() })
+	replayed, err := j.replay(zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	assert.Zero(t, replayed)
+	seqs, _ = journalFiles(path)
+	assert.Equal(t, []int{4}, seqs)
+}
+
+func TestJournal_ReplaysAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.journal")
+	// the destination goes down after the first entry, and the process crashes while journaling the third one.
+	down := &auditSink{failAfter: 1}
+	j, err := openJournal(&JournalConfig{Path: path}, down)
+	require.NoError(t, err)
+	audit := newJSONLogger(j, "event")
+	for _, event := range []string{"book.create", "book.update", "book.delete"} {
+		audit.Info(event)
+	}
+	require.NoError(t, j.close())
+	info, err := os.Stat(path + ".1")
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path+".1", info.Size()-5))
+
+	core, logs := observer.New(zap.WarnLevel)
+	sink := &auditSink{}
+	j, err = openJournal(&JournalConfig{Path: path}, sink)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = j.close() })
+	replayed, err := j.replay(zap.New(core).Sugar())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, []string{"book.update"}, sink.events())
+	torn := logs.FilterMessage(tornRecordMessage).All()
+	if assert.Len(t, torn, 1) {
+		assert.Equal(t, path+".1", torn[0].ContextMap()["file"])
+	}
+	seqs, _ := journalFiles(path)
+	assert.Equal(t, []int{2}, seqs)
+
+	// the entries after the replay are journaled and acknowledged as usual.
+	newJSONLogger(j, "event").Info("book.create")
+	assert.Equal(t, []string{"book.update", "book.create"}, sink.events())
+	ackSeq, ackOffset, err := j.acknowledged()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ackSeq)
+	assert.Equal(t, j.size, ackOffset)
+}
+
+func TestJournal_ReplayedByNew(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, "app.log")}
+	cfg.Audit.OutputPaths = []string{filepath.Join(dir, "audit.log")}
+	cfg.Audit.Journal.Path = filepath.Join(dir, "audit.journal")
+	// the entry journaled by the previous process which crashed before delivering it.
+	j, err := openJournal(&cfg.Audit.Journal, &auditSink{failAfter: 1, written: 1})
+	require.NoError(t, err)
+	newJSONLogger(j, "event").Info("book.delete")
+	require.NoError(t, j.close())
+
+	log, err := New(cfg)
+	require.NoError(t, err)
+	log.Audit("book.create")
+	require.NoError(t, log.Close())
+
+	content, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+	assert.Less(t, strings.Index(string(content), "book.delete"), strings.Index(string(content), "book.create"))
+	logs, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(logs), `"replayed":1`)
+}
@@ -2,70 +2,400 @@ package logger
 
 import (
 	"context"
-	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ybkuroki/go-webapp-sample/config"
 	"go.uber.org/zap"
-	"gopkg.in/natefinch/lumberjack.v2"
-	"gopkg.in/yaml.v3"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 )
 
 // Config represents the setting for zap logger.
 type Config struct {
-	ZapConfig zap.Config        `json:"zap_config" yaml:"zap_config"`
-	LogRotate lumberjack.Logger `json:"log_rotate" yaml:"log_rotate"`
+	ZapConfig zap.Config      `json:"zap_config" yaml:"zap_config"`
+	LogRotate RotateConfig    `json:"log_rotate" yaml:"log_rotate"`
+	SQL       SQLConfig       `json:"sql" yaml:"sql"`
+	Stream    StreamConfig    `json:"stream" yaml:"stream"`
+	Audit     AuditConfig     `json:"audit" yaml:"audit"`
+	Metrics   MetricsConfig   `json:"metrics" yaml:"metrics"`
+	Redact    RedactConfig    `json:"redact" yaml:"redact"`
+	Sink      SinkConfig      `json:"sink" yaml:"sink"`
+	RequestID RequestIDConfig `json:"request_id" yaml:"request_id"`
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+	Preflight PreflightConfig `json:"preflight" yaml:"preflight"`
+	// RecentBuffer keeps the last logs in memory, which RecentEntries and RecentEntriesHandler return.
+	RecentBuffer RecentBufferConfig `json:"recent_buffer" yaml:"recent_buffer"`
+	// Schema is the name of the log schema, gcp or ecs, which names the fields such as the level and the message.
+	// It overrides the keys of zap_config.encoderConfig.
+	Schema string `json:"schema" yaml:"schema"`
+	// DurationEncoding is the unit of the durations in the logs, string (such as 1.2s), seconds, millis or nanos.
+	// It overrides zap_config.encoderConfig.durationEncoder and the schema.
+	DurationEncoding string `json:"duration_encoding" yaml:"duration_encoding"`
+	// IncludeHost adds the host field, the hostname, to every log, which distinguishes the hosts in a shared log store.
+	IncludeHost bool `json:"include_host" yaml:"include_host"`
+	// IncludePID adds the pid field, the process ID, to every log.
+	IncludePID bool `json:"include_pid" yaml:"include_pid"`
+	// CallerMinLevel is the lowest level of the logs which have the caller, such as warn, which saves looking up
+	// the callers of the frequent debug logs. Every log has the caller when it isn't given.
+	CallerMinLevel *zapcore.Level `json:"caller_min_level" yaml:"caller_min_level"`
+}
+
+// RotateConfig represents the setting for the rotation of the log files.
+// The bare numbers are read as before: maxsize in megabytes and maxage in days.
+type RotateConfig struct {
+	// MaxSize is the size of a log file which triggers the rotation, such as 100MB.
+	MaxSize config.ByteSize `json:"maxsize" yaml:"maxsize" unit:"MiB"`
+	// MaxAge is the period to keep the rotated files, such as 7d. Zero means they are kept forever.
+	MaxAge     config.Duration `json:"maxage" yaml:"maxage" unit:"d"`
+	MaxBackups int             `json:"maxbackups" yaml:"maxbackups"`
+	LocalTime  bool            `json:"localtime" yaml:"localtime"`
+	Compress   bool            `json:"compress" yaml:"compress"`
+}
+
+// SQLConfig represents the setting for sql logger.
+type SQLConfig struct {
+	// MaxFormattedValues is the maximum number of values formatted in a sql log. Zero means no limit.
+	MaxFormattedValues int `json:"max_formatted_values" yaml:"max_formatted_values"`
+	// SlowThreshold is the elapsed time from which a sql is logged as slow, such as 500ms. It is 200ms by default.
+	SlowThreshold config.Duration `json:"slow_threshold" yaml:"slow_threshold" unit:"ms"`
+	// ExplainQueries logs the plans of the SELECTs by Find, First, Count and so on at the debug level.
+	// It doubles the number of the queries, so it is off by default and can't be enabled in production.
+	ExplainQueries bool `json:"explain_queries" yaml:"explain_queries"`
+	// MaxStatementsPerRequest is the number of the sqls in a request from which it is warned as a likely N+1 query.
+	// Zero means no limit.
+	MaxStatementsPerRequest int `json:"max_statements_per_request" yaml:"max_statements_per_request"`
+	// QuoteStyle is how the strings are quoted in the logged sqls, standard, mysql or postgres.
+	// It is derived from the dialect by default, and the standard SQL is used for the other dialects.
+	QuoteStyle string `json:"quote_style" yaml:"quote_style"`
+	// DisableNotFoundHints stops the debug logs of the SELECTs which found no record for First, Take and Last,
+	// which are too many for the workloads whose reads are often empty.
+	DisableNotFoundHints bool `json:"disable_not_found_hints" yaml:"disable_not_found_hints"`
+	// InListThreshold is the number of the values of an IN list from which the rest are omitted in the logged sql,
+	// such as IN (1, 2, 3, … +47 more). It is 10 by default, and a negative value logs every value.
+	// The json logs have every value in the values of the sql field.
+	InListThreshold int `json:"in_list_threshold" yaml:"in_list_threshold"`
+	// Interpolated adds the sql which the values are embedded in to the json logs of the sqls.
+	// It is off by default, because it is expensive and it may have the sensitive values.
+	Interpolated bool `json:"interpolated" yaml:"interpolated"`
+}
+
+// slowThreshold returns the elapsed time from which a sql is logged as slow.
+func (c *SQLConfig) slowThreshold() time.Duration {
+	if c.SlowThreshold == 0 {
+		return defaultSlowThreshold
+	}
+	return c.SlowThreshold.Std()
+}
+
+// inListThreshold returns the number of the values of an IN list logged, which is zero for every value.
+func (c *SQLConfig) inListThreshold() int {
+	switch {
+	case c.InListThreshold < 0:
+		return 0
+	case c.InListThreshold == 0:
+		return defaultInListThreshold
+	}
+	return c.InListThreshold
+}
+
+// resolvePaths resolves the relative paths of the log files, including the keys of sink.outputs, by the given function.
+func (c *Config) resolvePaths(resolve func(string) string) {
+	for _, paths := range [][]string{c.ZapConfig.OutputPaths, c.ZapConfig.ErrorOutputPaths, c.Audit.OutputPaths,
+		c.Metrics.OutputPaths} {
+		for i, path := range paths {
+			paths[i] = resolve(path)
+		}
+	}
+	if c.Audit.Journal.Path != "" {
+		c.Audit.Journal.Path = resolve(c.Audit.Journal.Path)
+	}
+	if len(c.Sink.Outputs) > 0 {
+		outputs := make(map[string]SinkOutputConfig, len(c.Sink.Outputs))
+		for path, output := range c.Sink.Outputs {
+			outputs[resolve(path)] = output
+		}
+		c.Sink.Outputs = outputs
+	}
 }
 
 // Logger is an alternative implementation of *gorm.Logger
 type Logger interface {
 	GetZapLogger() *zap.SugaredLogger
+	GetEventStream() *EventStream
+	RecentEntries(filter RecentFilter) []Event
+	RecentBufferEnabled() bool
+	Audit(event string, fields ...zap.Field)
+	ReplayJournal() (int, error)
+	Metric(name string, value float64, tags map[string]string)
+	Access(fields AccessFields)
+	LogStartupInfo(info BuildInfo)
+	StartupInfo() BuildInfo
+	DebugLazy(msg string, fn func() []zap.Field)
+	LogStatementCount(ctx context.Context)
+	Diagnostics() DiagnosticsReport
+	GetDroppedWrites() uint64
+	OnInternalError(fn func(err error))
+	SafeGo(name string, fn func())
+	SetLevel(level zapcore.Level)
+	Sync() error
+	Close() error
 	LogMode(level gormLogger.LogLevel) gormLogger.Interface
 	Info(ctx context.Context, msg string, data ...interface{})
 	Warn(ctx context.Context, msg string, data ...interface{})
 	Error(ctx context.Context, msg string, data ...interface{})
 	Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error)
+	Print(values ...interface{})
+	Name() string
+	Initialize(db *gorm.DB) error
 }
 
 type logger struct {
-	Zap *zap.SugaredLogger
+	Zap    *zap.SugaredLogger
+	config *Config
+	stream *EventStream
+	// recent is the buffer of the recent logs. It is nil when it isn't enabled.
+	recent *recentBuffer
+	audit  *zap.Logger
+	// journal is the write-ahead journal of the audit log. It is nil when it isn't enabled.
+	journal *journal
+	// metrics is the logger of the metrics log. It is nil when the logger isn't built by InitLogger or New.
+	metrics *zap.Logger
+	// dropped is the number of the writes abandoned because the sink exceeded the deadline or its queue was full.
+	dropped *atomic.Uint64
+	// diagnostics is the state of the destinations. It is nil when the logger isn't built by InitLogger or New.
+	diagnostics *diagnostics
+	// unrecognized is the time of the last warning of the values of Print which can't be parsed, in unix nanoseconds.
+	unrecognized atomic.Int64
+	// startup is the build and the runtime logged at startup.
+	startup atomic.Pointer[BuildInfo]
+	// reloadedSlowThreshold is sql.slow_threshold reloaded from the configuration file, in nanoseconds.
+	// It is zero until it is reloaded.
+	reloadedSlowThreshold atomic.Int64
+	// closeAudit closes the files of the audit log.
+	closeAudit func()
+	// closeMetrics closes the files of the metrics log.
+	closeMetrics func()
+	// done stops the reports of the dropped writes and the flush of the suppressed logs when the logger is closed.
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 // NewLogger is constructor for logger
 func NewLogger(sugar *zap.SugaredLogger) Logger {
-	return &logger{Zap: sugar}
+	return NewLoggerWithConfig(sugar, &Config{})
+}
+
+// NewLoggerWithConfig is constructor for logger with the setting.
+func NewLoggerWithConfig(sugar *zap.SugaredLogger, cfg *Config) Logger {
+	return &logger{Zap: sugar, config: cfg, dropped: &atomic.Uint64{}}
 }
 
+var (
+	// initOnce makes InitLogger build the logger once.
+	initOnce sync.Once
+	// initialized is the logger built by InitLogger.
+	initialized Logger
+)
+
 // InitLogger create logger object for *gorm.DB from *echo.Logger
-func InitLogger(env string, yamlFile embed.FS) Logger {
-	configYaml, err := yamlFile.ReadFile(fmt.Sprintf(config.LoggerConfigPath, env))
+// The level of the logger is reloaded when zap_config.level is changed in the configuration file.
+// It builds the logger once. The later and the concurrent calls wait for it and return the same logger,
+// ignoring their arguments.
+func InitLogger(env string, configFile fs.FS) Logger {
+	initOnce.Do(func() {
+		initialized = initLogger(env, configFile)
+	})
+	return initialized
+}
+
+func initLogger(env string, configFile fs.FS) Logger {
+	myConfig, name, err := loadConfig(env, configFile)
 	if err != nil {
-		fmt.Printf("Failed to read logger configuration: %s", err)
+		fmt.Fprintf(os.Stderr, "Failed to read zap logger configuration: %s\n", err)
 		os.Exit(config.ErrExitStatus)
 	}
-	var myConfig *Config
-	if err = yaml.Unmarshal(configYaml, &myConfig); err != nil {
-		fmt.Printf("Failed to read zap logger configuration: %s", err)
-		os.Exit(config.ErrExitStatus)
-	}
-	var zap *zap.Logger
-	zap, err = build(myConfig)
+	config.AddReloadSource(config.DiskFile(name), myConfig, func() (interface{}, error) {
+		reloaded, _, err := loadConfig(env, configFile)
+		return reloaded, err
+	})
+	config.OnChange("zap_config.level", func(value interface{}) {
+		myConfig.ZapConfig.Level.SetLevel(value.(zap.AtomicLevel).Level())
+	})
+	setRequestIDFormat(myConfig.RequestID.Format)
+	log, err := newLogger(myConfig, name)
 	if err != nil {
 		fmt.Printf("Failed to compose zap logger : %s", err)
 		os.Exit(config.ErrExitStatus)
 	}
-	sugar := zap.Sugar()
-	// set package varriable logger.
-	log := NewLogger(sugar)
-	log.GetZapLogger().Infof("Success to read zap logger configuration: zaplogger." + env + ".yml")
-	_ = zap.Sync()
+	config.OnChange("sql.slow_threshold", func(value interface{}) {
+		log.setSlowThreshold(value.(config.Duration))
+	})
+	config.RejectChange("zap_config.sampling", "the sampler is built into the logger at startup")
+	log.logConfigFile(configFile, name)
+	_ = log.Sync()
 	return log
 }
 
+// New is constructor for the logger built from the setting.
+// The logger has its own destinations, level and audit log, so the loggers built by New don't affect each other.
+// Unlike InitLogger, it neither watches the configuration file nor changes the format of the request IDs.
+// Close it when it is no longer used, which stops its goroutines and closes its files.
+func New(cfg *Config) (Logger, error) {
+	log, err := newLogger(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func newLogger(cfg *Config, configFile string) (*logger, error) {
+	stream := newEventStream(&cfg.Stream)
+	dropped := &atomic.Uint64{}
+	diag := &diagnostics{configFile: configFile}
+	recent := newRecentBuffer(&cfg.RecentBuffer)
+	done := make(chan struct{})
+	zap, err := build(cfg, stream, recent, dropped, diag, done)
+	if err != nil {
+		close(done)
+		return nil, err
+	}
+	audit, journal, closeAudit, err := newAuditLogger(&cfg.Audit)
+	if err != nil {
+		close(done)
+		_ = diag.close()
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	metrics, closeMetrics, err := newMetricsLogger(&cfg.Metrics)
+	if err != nil {
+		closeAudit()
+		close(done)
+		_ = diag.close()
+		return nil, fmt.Errorf("failed to open metrics log: %w", err)
+	}
+	go reportDropped(zap, dropped, done)
+	log := &logger{Zap: zap.Sugar(), config: cfg, stream: stream, recent: recent, audit: audit, journal: journal,
+		metrics: metrics, dropped: dropped, diagnostics: diag, closeAudit: closeAudit, closeMetrics: closeMetrics,
+		done: done}
+	if replayed, err := log.ReplayJournal(); err != nil {
+		log.Zap.Warnw("Failed to replay the audit journal, its entries are kept for the next startup",
+			"replayed", replayed, "error", err)
+	} else if replayed > 0 {
+		log.Zap.Infow("Replayed the audit entries which hadn't been delivered", "replayed", replayed)
+	}
+	return log, nil
+}
+
+// logConfigFile logs the configuration file which the logger is read from, with the SHA-256 checksum of its content,
+// so the operators can confirm the deployed configuration by the logs.
+func (log *logger) logConfigFile(embedded fs.FS, name string) {
+	fields := []interface{}{"config_file", name}
+	if checksum, err := config.Checksum(embedded, name); err != nil {
+		log.Zap.Warnw("Failed to compute the checksum of the configuration file", "config_file", name, "error", err)
+	} else {
+		fields = append(fields, "config_checksum", checksum)
+	}
+	fields = append(fields, "log_files", log.diagnostics.probes)
+	log.Zap.Infow("Success to read zap logger configuration: "+name, fields...)
+}
+
+// loadConfig reads, overrides and validates the setting of the logger for the environment.
+// It returns an error when the environment is unknown, instead of reading the wrong file or none.
+func loadConfig(envName string, configFile fs.FS) (*Config, string, error) {
+	env, err := config.ParseEnv(envName)
+	if err != nil {
+		return nil, "", err
+	}
+	myConfig := &Config{}
+	name, err := config.ReadConfig(configFile, fmt.Sprintf(config.LoggerConfigName, env), myConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = config.OverrideWithEnv(myConfig); err != nil {
+		return nil, "", fmt.Errorf("failed to override %s: %w", name, err)
+	}
+	// the URIs and ~ are normalized first, or they are taken as relative to the configuration directory.
+	err = myConfig.normalizeOutputPaths()
+	myConfig.resolvePaths(config.ResolvePath)
+	err = errors.Join(err, myConfig.Validate())
+	if env == config.PRD && myConfig.SQL.ExplainQueries {
+		err = errors.Join(err, config.NewFieldError("sql.explain_queries", "must not be enabled in production"))
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s:\n%w", name, err)
+	}
+	return myConfig, name, nil
+}
+
 // GetZapLogger returns zapSugaredLogger
 func (log *logger) GetZapLogger() *zap.SugaredLogger {
 	return log.Zap
 }
+
+// GetDroppedWrites returns the number of the log writes abandoned because the sink exceeded the deadline
+// or its queue was full.
+func (log *logger) GetDroppedWrites() uint64 {
+	return log.dropped.Load()
+}
+
+// DebugLazy writes a debug log with the fields returned by fn.
+// fn is called only when the debug logs are written, so the expensive fields aren't built in production.
+func (log *logger) DebugLazy(msg string, fn func() []zap.Field) {
+	if ce := log.Zap.Desugar().Check(zap.DebugLevel, msg); ce != nil {
+		ce.Write(fn()...)
+	}
+}
+
+// SetLevel changes the level of the logger. The other loggers keep their levels.
+// It does nothing for the logger which isn't built from a setting, such as by NewLogger.
+func (log *logger) SetLevel(level zapcore.Level) {
+	if log.config.ZapConfig.Level == (zap.AtomicLevel{}) {
+		return
+	}
+	log.config.ZapConfig.Level.SetLevel(level)
+}
+
+// Sync flushes the logs, the audit log and the metrics log buffered by the logger.
+func (log *logger) Sync() error {
+	err := log.Zap.Sync()
+	if log.audit != nil {
+		err = errors.Join(err, log.audit.Sync())
+	}
+	if log.metrics != nil {
+		err = errors.Join(err, log.metrics.Sync())
+	}
+	return err
+}
+
+// Close flushes the logs, stops the reports of the dropped writes and the goroutines of the sinks,
+// and closes the log files of the logger. The logger must not be used after it is closed.
+func (log *logger) Close() error {
+	var err error
+	log.closeOnce.Do(func() {
+		err = log.Sync()
+		if log.done != nil {
+			close(log.done)
+		}
+		if log.closeAudit != nil {
+			log.closeAudit()
+		}
+		if log.closeMetrics != nil {
+			log.closeMetrics()
+		}
+		if log.diagnostics != nil {
+			err = errors.Join(err, log.diagnostics.close())
+		}
+	})
+	return err
+}
+
+// GetEventStream returns the stream of the log events. It returns nil when the stream isn't enabled.
+func (log *logger) GetEventStream() *EventStream {
+	return log.stream
+}
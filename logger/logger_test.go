@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestOverrideWithEnv_LoggerConfig(t *testing.T) {
+	t.Setenv("ZAP_CONFIG_LEVEL", "warn")
+	t.Setenv("LOG_ROTATE_MAXSIZE", "5")
+	cfg := createTestConfig()
+
+	assert.NoError(t, config.OverrideWithEnv(cfg))
+	assert.Equal(t, zapcore.WarnLevel, cfg.ZapConfig.Level.Level())
+	assert.Equal(t, config.ByteSize(5<<20), cfg.LogRotate.MaxSize)
+}
+
+func TestRotateConfig_LumberjackUnits(t *testing.T) {
+	cfg := RotateConfig{MaxSize: 100 * 1000 * 1000, MaxAge: config.Duration(36 * time.Hour)}
+
+	// lumberjack takes whole megabytes and days, so the fractions are rounded up.
+	assert.Equal(t, 96, cfg.maxSizeMegabytes())
+	assert.Equal(t, 2, cfg.maxAgeDays())
+}
+
+// createTestConfig returns the setting of the logger which writes nothing to the files.
+func createTestConfig() *Config {
+	return &Config{
+		ZapConfig: zap.Config{
+			Level:    zap.NewAtomicLevelAt(zapcore.DebugLevel),
+			Encoding: "json",
+			EncoderConfig: zapcore.EncoderConfig{
+				MessageKey:  "msg",
+				LevelKey:    "level",
+				EncodeLevel: zapcore.LowercaseLevelEncoder,
+			},
+		},
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.OutputPaths = []string{"stdout", "app.log"}
+	cfg.Audit.OutputPaths = []string{"audit.log"}
+	cfg.Sink.Outputs = map[string]SinkOutputConfig{"audit.log": {Backpressure: BackpressureBlock}}
+
+	cfg.resolvePaths(func(path string) string {
+		if path == "stdout" {
+			return path
+		}
+		return "/etc/app/" + path
+	})
+
+	assert.Equal(t, []string{"stdout", "/etc/app/app.log"}, cfg.ZapConfig.OutputPaths)
+	assert.Equal(t, []string{"/etc/app/audit.log"}, cfg.Audit.OutputPaths)
+	assert.Contains(t, cfg.Sink.Outputs, "/etc/app/audit.log")
+}
+
+func TestLoadConfig_UnknownEnv(t *testing.T) {
+	_, _, err := loadConfig("prod", os.DirFS("testdata/yml"))
+
+	assert.EqualError(t, err, "unknown environment: prod, it must be one of [develop test docker k8s production]")
+}
+
+func TestLoadConfig_KnownEnv(t *testing.T) {
+	data, err := os.ReadFile("testdata/yml/zaplogger.test.yml")
+	require.NoError(t, err)
+	t.Setenv(config.ConfigSourceVariable, config.SourceEmbedded)
+	embedded := fstest.MapFS{"config/zaplogger.test.yml": {Data: data}}
+
+	cfg, name, err := loadConfig(config.TST, embedded)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "embedded zaplogger.test.yml", name)
+	assert.NotNil(t, cfg)
+}
+
+func TestDebugLazy_Disabled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.DebugLazy("expensive", func() []zap.Field {
+		t.Fatal("the fields must not be built when the level is info")
+		return nil
+	})
+
+	assert.Zero(t, logs.Len())
+}
+
+func TestDebugLazy_Enabled(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.DebugLazy("expensive", func() []zap.Field { return []zap.Field{zap.Int("size", 3)} })
+
+	if assert.Equal(t, 1, logs.Len()) {
+		assert.Equal(t, map[string]interface{}{"size": int64(3)}, logs.All()[0].ContextMap())
+	}
+}
+
+func TestNew_Isolated(t *testing.T) {
+	dir := t.TempDir()
+	newLogger := func(name string, level zapcore.Level) (Logger, string) {
+		cfg := createTestConfig()
+		cfg.ZapConfig.Level = zap.NewAtomicLevelAt(level)
+		cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, name)}
+		cfg.LogRotate.MaxSize = megabyte
+		log, err := New(cfg)
+		require.NoError(t, err)
+		return log, cfg.ZapConfig.OutputPaths[0]
+	}
+	first, firstPath := newLogger("first.log", zapcore.InfoLevel)
+	second, secondPath := newLogger("second.log", zapcore.DebugLevel)
+
+	first.GetZapLogger().Debug("first debug")
+	first.GetZapLogger().Info("first info")
+	second.GetZapLogger().Debug("second debug")
+	second.SetLevel(zapcore.WarnLevel)
+	second.GetZapLogger().Info("second info")
+	first.GetZapLogger().Info("first info after")
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Close())
+
+	firstLog, err := os.ReadFile(firstPath)
+	require.NoError(t, err)
+	secondLog, err := os.ReadFile(secondPath)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"level\":\"info\",\"msg\":\"first info\"}\n{\"level\":\"info\",\"msg\":\"first info after\"}\n",
+		string(firstLog))
+	assert.Equal(t, "{\"level\":\"debug\",\"msg\":\"second debug\"}\n", string(secondLog))
+}
+
+func TestClose_StopsSinkGoroutines(t *testing.T) {
+	dir := t.TempDir()
+	newSinkConfig := func() *Config {
+		cfg := createTestConfig()
+		cfg.LogRotate.MaxSize = megabyte
+		cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, "app.log"), filepath.Join(dir, "sql.log")}
+		cfg.Sink = SinkConfig{WriteTimeout: config.Duration(time.Second), Backpressure: BackpressureDropNewest}
+		return cfg
+	}
+	// the other tests may leave the goroutines of their writers.
+	before := sinkGoroutines()
+
+	log, err := New(newSinkConfig())
+	require.NoError(t, err)
+	log.GetZapLogger().Info("test")
+	require.NoError(t, log.Close())
+	// the goroutines are stopped on the error after the files are opened too.
+	cfg := newSinkConfig()
+	cfg.Audit.OutputPaths = []string{filepath.Join(dir, "missing", "audit.log")}
+	_, err = New(cfg)
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool { return sinkGoroutines() <= before }, time.Second, 10*time.Millisecond)
+	content, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"level\":\"info\",\"msg\":\"test\"}\n", string(content))
+}
+
+// sinkGoroutines returns the number of the goroutines of the queues and the deadlines of the sinks.
+// lumberjack has its own goroutine which isn't stopped by closing the file, so the goroutines aren't just counted.
+func sinkGoroutines() int {
+	buf := make([]byte, 1<<20)
+	stacks := string(buf[:runtime.Stack(buf, true)])
+	return strings.Count(stacks, "(*queueWriter).run") + strings.Count(stacks, "(*timeoutWriter).run")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	log, err := New(&Config{})
+
+	assert.Error(t, err)
+	assert.Nil(t, log)
+}
+
+func TestInitLogger_Concurrent(t *testing.T) {
+	data, err := os.ReadFile("testdata/yml/zaplogger.test.yml")
+	require.NoError(t, err)
+	t.Setenv(config.ConfigSourceVariable, config.SourceEmbedded)
+	embedded := fstest.MapFS{"config/zaplogger.test.yml": {Data: data}}
+
+	const callers = 10
+	loggers := make([]Logger, callers)
+	var wg sync.WaitGroup
+	for i := range loggers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loggers[i] = InitLogger(config.TST, embedded)
+		}()
+	}
+	wg.Wait()
+
+	require.NotNil(t, loggers[0])
+	for _, log := range loggers {
+		assert.Same(t, loggers[0], log)
+	}
+	assert.Same(t, loggers[0], InitLogger(config.TST, embedded))
+}
+
+func TestLogConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zaplogger.develop.yml")
+	require.NoError(t, os.WriteFile(path, []byte("zap_config:\n  level: debug\n"), 0o600))
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := &logger{Zap: zap.New(core).Sugar(), diagnostics: &diagnostics{}}
+
+	log.logConfigFile(fstest.MapFS{}, path)
+
+	entries := logs.FilterMessage("Success to read zap logger configuration: " + path).All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, path, fields["config_file"])
+		assert.Regexp(t, "^[0-9a-f]{64}$", fields["config_checksum"])
+	}
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MetricsConfig represents the setting for the metrics log,
+// which is a lightweight alternative to a metrics backend for the environments without it.
+type MetricsConfig struct {
+	// OutputPaths is the list of the destinations of the metrics log. The metrics are discarded when it is empty.
+	OutputPaths []string `json:"output_paths" yaml:"output_paths"`
+}
+
+// newMetricsLogger creates the logger for the metrics log, and returns the function which closes its files.
+// Like the audit log, it is never sampled and never rotated, regardless of the level of the main log.
+func newMetricsLogger(cfg *MetricsConfig) (*zap.Logger, func(), error) {
+	return newJSONSinkLogger(cfg.OutputPaths, "metric")
+}
+
+// metricTags are the tags of a metric, which are written as an object whose keys are sorted.
+type metricTags map[string]string
+
+// MarshalLogObject adds the tags in order of the keys, so the same tags are always written in the same order.
+func (t metricTags) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		enc.AddString(key, t[key])
+	}
+	return nil
+}
+
+// Metric writes a metric to the metrics log as a json line, such as
+// {"time":"2024-04-01T09:30:00.000Z","metric":"books.created","value":1,"tags":{"category":"Novel"}},
+// so a batch job can aggregate them without parsing the other logs. The tags are always written, even when empty.
+func (log *logger) Metric(name string, value float64, tags map[string]string) {
+	if log.metrics == nil {
+		return
+	}
+	log.metrics.Info(name, zap.Float64("value", value), zap.Object("tags", metricTags(tags)))
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+)
+
+func TestMetric_WritesToMetricsSink(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, "app.log")}
+	cfg.Metrics.OutputPaths = []string{filepath.Join(dir, "metrics.log")}
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(clock.Set(clock.NewFake(time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)).Now))
+
+	log.Metric("books.created", 1, map[string]string{"format": "paper", "category": "Novel"})
+	log.Metric("queue.depth", 2.5, nil)
+	log.GetZapLogger().Info("not a metric")
+	require.NoError(t, log.Close())
+
+	metrics, err := os.ReadFile(filepath.Join(dir, "metrics.log"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"time":"2024-04-01T09:30:00.000Z","metric":"books.created","value":1,"tags":{"category":"Novel","format":"paper"}}`,
+		`{"time":"2024-04-01T09:30:00.000Z","metric":"queue.depth","value":2.5,"tags":{}}`,
+	}, strings.Split(strings.TrimSpace(string(metrics)), "\n"))
+	logs, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(logs), "not a metric")
+	assert.NotContains(t, string(logs), "books.created")
+}
+
+func TestMetric_Disabled(t *testing.T) {
+	log := NewLogger(zap.NewNop().Sugar())
+
+	assert.NotPanics(t, func() { log.Metric("books.created", 1, nil) })
+}
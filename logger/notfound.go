@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// notFoundFormat is the message of the hint of the SELECT which found no record, such as record not found for book.
+const notFoundFormat = logTitle + "record not found for %s"
+
+// wherePattern matches the WHERE clause of the statement up to the clauses which follow it.
+var wherePattern = regexp.MustCompile(`(?is)\bWHERE\b\s*(.*?)\s*(?:\bGROUP\s+BY\b|\bORDER\s+BY\b|\bLIMIT\b|\bFOR\b|$)`)
+
+// statementRows returns the number of the rows which the statement returned or affected.
+func statementRows(ctx context.Context, fc func() (string, int64)) int64 {
+	if stmt, ok := ctx.Value(statementKey{}).(*statement); ok {
+		return stmt.rows
+	}
+	_, rows := fc()
+	return rows
+}
+
+// logNotFound logs the hint of the SELECT which found no record for First, Take and Last at the debug level,
+// with its WHERE clause which the values are embedded in, so an empty result can be told from a failure.
+func (log *logger) logNotFound(ctx context.Context, sugar *zap.SugaredLogger, fc func() (string, int64)) {
+	if !sugar.Desugar().Core().Enabled(zap.DebugLevel) {
+		return
+	}
+	sql := log.explain(ctx, fc)
+	sugar.Debugw(fmt.Sprintf(notFoundFormat, tableOf(sql)), "where", whereClause(sql))
+}
+
+// whereClause returns the conditions of the WHERE clause of the sql, and the empty string when it has none.
+func whereClause(sql string) string {
+	match := wherePattern.FindStringSubmatch(sql)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
+)
+
+// fileScheme is the scheme of the URIs of the log files, such as file:///var/log/app.log.
+const fileScheme = "file"
+
+// normalizeOutputPath returns the file path of the destination of the logs written in the setting,
+// which may be a file URI such as file:///var/log/app.log, or a path starting with ~, the home directory.
+// stdout and stderr are returned as they are. It returns an error for the other schemes and the directories.
+func normalizeOutputPath(path string) (string, error) {
+	if path == SinkTypeStdout || path == SinkTypeStderr || path == "" {
+		return path, nil
+	}
+	if scheme, _, ok := strings.Cut(path, ":"); ok && len(scheme) > 1 && !strings.ContainsAny(scheme, `/\`) {
+		u, err := url.Parse(path)
+		if err != nil {
+			return "", fmt.Errorf("malformed URI: %w", err)
+		}
+		if u.Scheme != fileScheme {
+			return "", fmt.Errorf("unsupported scheme %s, it must be a file path or a file URI", u.Scheme)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return "", fmt.Errorf("the host %s of a file URI must be empty or localhost", u.Host)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return "", errors.New("a file URI must not have the query or the fragment")
+		}
+		path = u.Path
+		if u.Opaque != "" {
+			// the relative file URI, such as file:log/app.log.
+			path = u.Opaque
+		}
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if path == "" || strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return "", errors.New("it is a directory, not a file")
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "", errors.New("it is a directory, not a file")
+	}
+	return filepath.Clean(path), nil
+}
+
+// normalizeOutputPaths normalizes the destinations of the logs, the audit log and the metrics by normalizeOutputPath.
+// It returns every malformed path.
+func (c *Config) normalizeOutputPaths() error {
+	var errs []error
+	for _, list := range []struct {
+		name  string
+		paths []string
+	}{
+		{"zap_config.outputPaths", c.ZapConfig.OutputPaths},
+		{"zap_config.errorOutputPaths", c.ZapConfig.ErrorOutputPaths},
+		{"audit.output_paths", c.Audit.OutputPaths},
+		{"metrics.output_paths", c.Metrics.OutputPaths},
+	} {
+		for i, path := range list.paths {
+			normalized, err := normalizeOutputPath(path)
+			if err != nil {
+				errs = append(errs, config.NewFieldError(list.name, fmt.Sprintf("%s is invalid: %s", path, err)))
+				continue
+			}
+			list.paths[i] = normalized
+		}
+	}
+	return errors.Join(append(errs, c.normalizeSinkOutputs())...)
+}
+
+// normalizeSinkOutputs normalizes the paths of sink.outputs, and returns an error for the path
+// which isn't any destination, which is likely a typo.
+func (c *Config) normalizeSinkOutputs() error {
+	if len(c.Sink.Outputs) == 0 {
+		return nil
+	}
+	var errs []error
+	outputs := make(map[string]SinkOutputConfig, len(c.Sink.Outputs))
+	for path, output := range c.Sink.Outputs {
+		normalized, err := normalizeOutputPath(path)
+		if err != nil {
+			errs = append(errs, config.NewFieldError("sink.outputs", fmt.Sprintf("%s is invalid: %s", path, err)))
+			continue
+		}
+		if !slices.ContainsFunc([][]string{c.ZapConfig.OutputPaths, c.ZapConfig.ErrorOutputPaths,
+			c.Audit.OutputPaths, c.Metrics.OutputPaths}, func(paths []string) bool {
+			return slices.Contains(paths, normalized)
+		}) {
+			errs = append(errs, config.NewFieldError("sink.outputs", path+" isn't any of the output paths"))
+			continue
+		}
+		outputs[normalized] = output
+	}
+	c.Sink.Outputs = outputs
+	return errors.Join(errs...)
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOutputPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+
+	for path, expected := range map[string]string{
+		"stdout":                           "stdout",
+		"stderr":                           "stderr",
+		"log/app.log":                      "log/app.log",
+		"./log/../app.log":                 "app.log",
+		"/var/log/app.log":                 "/var/log/app.log",
+		"file:///var/log/app.log":          "/var/log/app.log",
+		"file://localhost/var/log/app.log": "/var/log/app.log",
+		"file:///var/log/my%20app.log":     "/var/log/my app.log",
+		"file:log/app.log":                 "log/app.log",
+		"~/app.log":                        filepath.Join(home, "app.log"),
+	} {
+		normalized, err := normalizeOutputPath(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, normalized, path)
+		}
+	}
+
+	for path, message := range map[string]string{
+		"http://example.com/app.log":     "unsupported scheme http, it must be a file path or a file URI",
+		"file://example.com/app.log":     "the host example.com of a file URI must be empty or localhost",
+		"file:///var/log/app.log?keep=1": "a file URI must not have the query or the fragment",
+		"file://%zz/app.log":             "malformed URI",
+		"log/":                           "it is a directory, not a file",
+		"file:///var/log/":               "it is a directory, not a file",
+		dir:                              "it is a directory, not a file",
+	} {
+		_, err := normalizeOutputPath(path)
+		assert.ErrorContains(t, err, message, path)
+	}
+}
+
+func TestNew_OutputPathURI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{"file://" + path}
+
+	log, err := New(cfg)
+	require.NoError(t, err)
+	log.GetZapLogger().Info("written by the URI")
+	require.NoError(t, log.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "written by the URI")
+	assert.Equal(t, []string{path}, cfg.ZapConfig.OutputPaths)
+}
+
+func TestNew_InvalidOutputPath(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{"stdout", t.TempDir(), "s3://bucket/app.log"}
+
+	_, err := New(cfg)
+
+	assert.ErrorContains(t, err, "zap_config.outputPaths: "+cfg.ZapConfig.OutputPaths[1]+
+		" is invalid: it is a directory, not a file")
+	assert.ErrorContains(t, err, "zap_config.outputPaths: s3://bucket/app.log is invalid: unsupported scheme s3")
+}
+
+func TestNew_SinkOutputs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(dir, "app.log")}
+	cfg.Sink.Outputs = map[string]SinkOutputConfig{
+		"file://" + filepath.Join(dir, "app.log"): {Backpressure: BackpressureDropNewest},
+		filepath.Join(dir, "typo.log"):            {Backpressure: BackpressureDropNewest},
+	}
+
+	_, err := New(cfg)
+
+	assert.ErrorContains(t, err, "sink.outputs: "+filepath.Join(dir, "typo.log")+" isn't any of the output paths")
+	assert.Equal(t, map[string]SinkOutputConfig{filepath.Join(dir, "app.log"): {Backpressure: BackpressureDropNewest}},
+		cfg.Sink.Outputs)
+}
@@ -0,0 +1,22 @@
+package logger
+
+import "context"
+
+// sqlLoggingPausedKey is the context key of the pause of the sql logs.
+type sqlLoggingPausedKey struct{}
+
+// WithSQLLoggingPaused returns the context which stops the logs of the successful sqls executed with it,
+// such as around the loop of a backfill, without changing the setting. The errors and the slow sqls are still logged.
+// The sqls of the other contexts, such as the ones of the concurrent requests, are logged as usual.
+func WithSQLLoggingPaused(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sqlLoggingPausedKey{}, true)
+}
+
+// sqlLoggingPaused returns true when the context has been paused by WithSQLLoggingPaused.
+func sqlLoggingPaused(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	paused, _ := ctx.Value(sqlLoggingPausedKey{}).(bool)
+	return paused
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithSQLLoggingPaused(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	trace := func(ctx context.Context, sql string, err error) {
+		log.Trace(ctx, time.Now(), func() (string, int64) { return sql, 1 }, err)
+	}
+	ctx := context.Background()
+	paused := WithSQLLoggingPaused(ctx)
+
+	trace(ctx, "SELECT 1", nil)
+	trace(paused, "SELECT 2", nil)
+	trace(WithRequestID(paused, "abc"), "SELECT 3", nil)
+	trace(ctx, "SELECT 4", nil)
+	trace(paused, "SELECT 5", errors.New("broken"))
+	log.Trace(paused, time.Now().Add(-2*defaultSlowThreshold), func() (string, int64) { return "SELECT 6", 1 }, nil)
+
+	var sqls []string
+	for _, entry := range logs.All() {
+		sqls = append(sqls, entry.Message)
+	}
+	if assert.Len(t, sqls, 4) {
+		assert.Contains(t, sqls[0], "SELECT 1")
+		assert.Contains(t, sqls[1], "SELECT 4")
+		assert.Equal(t, sqlErrorMessage, sqls[2])
+		assert.Contains(t, sqls[3], "SLOW SQL")
+	}
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// mysqlDialect is the name of the dialector whose string literals escape the quote with a backslash.
+	mysqlDialect = "mysql"
+	// mismatchFormat notes that the number of the values doesn't match the placeholders of the sql.
+	mismatchFormat = "%s [param count mismatch]"
+	// inListMoreFormat is the end of the IN list whose rest of the values are omitted, such as ", … +47 more".
+	inListMoreFormat = ", … +%d more"
+	// defaultInListThreshold is the number of the values of an IN list logged when it isn't configured.
+	defaultInListThreshold = 10
+)
+
+// placeholder is the location of a placeholder in the sql.
+type placeholder struct {
+	start, end int
+	// index is the index of the value of the placeholder.
+	index int
+}
+
+// findPlaceholders returns the placeholders of the sql, "$1, $2, ..." for PostgreSQL and "?" for the other dialects.
+// The question marks and the dollar signs in the string literals, the quoted identifiers and the comments
+// aren't placeholders, such as name LIKE 'what?%' or -- why?.
+func findPlaceholders(sql string, dialect string) []placeholder {
+	numbered := dialect == postgresDialect
+	var placeholders []placeholder
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			i = skipQuoted(sql, i, dialect == mysqlDialect)
+		case c == '"' || c == '`':
+			i = skipQuoted(sql, i, false)
+		case strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '?' && !numbered:
+			placeholders = append(placeholders, placeholder{start: i, end: i + 1, index: len(placeholders)})
+		case c == '$' && numbered:
+			end := i + 1
+			for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+				end++
+			}
+			if n, err := strconv.Atoi(sql[i+1 : end]); err == nil && n > 0 {
+				placeholders = append(placeholders, placeholder{start: i, end: end, index: n - 1})
+				i = end - 1
+			}
+		}
+	}
+	return placeholders
+}
+
+// skipQuoted returns the index of the quote which closes the quoted text starting at start.
+// The doubled quote is an escaped quote, and so is the quote after a backslash when backslash is true.
+func skipQuoted(sql string, start int, backslash bool) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch {
+		case backslash && sql[i] == '\\':
+			i++
+		case sql[i] == quote && i+1 < len(sql) && sql[i+1] == quote:
+			i++
+		case sql[i] == quote:
+			return i
+		}
+	}
+	return len(sql)
+}
+
+// placeholderMismatch reports whether the placeholders don't have the given number of values just enough.
+// The numbered placeholders may refer to a value more than once, so the highest number is compared with it.
+func placeholderMismatch(placeholders []placeholder, dialect string, values int) bool {
+	if dialect != postgresDialect {
+		return len(placeholders) != values
+	}
+	highest := 0
+	for _, p := range placeholders {
+		highest = max(highest, p.index+1)
+	}
+	return highest != values
+}
+
+// inListLength returns the number of the placeholders of the IN list starting at the head of the placeholders,
+// such as IN (?,?,?) which gorm expands a slice into. It returns 0 when they aren't the start of an IN list.
+func inListLength(sql string, placeholders []placeholder) int {
+	before := strings.TrimRightFunc(sql[:placeholders[0].start], unicode.IsSpace)
+	if !strings.HasSuffix(before, "(") {
+		return 0
+	}
+	before = strings.TrimRightFunc(strings.TrimSuffix(before, "("), unicode.IsSpace)
+	if len(before) < 2 || !strings.EqualFold(before[len(before)-2:], "IN") ||
+		(len(before) > 2 && !unicode.IsSpace(rune(before[len(before)-3]))) {
+		return 0
+	}
+	n := 1
+	for ; n < len(placeholders); n++ {
+		if strings.TrimSpace(sql[placeholders[n-1].end:placeholders[n].start]) != "," {
+			break
+		}
+	}
+	if !strings.HasPrefix(strings.TrimLeftFunc(sql[placeholders[n-1].end:], unicode.IsSpace), ")") {
+		return 0
+	}
+	return n
+}
+
+// writeInList writes the first threshold values of the IN list and the number of the rest,
+// such as 1, 2, 3, … +47 more. The placeholders which have no value are written as they are.
+func writeInList(builder *strings.Builder, sql string, placeholders []placeholder, values []string, threshold int) {
+	for i, p := range placeholders[:threshold] {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		if p.index < len(values) {
+			builder.WriteString(values[p.index])
+		} else {
+			builder.WriteString(sql[p.start:p.end])
+		}
+	}
+	fmt.Fprintf(builder, inListMoreFormat, len(placeholders)-threshold)
+}
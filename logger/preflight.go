@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// fallbackWarning explains the log file which can't be written and is replaced by stderr.
+const fallbackWarning = "%s can't be written, so its logs go to stderr instead: %s"
+
+// PreflightConfig represents the setting for the check of the log files at startup.
+type PreflightConfig struct {
+	// CreateDirs creates the missing directories of the log files. Otherwise a missing directory fails the check.
+	CreateDirs bool `json:"create_dirs" yaml:"create_dirs"`
+	// Strict fails the startup when a log file can't be written.
+	// Otherwise the logs of the file go to stderr with a warning.
+	Strict bool `json:"strict" yaml:"strict"`
+}
+
+// ProbeResult is the result of the check of a log file at startup.
+type ProbeResult struct {
+	Path  string `json:"path"`
+	Error string `json:"error,omitempty"`
+}
+
+// String returns the path and ok, or the path and the error.
+func (r ProbeResult) String() string {
+	if r.Error == "" {
+		return r.Path + ": ok"
+	}
+	return r.Path + ": " + r.Error
+}
+
+// preflight checks that the files of zap_config.outputPaths and zap_config.errorOutputPaths can be written,
+// by opening and closing a probe file in their directories, instead of failing silently at the first log.
+// The files which fail are replaced by stderr in the returned setting, or it returns an error when strict.
+func preflight(cfg *Config) (*Config, []ProbeResult, error) {
+	var results []ProbeResult
+	failed := map[string]bool{}
+	var errs []error
+	for _, paths := range [][]string{cfg.ZapConfig.OutputPaths, cfg.ZapConfig.ErrorOutputPaths} {
+		for _, path := range paths {
+			if path == SinkTypeStdout || path == SinkTypeStderr || probed(results, path) {
+				continue
+			}
+			result := ProbeResult{Path: path}
+			if err := probe(path, cfg.Preflight.CreateDirs); err != nil {
+				result.Error = err.Error()
+				failed[path] = true
+				errs = append(errs, fmt.Errorf("%s can't be written: %w", path, err))
+			}
+			results = append(results, result)
+		}
+	}
+	if len(failed) == 0 {
+		return cfg, results, nil
+	}
+	if cfg.Preflight.Strict {
+		return nil, results, errors.Join(errs...)
+	}
+	fallback := *cfg
+	fallback.ZapConfig.OutputPaths = replaceFailed(cfg.ZapConfig.OutputPaths, failed)
+	fallback.ZapConfig.ErrorOutputPaths = replaceFailed(cfg.ZapConfig.ErrorOutputPaths, failed)
+	return &fallback, results, nil
+}
+
+func probed(results []ProbeResult, path string) bool {
+	for _, result := range results {
+		if result.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// probe creates the directory of the log file when createDirs is true,
+// and checks that a file can be created in it and the existing log file can be appended to.
+func probe(path string, createDirs bool) error {
+	dir := filepath.Dir(path)
+	if createDirs {
+		// the same permission as lumberjack, which creates the directory at the first log otherwise.
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	err = file.Close()
+	_ = os.Remove(file.Name())
+	if err != nil {
+		return err
+	}
+	existing, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return existing.Close()
+}
+
+// replaceFailed returns the paths whose failed files are replaced by stderr, which is listed once.
+func replaceFailed(paths []string, failed map[string]bool) []string {
+	replaced := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if failed[path] {
+			path = SinkTypeStderr
+		}
+		if path != SinkTypeStderr || !slices.Contains(replaced, SinkTypeStderr) {
+			replaced = append(replaced, path)
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createPreflightTestConfig(path string) *Config {
+	cfg := createTestConfig()
+	cfg.ZapConfig.OutputPaths = []string{path}
+	cfg.LogRotate.MaxSize = megabyte
+	return cfg
+}
+
+func TestPreflight_CreateDirs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "var", "log", "app.log")
+	cfg := createPreflightTestConfig(path)
+	cfg.Preflight.CreateDirs = true
+
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+
+	assert.DirExists(t, filepath.Dir(path))
+	assert.Equal(t, []ProbeResult{{Path: path}}, log.Diagnostics().Preflight)
+	log.GetZapLogger().Info("written")
+	assert.FileExists(t, path)
+}
+
+func TestPreflight_MissingDirFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "var", "log", "app.log")
+	cfg := createPreflightTestConfig(path)
+
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+
+	assert.NoDirExists(t, filepath.Dir(path))
+	report := log.Diagnostics()
+	if assert.Len(t, report.Preflight, 1) {
+		assert.Contains(t, report.Preflight[0].Error, "no such file or directory")
+	}
+	if assert.Len(t, report.Sinks, 1) {
+		assert.Equal(t, SinkTypeStderr, report.Sinks[0].Type)
+	}
+	assert.Equal(t, []string{path}, cfg.ZapConfig.OutputPaths)
+}
+
+func TestPreflight_MissingDirStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "var", "log", "app.log")
+	cfg := createPreflightTestConfig(path)
+	cfg.Preflight.Strict = true
+
+	_, err := New(cfg)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), path+" can't be written")
+	}
+}
+
+func TestPreflight_ReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write the read-only directory")
+	}
+	dir := filepath.Join(t.TempDir(), "readonly")
+	require.NoError(t, os.Mkdir(dir, 0o500))
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o700) })
+	cfg := createPreflightTestConfig(filepath.Join(dir, "app.log"))
+	cfg.Preflight.CreateDirs = true
+	cfg.Preflight.Strict = true
+
+	_, err := New(cfg)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "permission denied")
+	}
+}
+
+func TestReplaceFailed(t *testing.T) {
+	failed := map[string]bool{"a.log": true, "b.log": true}
+
+	assert.Equal(t, []string{"stderr", "c.log"}, replaceFailed([]string{"a.log", "stderr", "b.log", "c.log"}, failed))
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// prettyCallerWidth is the width of the caller column. The longer caller is trimmed from the left.
+	prettyCallerWidth = 28
+	// prettyLevelWidth is the width of the level column, which fits the longest level name.
+	prettyLevelWidth = 6
+	colorReset       = "\x1b[0m"
+)
+
+var (
+	prettyPool = buffer.NewPool()
+
+	levelColors = map[zapcore.Level]string{
+		zapcore.DebugLevel:  "\x1b[35m",
+		zapcore.InfoLevel:   "\x1b[34m",
+		zapcore.WarnLevel:   "\x1b[33m",
+		zapcore.ErrorLevel:  "\x1b[31m",
+		zapcore.DPanicLevel: "\x1b[31m",
+		zapcore.PanicLevel:  "\x1b[31m",
+		zapcore.FatalLevel:  "\x1b[31m",
+	}
+)
+
+// prettyEncoder is the encoder for the local development, which aligns the time, the level, the caller
+// and the message into the fixed columns, and colorizes the level.
+// The fields are written after the message as json.
+type prettyEncoder struct {
+	zapcore.Encoder
+}
+
+// newPrettyEncoder is constructor.
+func newPrettyEncoder() zapcore.Encoder {
+	return &prettyEncoder{Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})}
+}
+
+// Clone copies the encoder with the fields added to it.
+func (e *prettyEncoder) Clone() zapcore.Encoder {
+	return &prettyEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes the entry and the fields into a line.
+func (e *prettyEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line := prettyPool.Get()
+	line.AppendString(entry.Time.Format(timeFormat))
+	line.AppendByte(' ')
+
+	level := entry.Level.CapitalString()
+	line.AppendString(levelColors[entry.Level])
+	line.AppendString(level)
+	line.AppendString(colorReset)
+	line.AppendString(strings.Repeat(" ", max(prettyLevelWidth-len(level), 1)))
+
+	caller := ""
+	if entry.Caller.Defined {
+		caller = entry.Caller.TrimmedPath()
+	}
+	if len(caller) > prettyCallerWidth {
+		caller = "…" + caller[len(caller)-prettyCallerWidth+1:]
+	}
+	line.AppendString(caller)
+	line.AppendString(strings.Repeat(" ", max(prettyCallerWidth-len([]rune(caller)), 0)+1))
+
+	if entry.LoggerName != "" {
+		line.AppendString("[" + entry.LoggerName + "] ")
+	}
+	line.AppendString(entry.Message)
+
+	encoded, err := e.Encoder.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return nil, err
+	}
+	if context := strings.TrimSpace(encoded.String()); context != "{}" {
+		line.AppendByte(' ')
+		line.AppendString(context)
+	}
+	encoded.Free()
+
+	if entry.Stack != "" {
+		line.AppendByte('\n')
+		line.AppendString(entry.Stack)
+	}
+	line.AppendByte('\n')
+	return line, nil
+}
+
+// isTerminal returns true when all the paths are the standard outputs connected to a terminal.
+func isTerminal(paths []string) bool {
+	for _, path := range paths {
+		var file *os.File
+		switch path {
+		case "stdout":
+			file = os.Stdout
+		case "stderr":
+			file = os.Stderr
+		default:
+			return false
+		}
+		if !isatty.IsTerminal(file.Fd()) {
+			return false
+		}
+	}
+	return len(paths) > 0
+}
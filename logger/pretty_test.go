@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestPrettyEncoder_Golden(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 30, 15, 123000000, time.UTC)
+	entries := []struct {
+		entry  zapcore.Entry
+		fields []zapcore.Field
+	}{
+		{
+			entry: zapcore.Entry{Level: zapcore.InfoLevel, Time: at, Message: "Success database connection",
+				Caller: zapcore.NewEntryCaller(0, "/src/repository/repository.go", 52, true)},
+		},
+		{
+			entry: zapcore.Entry{Level: zapcore.WarnLevel, Time: at, LoggerName: "gorm", Message: "slow query",
+				Caller: zapcore.NewEntryCaller(0, "/src/logger/gormlogger.go", 80, true)},
+			fields: []zapcore.Field{zap.Int("rows", 3), zap.Duration("elapsed", 250*time.Millisecond)},
+		},
+		{
+			entry: zapcore.Entry{Level: zapcore.ErrorLevel, Time: at, Message: "failed to the registration",
+				Caller: zapcore.NewEntryCaller(0, "/src/service/somewhere/very/deep/bookservice.go", 120, true),
+				Stack:  "main.main\n\t/src/main.go:10"},
+		},
+	}
+
+	enc := newPrettyEncoder()
+	enc.AddString("request_id", "abc")
+	var output []byte
+	for _, e := range entries {
+		line, err := enc.EncodeEntry(e.entry, e.fields)
+		require.NoError(t, err)
+		output = append(output, line.Bytes()...)
+		line.Free()
+	}
+
+	golden := "testdata/pretty.golden"
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, output, 0o600))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(output))
+}
+
+func TestNewEncoder_PrettyOnlyForTerminal(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.Encoding = "pretty"
+	cfg.ZapConfig.OutputPaths = []string{"stdout", "./application.log"}
+
+	enc, err := newEncoder(cfg.ZapConfig)
+
+	assert.NoError(t, err)
+	_, ok := enc.(*prettyEncoder)
+	assert.False(t, ok)
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+)
+
+const (
+	// unrecognizedFormatMessage is the warning of the values of Print which can't be parsed.
+	unrecognizedFormatMessage = "unrecognized gorm log format, falling back to raw dump"
+	// rawDumpMessage is the message of the debug log which has the values of Print as they are.
+	rawDumpMessage = logTitle + "raw dump"
+	// v1SQLLevel and v1LogLevel are the first values of Print of gorm v1, which tell its layout.
+	v1SQLLevel = "sql"
+	v1LogLevel = "log"
+)
+
+// Print logs the values given by the Print-style logger of gorm v1, which is wired by mistake while migrating to v2.
+// It recognizes the layouts of gorm v1, such as "sql", source, duration, sql, vars, rows and "log", source, messages,
+// and the common layouts of v2, such as the arguments of Trace and a sql with its vars.
+// The values which can't be parsed are dumped at the debug level with a warning written once a minute,
+// and it never panics whatever the values are.
+func (log *logger) Print(values ...interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.logUnrecognized(values)
+		}
+	}()
+	if !log.printV1(values) && !log.printV2(values) {
+		log.logUnrecognized(values)
+	}
+}
+
+// printV1 logs the values of the layouts of gorm v1. It returns false when the values aren't one of them.
+func (log *logger) printV1(values []interface{}) bool {
+	if len(values) < 2 {
+		return false
+	}
+	level, _ := values[0].(string)
+	source, ok := values[1].(string)
+	if !ok {
+		return false
+	}
+	switch level {
+	case v1SQLLevel:
+		if len(values) < 5 || len(values) > 6 {
+			return false
+		}
+		elapsed, ok1 := values[2].(time.Duration)
+		sql, ok2 := values[3].(string)
+		vars, ok3 := values[4].([]interface{})
+		var rows int64
+		ok4 := true
+		if len(values) == 6 {
+			rows, ok4 = values[5].(int64)
+		}
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			return false
+		}
+		log.traceSQL(clock.Now().Add(-elapsed), sql, vars, rows)
+		return true
+	case v1LogLevel:
+		log.Zap.Infof(messageFormat, fmt.Sprint(values[2:]...), source)
+		return true
+	}
+	return false
+}
+
+// printV2 logs the values of the common layouts of gorm v2, which are the arguments of Trace with or without
+// the context, and a sql with its vars and optionally its rows. It returns false when the values aren't one of them.
+func (log *logger) printV2(values []interface{}) bool {
+	ctx := context.Background()
+	if len(values) > 0 {
+		if c, ok := values[0].(context.Context); ok {
+			ctx, values = c, values[1:]
+		}
+	}
+	if len(values) == 2 || len(values) == 3 {
+		if begin, ok := values[0].(time.Time); ok {
+			fc, ok := values[1].(func() (string, int64))
+			var err error
+			if len(values) == 3 && values[2] != nil {
+				err, ok = values[2].(error)
+			}
+			if ok && fc != nil {
+				log.Trace(ctx, begin, fc, err)
+				return true
+			}
+			return false
+		}
+		sql, ok1 := values[0].(string)
+		vars, ok2 := values[1].([]interface{})
+		var rows int64
+		ok3 := true
+		if len(values) == 3 {
+			rows, ok3 = values[2].(int64)
+		}
+		if ok1 && ok2 && ok3 {
+			log.traceSQL(clock.Now(), sql, vars, rows)
+			return true
+		}
+	}
+	return false
+}
+
+// traceSQL logs the sql of Print as Trace does, so it has the same format as the other sqls.
+func (log *logger) traceSQL(begin time.Time, sql string, vars []interface{}, rows int64) {
+	ctx := context.WithValue(context.Background(), statementKey{}, &statement{sql: sql, vars: vars, rows: rows})
+	log.Trace(ctx, begin, func() (string, int64) { return sql, rows }, nil)
+}
+
+// logUnrecognized dumps the values of Print which can't be parsed at the debug level,
+// and warns of them at most once in defaultRateLimitWindow.
+func (log *logger) logUnrecognized(values []interface{}) {
+	now := clock.Now().UnixNano()
+	last := log.unrecognized.Load()
+	if (last == 0 || now-last >= int64(defaultRateLimitWindow)) && log.unrecognized.CompareAndSwap(last, now) {
+		log.Zap.Warn(unrecognizedFormatMessage)
+	}
+	log.Zap.Debugw(rawDumpMessage, "values", fmt.Sprintf("%#v", values))
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPrint_V1SQL(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.Print("sql", "/src/model/book.go:42", 3*time.Millisecond, "SELECT * FROM book WHERE id = ?",
+		[]interface{}{1}, int64(1))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "[gorm] SELECT * FROM book WHERE id = 1", entries[0].Message)
+		assert.Equal(t, int64(1), entries[0].ContextMap()["rows"])
+	}
+}
+
+func TestPrint_V1Log(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.Print("log", "/src/model/book.go:42", "record not found")
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "[gorm] record not found, /src/model/book.go:42", entries[0].Message)
+	}
+}
+
+func TestPrint_V2Layouts(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	fc := func() (string, int64) { return "SELECT 1", 1 }
+
+	log.Print(context.Background(), time.Now(), fc, nil)
+	log.Print(time.Now(), fc, errors.New("failed"))
+	log.Print("SELECT * FROM book WHERE id = ?", []interface{}{2})
+
+	assert.Equal(t, 1, logs.FilterMessage("[gorm] SELECT 1").Len())
+	assert.Equal(t, 1, logs.FilterMessage(sqlErrorMessage).Len())
+	assert.Equal(t, 1, logs.FilterMessage("[gorm] SELECT * FROM book WHERE id = 2").Len())
+	assert.Zero(t, logs.FilterMessage(unrecognizedFormatMessage).Len())
+}
+
+func TestPrint_Unrecognized(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	fake := clock.NewFake(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+	t.Cleanup(clock.Set(fake.Now))
+
+	log.Print("sql", "/src/model/book.go:42", "not a duration")
+	log.Print(42)
+	fake.Advance(defaultRateLimitWindow)
+	log.Print()
+
+	assert.Equal(t, 2, logs.FilterMessage(unrecognizedFormatMessage).Len())
+	dumps := logs.FilterMessage(rawDumpMessage).All()
+	if assert.Len(t, dumps, 3) {
+		assert.Equal(t, "[]interface {}{42}", dumps[1].ContextMap()["values"])
+	}
+}
+
+func TestPrint_RandomValuesNeverPanic(t *testing.T) {
+	core, _ := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	candidates := []interface{}{
+		nil, "sql", "log", "SELECT * FROM book WHERE id = ?", "/src/model/book.go:42", 1, int64(3), 2.5,
+		3 * time.Millisecond, time.Now(), []interface{}{1, "a"}, []interface{}{}, []string{"a"}, errors.New("failed"),
+		context.Background(), func() (string, int64) { return "SELECT 1", 1 },
+		func() (string, int64) { panic("broken") }, (func() (string, int64))(nil), map[string]int{"a": 1},
+		(*int)(nil), struct{}{},
+	}
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		values := make([]interface{}, random.Intn(8))
+		for j := range values {
+			values[j] = candidates[random.Intn(len(candidates))]
+		}
+		assert.NotPanics(t, func() { log.Print(values...) }, "%#v", values)
+	}
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// hostKey and pidKey are the keys of the hostname and the process ID added to every log.
+	hostKey = "host"
+	pidKey  = "pid"
+)
+
+// processFields returns the fields of the hostname and the process ID enabled by include_host and include_pid,
+// which are resolved once when the logger is built. It returns an error when the hostname can't be resolved.
+func (c *Config) processFields() ([]zap.Field, error) {
+	var fields []zap.Field
+	if c.IncludeHost {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the hostname: %w", err)
+		}
+		fields = append(fields, zap.String(hostKey, host))
+	}
+	if c.IncludePID {
+		fields = append(fields, zap.Int(pidKey, os.Getpid()))
+	}
+	return fields, nil
+}
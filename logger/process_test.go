@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_ProcessFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		includeHost bool
+		includePID  bool
+	}{
+		{"both", true, true},
+		{"host only", true, false},
+		{"none", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			cfg := createTestConfig()
+			cfg.LogRotate.MaxSize = megabyte
+			cfg.ZapConfig.OutputPaths = []string{path}
+			cfg.IncludeHost, cfg.IncludePID = tt.includeHost, tt.includePID
+			log, err := New(cfg)
+			require.NoError(t, err)
+			log.GetZapLogger().Infow("request", "status", 200)
+			require.NoError(t, log.Close())
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+			host, _ := os.Hostname()
+			if tt.includeHost {
+				assert.Equal(t, host, entry[hostKey])
+			} else {
+				assert.NotContains(t, entry, hostKey)
+			}
+			if tt.includePID {
+				assert.EqualValues(t, os.Getpid(), entry[pidKey])
+			} else {
+				assert.NotContains(t, entry, pidKey)
+			}
+			assert.EqualValues(t, 200, entry["status"])
+		})
+	}
+}
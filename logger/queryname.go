@@ -0,0 +1,24 @@
+package logger
+
+import "context"
+
+// QueryNameKey is the name of the log field which has the name of the hand-written query.
+const QueryNameKey = "query"
+
+// queryNameKey is the context key of the name of the hand-written query.
+type queryNameKey struct{}
+
+// WithQueryName returns the context which has the name of the hand-written query, such as books_per_category,
+// which labels the sql logs of the statements executed with it.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryNameFromContext returns the name of the query of the context. It returns an empty string when there is none.
+func QueryNameFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"strings"
+)
+
+const (
+	// QuoteStandard quotes the strings in the logged sqls by the standard SQL, which doubles the single quotes.
+	QuoteStandard = "standard"
+	// QuoteMySQL quotes the strings as MySQL reads them by default, which escapes the backslashes too.
+	QuoteMySQL = "mysql"
+	// QuotePostgres quotes the strings which have the backslashes or the control characters
+	// as the escape strings of PostgreSQL, such as E'a\nb'.
+	QuotePostgres = "postgres"
+)
+
+var (
+	// controlEscaper escapes the control characters as the visible sequences, so a logged sql stays on a line.
+	controlEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+	// backslashEscaper escapes the backslashes and the control characters of the strings of MySQL and PostgreSQL.
+	backslashEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\x00", `\0`)
+)
+
+// quoteStyle returns the style of the quoted strings of the dialect, which sql.quote_style overrides.
+// The dialects other than MySQL and PostgreSQL, such as SQLite, use the standard SQL.
+func (c *SQLConfig) quoteStyle(dialect string) string {
+	if c.QuoteStyle != "" {
+		return c.QuoteStyle
+	}
+	switch dialect {
+	case mysqlDialect:
+		return QuoteMySQL
+	case postgresDialect:
+		return QuotePostgres
+	}
+	return QuoteStandard
+}
+
+// quoteAs encloses a string value in single quotes, escaping it in the style,
+// so the logged sql can be run as it is to reproduce an issue.
+// The standard SQL has no escape of the control characters, so they are only made visible, such as \n.
+func quoteAs(value string, style string) string {
+	switch style {
+	case QuoteMySQL:
+		return quote(backslashEscaper.Replace(value))
+	case QuotePostgres:
+		if strings.ContainsAny(value, "\\\n\r\t\x00") {
+			return "E" + quote(backslashEscaper.Replace(value))
+		}
+	default:
+		return quote(controlEscaper.Replace(value))
+	}
+	return quote(value)
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteAs_Golden(t *testing.T) {
+	const golden = "testdata/quote.golden"
+	values := []interface{}{"O'Reilly", `C:\books\go`, "first line\nsecond\tline\r", "it's a\\n", []byte("a'b")}
+
+	var output strings.Builder
+	for _, style := range []string{QuoteStandard, QuoteMySQL, QuotePostgres} {
+		sql := createSQL("INSERT INTO book (a, b, c, d, e) VALUES (?, ?, ?, ?, ?)", "",
+			getFormattedValues(values, 0, style), 0, 0)
+		fmt.Fprintf(&output, "%s: %s\n", style, sql)
+	}
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, []byte(output.String()), 0o600))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), output.String())
+	assert.Equal(t, 3, strings.Count(output.String(), "\n"), "a logged sql must stay on a line")
+}
+
+func TestSQLConfig_QuoteStyle(t *testing.T) {
+	cfg := &SQLConfig{}
+	assert.Equal(t, QuoteStandard, cfg.quoteStyle("sqlite"))
+	assert.Equal(t, QuoteMySQL, cfg.quoteStyle(mysqlDialect))
+	assert.Equal(t, QuotePostgres, cfg.quoteStyle(postgresDialect))
+
+	cfg.QuoteStyle = QuoteStandard
+	assert.Equal(t, QuoteStandard, cfg.quoteStyle(mysqlDialect))
+}
+
+func TestTrace_QuoteStyleOfDialect(t *testing.T) {
+	stmt := &statement{sql: "SELECT * FROM book WHERE title = ?", vars: []interface{}{`a\'b`}, dialect: mysqlDialect}
+	log := &logger{config: &Config{}}
+
+	ctx := context.WithValue(context.Background(), statementKey{}, stmt)
+
+	assert.Equal(t, `SELECT * FROM book WHERE title = 'a\\''b'`, log.explain(ctx, nil))
+}
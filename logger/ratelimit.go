@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultRateLimitWindow is the window used when it isn't specified in the setting.
+	defaultRateLimitWindow = time.Minute
+	suppressedFormat       = "suppressed %d identical messages: %s"
+)
+
+// RateLimitConfig represents the setting for the limit of the identical warning and error logs.
+type RateLimitConfig struct {
+	// Threshold is the number of the identical logs written in a window. Zero means no limit.
+	Threshold int `json:"threshold" yaml:"threshold"`
+	// Window is the period in which the identical logs are counted, such as 1m. It is 1m by default.
+	Window config.Duration `json:"window" yaml:"window"`
+}
+
+// rateLimitKey identifies the identical logs.
+type rateLimitKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// rateLimitCount is the number of the identical logs in the current window.
+type rateLimitCount struct {
+	start      time.Time
+	written    int
+	suppressed int
+}
+
+// rateLimitState is the counts shared by the cores derived by With.
+type rateLimitState struct {
+	mutex     sync.Mutex
+	counts    map[rateLimitKey]*rateLimitCount
+	lastSweep time.Time
+}
+
+// rateLimitCore is the zapcore.Core which suppresses the identical warning and error logs
+// after the threshold is reached in a window, to prevent the log storm while a dependency is down.
+// The number of the suppressed logs is written as a summary when the window ends, by the next warning or error,
+// Sync, or the periodic flush, whichever comes first.
+type rateLimitCore struct {
+	zapcore.Core
+	threshold int
+	window    time.Duration
+	now       func() time.Time
+	state     *rateLimitState
+}
+
+// newRateLimitCore wraps the core. It returns the core as it is when no threshold is given.
+// The summaries are flushed every window until done is closed, so they aren't held back while the logs are quiet.
+// They aren't flushed periodically when done is nil.
+func newRateLimitCore(core zapcore.Core, cfg *RateLimitConfig, done <-chan struct{}) zapcore.Core {
+	if cfg.Threshold <= 0 {
+		return core
+	}
+	window := cfg.Window.Std()
+	if window <= 0 {
+		window = defaultRateLimitWindow
+	}
+	c := &rateLimitCore{Core: core, threshold: cfg.Threshold, window: window, now: clock.Now,
+		state: &rateLimitState{counts: map[rateLimitKey]*rateLimitCount{}}}
+	if done != nil {
+		go c.flushEvery(done)
+	}
+	return c
+}
+
+// flushEvery writes the summaries of the ended windows every window until done is closed.
+func (c *rateLimitCore) flushEvery(done <-chan struct{}) {
+	ticker := time.NewTicker(c.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.flush()
+		case <-done:
+			return
+		}
+	}
+}
+
+// flush writes the summaries of the windows which have ended.
+func (c *rateLimitCore) flush() {
+	c.state.mutex.Lock()
+	summaries := c.sweep(c.now(), false)
+	c.state.mutex.Unlock()
+	c.writeSummaries(summaries)
+}
+
+// With adds the fields to the core, sharing the counts.
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), threshold: c.threshold, window: c.window, now: c.now,
+		state: c.state}
+}
+
+// Check determines whether the entry should be logged. The suppressed entry isn't encoded at all.
+func (c *rateLimitCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	if entry.Level >= zapcore.WarnLevel && !c.allow(entry) {
+		return checked
+	}
+	return checked.AddCore(entry, c)
+}
+
+// Sync writes the summaries of the suppressed logs, and flushes the wrapped core.
+func (c *rateLimitCore) Sync() error {
+	c.state.mutex.Lock()
+	summaries := c.sweep(c.now(), true)
+	c.state.mutex.Unlock()
+	c.writeSummaries(summaries)
+	return c.Core.Sync()
+}
+
+// allow counts the entry, and returns false when it must be suppressed.
+func (c *rateLimitCore) allow(entry zapcore.Entry) bool {
+	now := c.now()
+	key := rateLimitKey{level: entry.Level, message: entry.Message}
+
+	c.state.mutex.Lock()
+	var summaries []zapcore.Entry
+	if now.Sub(c.state.lastSweep) >= c.window {
+		summaries = c.sweep(now, false)
+	}
+	count, ok := c.state.counts[key]
+	if !ok {
+		count = &rateLimitCount{start: now}
+		c.state.counts[key] = count
+	}
+	allowed := count.written < c.threshold
+	if allowed {
+		count.written++
+	} else {
+		count.suppressed++
+	}
+	c.state.mutex.Unlock()
+
+	c.writeSummaries(summaries)
+	return allowed
+}
+
+// sweep removes the counts whose windows have ended, or all counts when all is true,
+// and returns the summaries of their suppressed logs. It must be called with the lock held.
+func (c *rateLimitCore) sweep(now time.Time, all bool) []zapcore.Entry {
+	var summaries []zapcore.Entry
+	for key, count := range c.state.counts {
+		if !all && now.Sub(count.start) < c.window {
+			continue
+		}
+		if count.suppressed > 0 {
+			summaries = append(summaries, zapcore.Entry{Level: key.level, Time: now,
+				Message: fmt.Sprintf(suppressedFormat, count.suppressed, key.message)})
+		}
+		delete(c.state.counts, key)
+	}
+	c.state.lastSweep = now
+	return summaries
+}
+
+func (c *rateLimitCore) writeSummaries(summaries []zapcore.Entry) {
+	for _, summary := range summaries {
+		_ = c.Core.Write(summary, nil)
+	}
+}
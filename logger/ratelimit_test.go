@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRateLimitCore_Suppress(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core := newRateLimitCore(observed, &RateLimitConfig{Threshold: 3, Window: config.Duration(time.Minute)}, nil)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	core.(*rateLimitCore).now = func() time.Time { return now }
+	log := zap.New(core)
+
+	for i := 0; i < 10; i++ {
+		log.Error("failed to connect to database", zap.Error(errors.New("connection refused")))
+	}
+	log.Info("request")
+	log.Info("request")
+	log.Info("request")
+	log.Info("request")
+
+	assert.Equal(t, 3, logs.FilterMessage("failed to connect to database").Len())
+	assert.Equal(t, 4, logs.FilterMessage("request").Len())
+
+	now = now.Add(time.Minute)
+	log.Error("failed to connect to database")
+
+	summaries := logs.FilterMessage("suppressed 7 identical messages: failed to connect to database").All()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, zapcore.ErrorLevel, summaries[0].Level)
+	assert.Equal(t, 4, logs.FilterMessage("failed to connect to database").Len())
+}
+
+func TestRateLimitCore_SyncWritesSummary(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRateLimitCore(observed, &RateLimitConfig{Threshold: 1}, nil))
+
+	log.With(zap.String("user", "test")).Warn("slow response")
+	log.Warn("slow response")
+	require.NoError(t, log.Sync())
+
+	assert.Equal(t, 1, logs.FilterMessage("slow response").Len())
+	assert.Equal(t, 1, logs.FilterMessage("suppressed 1 identical messages: slow response").Len())
+}
+
+func TestRateLimitCore_Disabled(t *testing.T) {
+	observed, _ := observer.New(zapcore.DebugLevel)
+	assert.Equal(t, observed, newRateLimitCore(observed, &RateLimitConfig{}, nil))
+}
+
+func TestRateLimitCore_FlushEvery(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	done := make(chan struct{})
+	log := zap.New(newRateLimitCore(observed,
+		&RateLimitConfig{Threshold: 1, Window: config.Duration(20 * time.Millisecond)}, done))
+
+	log.Warn("slow response")
+	log.Warn("slow response")
+	log.Warn("slow response")
+
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessage("suppressed 2 identical messages: slow response").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	close(done)
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RecentBufferConfig represents the setting for the buffer of the recent logs, which keeps the last logs
+// in memory, so they can be queried while debugging a live incident without reading the log files.
+type RecentBufferConfig struct {
+	// Size is the number of the logs kept in the buffer, such as 1000. The buffer is disabled when it is zero.
+	Size int `json:"size" yaml:"size"`
+	// MinLevel is the lowest level of the logs kept in the buffer, such as warn. It is info by default,
+	// and it doesn't depend on the level of the logger.
+	MinLevel zapcore.Level `json:"min_level" yaml:"min_level"`
+}
+
+// RecentFilter is the condition of the logs returned by RecentEntries.
+type RecentFilter struct {
+	// MinLevel is the lowest level of the returned logs.
+	MinLevel zapcore.Level
+	// Contains is the substring of the messages of the returned logs. Every log is returned when it is empty.
+	Contains string
+}
+
+// recentEntry is a log kept in the buffer with its level.
+type recentEntry struct {
+	level zapcore.Level
+	event *Event
+}
+
+// recentBuffer is the ring buffer of the last logs, which overwrites the oldest one when it is full.
+type recentBuffer struct {
+	mu      sync.Mutex
+	entries []recentEntry
+	// next is the index which the next log is written to, and full is true once the buffer has wrapped around.
+	next int
+	full bool
+}
+
+// newRecentBuffer is constructor. It returns nil when the buffer isn't enabled.
+func newRecentBuffer(cfg *RecentBufferConfig) *recentBuffer {
+	if cfg.Size <= 0 {
+		return nil
+	}
+	return &recentBuffer{entries: make([]recentEntry, cfg.Size)}
+}
+
+// add keeps the log, overwriting the oldest one when the buffer is full.
+func (b *recentBuffer) add(level zapcore.Level, event *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = recentEntry{level: level, event: event}
+	b.next++
+	if b.next == len(b.entries) {
+		b.next, b.full = 0, true
+	}
+}
+
+// find returns the logs which match the filter, the oldest first.
+func (b *recentBuffer) find(filter RecentFilter) []Event {
+	b.mu.Lock()
+	ordered := append([]recentEntry(nil), b.entries[:b.next]...)
+	if b.full {
+		ordered = append(append([]recentEntry(nil), b.entries[b.next:]...), ordered...)
+	}
+	b.mu.Unlock()
+
+	events := []Event{}
+	for _, entry := range ordered {
+		if entry.level >= filter.MinLevel && strings.Contains(entry.event.Message, filter.Contains) {
+			events = append(events, *entry.event)
+		}
+	}
+	return events
+}
+
+// recentCore is the zapcore.Core which keeps the log entries in the buffer of the recent logs.
+type recentCore struct {
+	zapcore.LevelEnabler
+	buffer *recentBuffer
+	fields []zapcore.Field
+}
+
+func newRecentCore(buffer *recentBuffer, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &recentCore{LevelEnabler: enabler, buffer: buffer}
+}
+
+// With adds structured context to the core.
+func (c *recentCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+// Check determines whether the entry should be kept in the buffer.
+func (c *recentCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write converts the entry into an event and keeps it in the buffer.
+func (c *recentCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	c.buffer.add(entry.Level, newEvent(entry, c.fields, fields))
+	return nil
+}
+
+// Sync does nothing because the buffer is in memory.
+func (c *recentCore) Sync() error {
+	return nil
+}
+
+// RecentEntries returns the logs kept in the buffer of recent_buffer which match the filter, the oldest first.
+// It returns an empty slice when the buffer isn't enabled.
+func (log *logger) RecentEntries(filter RecentFilter) []Event {
+	if log.recent == nil {
+		return []Event{}
+	}
+	return log.recent.find(filter)
+}
+
+// RecentBufferEnabled returns whether the logs are kept in the buffer of recent_buffer.
+func (log *logger) RecentBufferEnabled() bool {
+	return log.recent != nil
+}
+
+// RecentEntriesHandler returns the handler which renders the recent logs as JSON, filtered by the query parameters
+// level, the lowest level such as warn, and q, the substring of the messages.
+func RecentEntriesHandler(log Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := RecentFilter{MinLevel: zapcore.DebugLevel, Contains: r.URL.Query().Get("q")}
+		if level := r.URL.Query().Get("level"); level != "" {
+			parsed, err := zapcore.ParseLevel(level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter.MinLevel = parsed
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(log.RecentEntries(filter))
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// newRecentTestLogger builds the logger which keeps the last size logs of warn and above.
+func newRecentTestLogger(t *testing.T, size int) Logger {
+	cfg := createTestConfig()
+	cfg.LogRotate.MaxSize = megabyte
+	cfg.ZapConfig.OutputPaths = []string{filepath.Join(t.TempDir(), "app.log")}
+	cfg.RecentBuffer = RecentBufferConfig{Size: size, MinLevel: zapcore.WarnLevel}
+	log, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+	return log
+}
+
+func messages(events []Event) []string {
+	var messages []string
+	for _, event := range events {
+		messages = append(messages, event.Message)
+	}
+	return messages
+}
+
+func TestRecentEntries_EvictsOldest(t *testing.T) {
+	log := newRecentTestLogger(t, 3)
+
+	for i := 1; i <= 5; i++ {
+		log.GetZapLogger().Warnw(fmt.Sprintf("warning %d", i), "i", i)
+		log.GetZapLogger().Infof("info %d", i)
+	}
+
+	events := log.RecentEntries(RecentFilter{})
+	assert.Equal(t, []string{"warning 3", "warning 4", "warning 5"}, messages(events))
+	assert.Equal(t, "warn", events[0].Level)
+	assert.Equal(t, map[string]interface{}{"i": int64(3)}, events[0].Fields)
+}
+
+func TestRecentEntries_Filter(t *testing.T) {
+	log := newRecentTestLogger(t, 10)
+	log.GetZapLogger().Warn("slow sql")
+	log.GetZapLogger().Error("failed sql")
+	log.GetZapLogger().Error("failed request")
+
+	assert.Equal(t, []string{"failed sql", "failed request"},
+		messages(log.RecentEntries(RecentFilter{MinLevel: zapcore.ErrorLevel})))
+	assert.Equal(t, []string{"slow sql", "failed sql"}, messages(log.RecentEntries(RecentFilter{Contains: "sql"})))
+	assert.Empty(t, log.RecentEntries(RecentFilter{MinLevel: zapcore.FatalLevel}))
+	assert.NotNil(t, NewLogger(nil).RecentEntries(RecentFilter{}))
+}
+
+func TestRecentBufferEnabled(t *testing.T) {
+	assert.True(t, newRecentTestLogger(t, 10).RecentBufferEnabled())
+	assert.False(t, newRecentTestLogger(t, 0).RecentBufferEnabled())
+	assert.False(t, NewLogger(nil).RecentBufferEnabled())
+}
+
+func TestRecentEntries_Concurrent(t *testing.T) {
+	log := newRecentTestLogger(t, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				log.GetZapLogger().Warn("warning")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				_ = log.RecentEntries(RecentFilter{Contains: "warn"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, log.RecentEntries(RecentFilter{}), 100)
+}
+
+func TestRecentEntriesHandler(t *testing.T) {
+	log := newRecentTestLogger(t, 10)
+	log.GetZapLogger().Warn("slow sql")
+	log.GetZapLogger().Error("failed sql")
+
+	rec := httptest.NewRecorder()
+	RecentEntriesHandler(log).ServeHTTP(rec,
+		httptest.NewRequest(http.MethodGet, "/api/admin/debug/logs?level=error&q=sql", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var events []Event
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	assert.Equal(t, []string{"failed sql"}, messages(events))
+
+	rec = httptest.NewRecorder()
+	RecentEntriesHandler(log).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/debug/logs?level=loud", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue is the value written instead of the redacted one.
+const redactedValue = "***"
+
+// RedactConfig represents the setting for the redaction of the log fields.
+type RedactConfig struct {
+	// Keys is the list of the field keys whose values are redacted. The keys are case-insensitive.
+	Keys []string `json:"keys" yaml:"keys"`
+}
+
+// redactCore is the zapcore.Core which replaces the values of the sensitive fields before they are encoded.
+// The fields nested in the objects and the structs are also redacted.
+type redactCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+// newRedactCore wraps the core. It returns the core as it is when no key is given.
+func newRedactCore(core zapcore.Core, keys []string) zapcore.Core {
+	if len(keys) == 0 {
+		return core
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+	}
+	return &redactCore{Core: core, keys: set}
+}
+
+// With adds the redacted fields to the core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactFields(fields)), keys: c.keys}
+}
+
+// Check determines whether the entry should be logged.
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write redacts the fields and writes the entry to the wrapped core.
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+func (c *redactCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = c.redactField(field)
+	}
+	return redacted
+}
+
+func (c *redactCore) redactField(field zapcore.Field) zapcore.Field {
+	if c.isSensitive(field.Key) {
+		return zap.String(field.Key, redactedValue)
+	}
+	switch field.Type {
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.InlineMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		if field.Type == zapcore.InlineMarshalerType {
+			return zap.Inline(redactedObject(c.redactMap(enc.Fields)))
+		}
+		return zap.Any(field.Key, c.redactValue(enc.Fields[field.Key]))
+	case zapcore.ReflectType:
+		return zap.Any(field.Key, c.redactReflected(field.Interface))
+	}
+	return field
+}
+
+// redactReflected converts the value into the generic one through json, and redacts it.
+func (c *redactCore) redactReflected(value interface{}) interface{} {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var generic interface{}
+	if err := json.Unmarshal(bytes, &generic); err != nil {
+		return value
+	}
+	return c.redactValue(generic)
+}
+
+func (c *redactCore) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return c.redactMap(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = c.redactValue(item)
+		}
+		return v
+	}
+	return value
+}
+
+func (c *redactCore) redactMap(values map[string]interface{}) map[string]interface{} {
+	for key, value := range values {
+		if c.isSensitive(key) {
+			values[key] = redactedValue
+			continue
+		}
+		values[key] = c.redactValue(value)
+	}
+	return values
+}
+
+func (c *redactCore) isSensitive(key string) bool {
+	_, ok := c.keys[strings.ToLower(key)]
+	return ok
+}
+
+// redactedObject is the object which adds the redacted values to the encoder.
+type redactedObject map[string]interface{}
+
+// MarshalLogObject adds the values to the encoder.
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for key, value := range o {
+		if err := enc.AddReflected(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type redactTestAccount struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func TestRedactCore_Field(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := createTestConfig()
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(cfg.ZapConfig.EncoderConfig), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	log := zap.New(newRedactCore(core, []string{"password", "token"}))
+
+	log.Info("login", zap.String("name", "test"), zap.String("Password", "p@ssw0rd"))
+
+	assert.Contains(t, buf.String(), `"name":"test"`)
+	assert.Contains(t, buf.String(), `"Password":"***"`)
+	assert.NotContains(t, buf.String(), "p@ssw0rd")
+}
+
+func TestRedactCore_With(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactCore(core, []string{"token"}))
+
+	log.With(zap.String("token", "abcdef")).Info("request")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "***", logs.All()[0].ContextMap()["token"])
+}
+
+func TestRedactCore_Struct(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactCore(core, []string{"password"})).Sugar()
+
+	log.Infow("create account", "account", &redactTestAccount{Name: "test", Password: "p@ssw0rd"})
+
+	require.Equal(t, 1, logs.Len())
+	account := logs.All()[0].ContextMap()["account"]
+	assert.Equal(t, map[string]interface{}{"name": "test", "password": "***"}, account)
+}
+
+func TestRedactCore_NoKeys(t *testing.T) {
+	core, _ := observer.New(zapcore.DebugLevel)
+
+	assert.Same(t, core, newRedactCore(core, nil))
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDKey is the name of the log field which has the request ID.
+	RequestIDKey = "request_id"
+	// RequestIDFormatUUID generates the request IDs as UUIDv4, such as 0f8fad5b-d9cb-469f-a165-70867728950e.
+	RequestIDFormatUUID = "uuid"
+	// RequestIDFormatSortable generates the shorter request IDs which are sorted by the time,
+	// such as 018f3a2b4c5d9e8f7a6b.
+	RequestIDFormatSortable = "sortable"
+)
+
+// RequestIDConfig represents the setting for the request IDs.
+type RequestIDConfig struct {
+	// Format is the format of the generated request IDs, uuid or sortable. It is uuid by default.
+	Format string `json:"format" yaml:"format"`
+}
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// requestIDGenerator generates the request IDs in the configured format.
+var requestIDGenerator atomic.Value
+
+func init() {
+	requestIDGenerator.Store(newUUID)
+}
+
+// setRequestIDFormat changes the format of the generated request IDs.
+func setRequestIDFormat(format string) {
+	if format == RequestIDFormatSortable {
+		requestIDGenerator.Store(newSortableID)
+		return
+	}
+	requestIDGenerator.Store(newUUID)
+}
+
+// WithRequestID returns the context which has the given request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID of the context. It returns an empty string when there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// EnsureRequestID returns the request ID of the context.
+// When the context has none, it generates a new one and returns the context which has it.
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := requestIDGenerator.Load().(func() string)()
+	return WithRequestID(ctx, id), id
+}
+
+// WithContextFields returns the logger which adds the request ID, the transaction ID, the name of the query
+// and the fields added by WithFields of the context to the logs.
+func WithContextFields(ctx context.Context, sugar *zap.SugaredLogger) *zap.SugaredLogger {
+	var fields []interface{}
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, RequestIDKey, id)
+	}
+	if id := TransactionIDFromContext(ctx); id != "" {
+		fields = append(fields, TransactionIDKey, id)
+	}
+	if name := QueryNameFromContext(ctx); name != "" {
+		fields = append(fields, QueryNameKey, name)
+	}
+	fields = append(fields, FieldsFromContext(ctx)...)
+	if len(fields) == 0 {
+		return sugar
+	}
+	return sugar.With(fields...)
+}
+
+func newUUID() string {
+	return uuid.NewString()
+}
+
+// newSortableID returns the hex of the milliseconds since the epoch followed by the random bytes.
+func newSortableID() string {
+	var id [10]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(clock.Now().UnixMilli())<<16)
+	_, _ = rand.Read(id[6:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEnsureRequestID_KeepsExisting(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "abc")
+
+	ensured, id := EnsureRequestID(ctx)
+
+	assert.Equal(t, "abc", id)
+	assert.Equal(t, ctx, ensured)
+}
+
+func TestEnsureRequestID_GeneratesUUID(t *testing.T) {
+	ctx, id := EnsureRequestID(context.Background())
+
+	assert.Equal(t, id, RequestIDFromContext(ctx))
+	_, err := uuid.Parse(id)
+	assert.NoError(t, err)
+}
+
+func TestEnsureRequestID_Sortable(t *testing.T) {
+	setRequestIDFormat(RequestIDFormatSortable)
+	t.Cleanup(func() { setRequestIDFormat(RequestIDFormatUUID) })
+
+	_, first := EnsureRequestID(context.Background())
+	time.Sleep(2 * time.Millisecond)
+	_, second := EnsureRequestID(context.Background())
+
+	assert.Len(t, first, 20)
+	assert.Less(t, first, second)
+}
+
+func TestTrace_RequestID(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	ctx := WithRequestID(context.Background(), "abc")
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "abc", entries[0].ContextMap()[RequestIDKey])
+	}
+}
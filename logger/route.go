@@ -0,0 +1,20 @@
+package logger
+
+import "context"
+
+// routeKey is the context key of the route of the request.
+type routeKey struct{}
+
+// WithRoute returns the context which has the route of the request, such as GET /api/categories.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the route of the context. It returns an empty string when there is none.
+func RouteFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// SchemaGCP names the fields as Google Cloud Logging expects, such as severity and message.
+	SchemaGCP = "gcp"
+	// SchemaECS names the fields as Elastic Common Schema, such as @timestamp and log.level.
+	SchemaECS = "ecs"
+)
+
+// durationEncoders is the encoders of the durations of duration_encoding.
+var durationEncoders = map[string]zapcore.DurationEncoder{
+	"string":  zapcore.StringDurationEncoder,
+	"seconds": zapcore.SecondsDurationEncoder,
+	"millis":  zapcore.MillisDurationEncoder,
+	"nanos":   zapcore.NanosDurationEncoder,
+}
+
+// schemas is the field names and the encoders of the named log schemas.
+var schemas = map[string]zapcore.EncoderConfig{
+	SchemaGCP: {
+		TimeKey:        "timestamp",
+		LevelKey:       "severity",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "message",
+		StacktraceKey:  "stacktrace",
+		EncodeLevel:    gcpLevelEncoder,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	},
+	SchemaECS: {
+		TimeKey:        "@timestamp",
+		LevelKey:       "log.level",
+		NameKey:        "log.logger",
+		CallerKey:      "log.origin",
+		MessageKey:     "message",
+		StacktraceKey:  "error.stack_trace",
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.NanosDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	},
+}
+
+// gcpSeverities is the severities of Google Cloud Logging for the levels of zap.
+var gcpSeverities = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+// gcpLevelEncoder encodes the level as the severity of Google Cloud Logging.
+func gcpLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	severity, ok := gcpSeverities[level]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	enc.AppendString(severity)
+}
+
+// encoderConfig returns the encoder setting of the logger.
+// When a schema is given, its field names and encoders replace the ones written in zap_config.encoderConfig,
+// and the other settings such as the line ending are kept.
+// The duration encoding replaces the encoder of the durations of both of them.
+func (c *Config) encoderConfig() zapcore.EncoderConfig {
+	encoderCfg := c.ZapConfig.EncoderConfig
+	if schema, ok := schemas[c.Schema]; ok {
+		encoderCfg.TimeKey = schema.TimeKey
+		encoderCfg.LevelKey = schema.LevelKey
+		encoderCfg.NameKey = schema.NameKey
+		encoderCfg.CallerKey = schema.CallerKey
+		encoderCfg.MessageKey = schema.MessageKey
+		encoderCfg.StacktraceKey = schema.StacktraceKey
+		encoderCfg.EncodeLevel = schema.EncodeLevel
+		encoderCfg.EncodeTime = schema.EncodeTime
+		encoderCfg.EncodeDuration = schema.EncodeDuration
+		encoderCfg.EncodeCaller = schema.EncodeCaller
+	}
+	if encoder, ok := durationEncoders[c.DurationEncoding]; ok {
+		encoderCfg.EncodeDuration = encoder
+	}
+	return encoderCfg
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSchema_Keys(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   map[string]interface{}
+	}{
+		{schema: SchemaGCP, want: map[string]interface{}{
+			"severity": "WARNING", "message": "test message", "logger": "app", "caller": "logger/schema_test.go:42",
+		}},
+		{schema: SchemaECS, want: map[string]interface{}{
+			"log.level": "warn", "message": "test message", "log.logger": "app", "log.origin": "logger/schema_test.go:42",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Schema = tt.schema
+
+			fields := encodeTestEntry(t, cfg)
+
+			for key, value := range tt.want {
+				assert.Equal(t, value, fields[key], key)
+			}
+			assert.NotContains(t, fields, "msg")
+			assert.NotContains(t, fields, "level")
+		})
+	}
+}
+
+func encodeTestEntry(t *testing.T, cfg *Config) map[string]interface{} {
+	caller := zapcore.NewEntryCaller(0, "/src/logger/schema_test.go", 42, true)
+	entry := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), LoggerName: "app", Message: "test message",
+		Caller: caller}
+	buf, err := zapcore.NewJSONEncoder(cfg.encoderConfig()).EncodeEntry(entry, nil)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	return fields
+}
+
+func TestSchema_Timestamp(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Schema = SchemaGCP
+	fields := encodeTestEntry(t, cfg)
+	_, err := time.Parse(time.RFC3339Nano, fields["timestamp"].(string))
+	assert.NoError(t, err)
+
+	cfg.Schema = SchemaECS
+	assert.Contains(t, encodeTestEntry(t, cfg), "@timestamp")
+}
+
+func TestSchema_None(t *testing.T) {
+	cfg := createTestConfig()
+
+	fields := encodeTestEntry(t, cfg)
+
+	assert.Equal(t, "test message", fields["msg"])
+	assert.Equal(t, "warn", fields["level"])
+}
+
+func TestSchema_Validate(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.OutputPaths = []string{"stdout"}
+	cfg.ZapConfig.EncoderConfig.MessageKey = ""
+
+	assert.ErrorContains(t, cfg.Validate(), "zap_config.encoderConfig.messageKey")
+
+	cfg.Schema = SchemaGCP
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Schema = "splunk"
+	assert.ErrorContains(t, cfg.Validate(), "schema: must be gcp or ecs")
+}
+
+func TestDurationEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		schema   string
+		want     interface{}
+	}{
+		{encoding: "string", want: "1.2s"},
+		{encoding: "seconds", want: 1.2},
+		{encoding: "millis", want: 1200.0},
+		{encoding: "nanos", want: 1.2e9},
+		{encoding: "millis", schema: SchemaECS, want: 1200.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding+tt.schema, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Schema = tt.schema
+			cfg.DurationEncoding = tt.encoding
+			cfg.ZapConfig.OutputPaths = []string{filepath.Join(t.TempDir(), "app.log")}
+			cfg.LogRotate.MaxSize = megabyte
+			log, err := build(cfg, nil, nil, &atomic.Uint64{}, &diagnostics{}, nil)
+			require.NoError(t, err)
+
+			log.Info("slow", zap.Duration("elapsed", 1200*time.Millisecond))
+
+			output, err := os.ReadFile(cfg.ZapConfig.OutputPaths[0])
+			require.NoError(t, err)
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(output, &fields))
+			assert.Equal(t, tt.want, fields["elapsed"])
+		})
+	}
+}
+
+func TestDurationEncoding_Validate(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.OutputPaths = []string{"stdout"}
+	cfg.DurationEncoding = "minutes"
+
+	assert.ErrorContains(t, cfg.Validate(), "duration_encoding: must be string, seconds, millis or nanos")
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	gormUtils "gorm.io/gorm/utils"
+)
+
+const (
+	// sqlErrorMessage is the message of the log which has everything to reproduce the failed sql.
+	sqlErrorMessage = logTitle + "sql_error"
+	// sqlTimeoutMessage is the message of the log of the sql which exceeded the deadline.
+	sqlTimeoutMessage = logTitle + "sql_timeout"
+)
+
+var (
+	// insertColumnsPattern matches the column list of the insert statement.
+	insertColumnsPattern = regexp.MustCompile("(?is)^\\s*INSERT\\s+INTO\\s+\\S+\\s*\\(([^)]*)\\)\\s*VALUES")
+	// comparedColumnPattern matches the column which is compared with or assigned the placeholder just after it.
+	comparedColumnPattern = regexp.MustCompile("(?i)([\\w.\"`]+)\\s*(?:=|<>|!=|<=|>=|<|>|\\s+LIKE)\\s*$")
+)
+
+// logSQLError logs the failed sql with the separated values, the dialect and the error,
+// so that the sql can be reproduced without the ambiguity of the embedded values.
+// The values bound to the columns named by the redact keys, such as password = ?, are redacted.
+// The sql which exceeded the deadline of its context is logged as sql_timeout with the elapsed time.
+func (log *logger) logSQLError(ctx context.Context, sugar *zap.SugaredLogger, fc func() (string, int64), err error,
+	elapsed time.Duration) {
+	message := sqlErrorMessage
+	var timeout []interface{}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		message = sqlTimeoutMessage
+		timeout = []interface{}{"elapsed", elapsed}
+	}
+	stmt, ok := ctx.Value(statementKey{}).(*statement)
+	if !ok {
+		sql, _ := fc()
+		sugar.Errorw(message, append([]interface{}{"statement", sql, "params", []string{}, "dialect", "",
+			"error", err.Error(), "source", gormUtils.FileWithLineNum()}, timeout...)...)
+		return
+	}
+	values := getFormattedValues(stmt.vars, log.config.SQL.MaxFormattedValues, log.config.SQL.quoteStyle(stmt.dialect))
+	values = redactParams(stmt.sql, stmt.dialect, values, log.config.Redact.Keys)
+	sugar.Errorw(message, append([]interface{}{
+		"statement", createSQL(stmt.sql, stmt.dialect, values, len(stmt.vars)-len(values), log.config.SQL.inListThreshold()),
+		"params", values,
+		"dialect", stmt.dialect,
+		"error", err.Error(),
+		"source", gormUtils.FileWithLineNum(),
+	}, timeout...)...)
+}
+
+// redactParams replaces the values bound to the columns named by the redact keys.
+func redactParams(sql string, dialect string, values []string, keys []string) []string {
+	if len(keys) == 0 {
+		return values
+	}
+	redacted := append([]string{}, values...)
+	for _, column := range placeholderColumns(sql, dialect) {
+		if column.index < 0 || column.index >= len(redacted) {
+			continue
+		}
+		for _, key := range keys {
+			if strings.EqualFold(column.name, key) {
+				redacted[column.index] = quote(redactedValue)
+			}
+		}
+	}
+	return redacted
+}
+
+// placeholderColumn is the column which a placeholder is bound to.
+type placeholderColumn struct {
+	name string
+	// index is the index of the value of the placeholder.
+	index int
+}
+
+// placeholderColumns returns the columns which the placeholders of the sql are bound to, as far as they are known.
+// The columns of the insert statement are known by the column list,
+// and the others by the comparison or the assignment such as name = ?.
+func placeholderColumns(sql string, dialect string) []placeholderColumn {
+	var insertColumns []string
+	valuesStart := 0
+	if match := insertColumnsPattern.FindStringSubmatchIndex(sql); match != nil {
+		for _, column := range strings.Split(sql[match[2]:match[3]], ",") {
+			insertColumns = append(insertColumns, columnName(column))
+		}
+		valuesStart = match[1]
+	}
+
+	var columns []placeholderColumn
+	inserted := 0
+	for _, p := range findPlaceholders(sql, dialect) {
+		if len(insertColumns) > 0 && p.start >= valuesStart {
+			columns = append(columns, placeholderColumn{name: insertColumns[inserted%len(insertColumns)], index: p.index})
+			inserted++
+			continue
+		}
+		if match := comparedColumnPattern.FindStringSubmatch(sql[:p.start]); match != nil {
+			columns = append(columns, placeholderColumn{name: columnName(match[1]), index: p.index})
+		}
+	}
+	return columns
+}
+
+// columnName returns the name of the column without the table name and the quotes.
+func columnName(column string) string {
+	column = strings.TrimSpace(column)
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	return strings.Trim(column, "\"`")
+}
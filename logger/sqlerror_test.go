@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTrace_SQLError(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := createTestConfig()
+	cfg.Redact.Keys = []string{"password"}
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	stmt := &statement{
+		sql:     "INSERT INTO account (name,password) VALUES (?,?)",
+		vars:    []interface{}{"test", "secret"},
+		dialect: "sqlite",
+	}
+	ctx := context.WithValue(context.Background(), statementKey{}, stmt)
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "", 0 }, errors.New("UNIQUE constraint failed"))
+
+	entries := logs.FilterMessage(sqlErrorMessage).All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, zap.ErrorLevel, entries[0].Level)
+		assert.Equal(t, "INSERT INTO account (name,password) VALUES ('test','***')", fields["statement"])
+		assert.Equal(t, []interface{}{"'test'", "'***'"}, fields["params"])
+		assert.Equal(t, "sqlite", fields["dialect"])
+		assert.Equal(t, "UNIQUE constraint failed", fields["error"])
+	}
+}
+
+func TestRedactParams(t *testing.T) {
+	keys := []string{"password", "token"}
+	tests := []struct {
+		name    string
+		sql     string
+		dialect string
+		want    []string
+	}{
+		{name: "insert", sql: "INSERT INTO `account` (`name`,`password`) VALUES (?,?),(?,?)",
+			want: []string{"'a'", "'***'", "'c'", "'***'"}},
+		{name: "where", sql: "SELECT * FROM account WHERE name = ? AND account.password = ? AND id > ? AND token=?",
+			want: []string{"'a'", "'***'", "'c'", "'***'"}},
+		{name: "postgres", sql: `UPDATE "account" SET "token"=$2 WHERE "name" = $1 AND id IN ($3, $4)`, dialect: "postgres",
+			want: []string{"'a'", "'***'", "'c'", "'d'"}},
+		{name: "like", sql: "SELECT * FROM account WHERE password LIKE ? AND name LIKE ? AND id IN (?,?)",
+			want: []string{"'***'", "'b'", "'c'", "'d'"}},
+		{name: "quoted", sql: "SELECT * FROM account WHERE note LIKE 'why?' AND name = ? AND password = ? AND id IN (?,?)",
+			want: []string{"'a'", "'***'", "'c'", "'d'"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := []string{"'a'", "'b'", "'c'", "'d'"}
+
+			assert.Equal(t, tt.want, redactParams(tt.sql, tt.dialect, values, keys))
+			assert.Equal(t, []string{"'a'", "'b'", "'c'", "'d'"}, values)
+		})
+	}
+}
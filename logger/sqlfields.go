@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// sqlMessage is the message of the json log of a sql, whose details are in the sql field.
+	sqlMessage = logTitle + "sql"
+	// jsonEncoding is the encoding whose sql logs have the separated fields.
+	jsonEncoding = "json"
+)
+
+// tablePattern matches the table which the statement reads from or writes to.
+var tablePattern = regexp.MustCompile(
+	"(?is)^\\s*(?:SELECT\\b.*?\\bFROM|INSERT\\s+INTO|UPDATE|DELETE\\s+FROM)\\s+([\\w.\"`]+)")
+
+// sqlFields is the sql field of the json logs, which can be aggregated by the statement.
+type sqlFields struct {
+	statement    string
+	values       []string
+	interpolated string
+	table        string
+	operation    string
+}
+
+// MarshalLogObject encodes the fields as the sql object, such as {"statement": "SELECT ...", "values": [...]}.
+func (f *sqlFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("statement", f.statement)
+	if err := enc.AddArray("values", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, value := range f.values {
+			arr.AppendString(value)
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+	if f.interpolated != "" {
+		enc.AddString("interpolated", f.interpolated)
+	}
+	enc.AddString("table", f.table)
+	enc.AddString("operation", f.operation)
+	return nil
+}
+
+// structuredSQL reports whether the sql logs have the separated fields instead of the human-readable line.
+func (log *logger) structuredSQL() bool {
+	return log.config.ZapConfig.Encoding == jsonEncoding
+}
+
+// newSQLFields separates the executed statement, its values, its table and its operation.
+// The sql which the values are embedded in is added only when sql.interpolated is enabled,
+// because it is expensive and it may have the sensitive values.
+// The values bound to the columns named by the redact keys are redacted.
+func (log *logger) newSQLFields(ctx context.Context, fc func() (string, int64)) *sqlFields {
+	stmt, ok := ctx.Value(statementKey{}).(*statement)
+	if !ok {
+		sql, _ := fc()
+		return &sqlFields{statement: sql, values: []string{}, table: tableOf(sql), operation: operationOf(sql)}
+	}
+	values := getFormattedValues(stmt.vars, log.config.SQL.MaxFormattedValues, log.config.SQL.quoteStyle(stmt.dialect))
+	values = redactParams(stmt.sql, stmt.dialect, values, log.config.Redact.Keys)
+	fields := &sqlFields{statement: stmt.sql, values: values, table: tableOf(stmt.sql), operation: operationOf(stmt.sql)}
+	if log.config.SQL.Interpolated {
+		fields.interpolated = createSQL(stmt.sql, stmt.dialect, values, len(stmt.vars)-len(values),
+			log.config.SQL.inListThreshold())
+	}
+	return fields
+}
+
+// logStructuredSQL writes the json log of the sql at the given level.
+func logStructuredSQL(sugar *zap.SugaredLogger, level zapcore.Level, message string, fields *sqlFields,
+	extra ...zap.Field) {
+	if ce := sugar.Desugar().Check(level, message); ce != nil {
+		ce.Write(append([]zap.Field{zap.Object("sql", fields)}, extra...)...)
+	}
+}
+
+// operationOf returns the first keyword of the statement in upper case, such as SELECT.
+func operationOf(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if i := strings.IndexFunc(sql, func(r rune) bool { return r == ' ' || r == '\n' || r == '\t' }); i >= 0 {
+		sql = sql[:i]
+	}
+	return strings.ToUpper(sql)
+}
+
+// tableOf returns the table which the statement reads from or writes to, without the quotes.
+// It returns an empty string when the table isn't found, such as the statement without a table.
+func tableOf(sql string) string {
+	match := tablePattern.FindStringSubmatch(sql)
+	if match == nil {
+		return ""
+	}
+	return strings.Trim(match[1], "`\"")
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTrace_SQLGolden(t *testing.T) {
+	for _, test := range []struct {
+		encoding     string
+		interpolated bool
+		golden       string
+	}{
+		{encoding: "json", golden: "testdata/sql.json.golden"},
+		{encoding: "json", interpolated: true, golden: "testdata/sql_interpolated.json.golden"},
+		{encoding: "console", golden: "testdata/sql.console.golden"},
+	} {
+		t.Run(test.golden, func(t *testing.T) {
+			output := traceForGolden(t, test.encoding, test.interpolated)
+
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(test.golden, output, 0o600))
+			}
+			expected, err := os.ReadFile(test.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(output))
+		})
+	}
+}
+
+func TestTrace_StructuredSlowSQL(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := createTestConfig()
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	ctx := context.WithValue(context.Background(), statementKey{}, &statement{
+		sql: "UPDATE `book` SET `title`=? WHERE `id` = ?", vars: []interface{}{"Go", 1}, dialect: "sqlite"})
+
+	log.Trace(ctx, time.Now().Add(-time.Second), func() (string, int64) { return "", 1 }, nil)
+
+	entries := logs.FilterMessage("[gorm] SLOW SQL >= 200ms").All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, map[string]interface{}{"statement": "UPDATE `book` SET `title`=? WHERE `id` = ?",
+			"values": []interface{}{"'Go'", "1"}, "table": "book", "operation": "UPDATE"}, fields["sql"])
+		assert.GreaterOrEqual(t, fields["elapsed"], time.Second)
+		assert.Contains(t, fields["source"], "sqlfields_test.go")
+	}
+}
+
+func TestTableAndOperationOf(t *testing.T) {
+	for sql, expected := range map[string][2]string{
+		"SELECT * FROM `book` WHERE id = ?":                          {"book", "SELECT"},
+		"select count(*)\nfrom \"category_master\"":                  {"category_master", "SELECT"},
+		"INSERT INTO `category_master` (`name`) VALUES (?)":          {"category_master", "INSERT"},
+		"UPDATE `book` SET `title`=? WHERE `id` = ?":                 {"book", "UPDATE"},
+		"DELETE FROM `format_master` WHERE `format_master`.`id` = ?": {"format_master", "DELETE"},
+		"SAVEPOINT sp1": {"", "SAVEPOINT"},
+	} {
+		assert.Equal(t, expected[0], tableOf(sql), sql)
+		assert.Equal(t, expected[1], operationOf(sql), sql)
+	}
+}
+
+// traceForGolden traces a sql by the logger of the given encoding, and returns the output.
+func traceForGolden(t *testing.T, encoding string, interpolated bool) []byte {
+	fake := clock.NewFake(time.Date(2024, 4, 1, 9, 30, 15, 0, time.UTC))
+	t.Cleanup(clock.Set(fake.Now))
+	cfg := createTestConfig()
+	cfg.ZapConfig.Encoding = encoding
+	cfg.SQL.Interpolated = interpolated
+	cfg.Redact.Keys = []string{"password"}
+	encoderConfig := cfg.ZapConfig.EncoderConfig
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	enc, err := newEncoder(zap.Config{Encoding: encoding, EncoderConfig: encoderConfig})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)
+	log := NewLoggerWithConfig(zap.New(core, zap.WithClock(fakeZapClock{fake})).Sugar(), cfg)
+	ctx := context.WithValue(WithRequestID(context.Background(), "abc"), statementKey{}, &statement{
+		sql:     "SELECT * FROM `account_master` WHERE name = ? AND password = ?",
+		vars:    []interface{}{"test", "p@ssw0rd"},
+		dialect: "sqlite",
+	})
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "", 1 }, nil)
+	return buf.Bytes()
+}
+
+// fakeZapClock stamps the entries by the fake clock.
+type fakeZapClock struct {
+	*clock.Fake
+}
+
+func (fakeZapClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
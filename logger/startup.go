@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// startupMessage is the message of the log written once at the start of the process.
+const startupMessage = "Started the application"
+
+// BuildInfo is the build and the runtime of the process, which is logged at startup,
+// so the logs of an incident can be correlated with the deploy.
+type BuildInfo struct {
+	Version   string `json:"version,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+	OS        string `json:"os,omitempty"`
+	Arch      string `json:"arch,omitempty"`
+	PID       int    `json:"pid,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	// Env is the running environment, such as develop.
+	Env string `json:"env,omitempty"`
+	// Level is the effective level of the logger, which LogStartupInfo fills.
+	Level string `json:"level,omitempty"`
+}
+
+// NewBuildInfo is constructor for the build of the process, whose version, commit and build date are
+// the values given by -ldflags. The missing ones are read from the build information embedded by the go command.
+// The runtime, such as the Go version, the platform, the PID and the hostname, is filled too.
+func NewBuildInfo(version string, commit string, buildDate string) BuildInfo {
+	info := BuildInfo{Version: version, Commit: commit, BuildDate: buildDate, GoVersion: runtime.Version(),
+		OS: runtime.GOOS, Arch: runtime.GOARCH, PID: os.Getpid()}
+	info.Hostname, _ = os.Hostname()
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// MarshalLogObject adds the fields which aren't empty.
+func (b BuildInfo) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range []struct{ key, value string }{
+		{"version", b.Version}, {"commit", b.Commit}, {"build_date", b.BuildDate}, {"go_version", b.GoVersion},
+		{"os", b.OS}, {"arch", b.Arch}, {"hostname", b.Hostname}, {"env", b.Env}, {"level", b.Level},
+	} {
+		if field.value != "" {
+			enc.AddString(field.key, field.value)
+		}
+	}
+	if b.PID != 0 {
+		enc.AddInt("pid", b.PID)
+	}
+	return nil
+}
+
+// LogStartupInfo writes the build and the runtime of the process as an info log, with the effective level.
+// It is called once right after the logger is initialized. The missing values are omitted, not logged as empty.
+// The logged values are returned by StartupInfo, which the diagnostics show too.
+func (log *logger) LogStartupInfo(info BuildInfo) {
+	info.Level = zapcore.LevelOf(log.Zap.Desugar().Core()).String()
+	log.startup.Store(&info)
+	logged := info
+	if log.config != nil && log.config.IncludePID {
+		// every log has the pid already.
+		logged.PID = 0
+	}
+	log.Zap.Desugar().Info(startupMessage, zap.Inline(logged))
+}
+
+// StartupInfo returns the build and the runtime logged by LogStartupInfo, which is empty before it is logged.
+func (log *logger) StartupInfo() BuildInfo {
+	if info := log.startup.Load(); info != nil {
+		return *info
+	}
+	return BuildInfo{}
+}
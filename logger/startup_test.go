@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogStartupInfo(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.LogStartupInfo(BuildInfo{Version: "1.5.1", Commit: "cba3b8c", BuildDate: "2024-04-01T09:30:15Z",
+		GoVersion: "go1.22.0", OS: "linux", Arch: "amd64", PID: 42, Hostname: "web-1", Env: "k8s"})
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Started the application", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{"version": "1.5.1", "commit": "cba3b8c",
+			"build_date": "2024-04-01T09:30:15Z", "go_version": "go1.22.0", "os": "linux", "arch": "amd64",
+			"pid": 42, "hostname": "web-1", "env": "k8s", "level": "info"}, entries[0].ContextMap())
+	}
+	assert.Equal(t, "1.5.1", log.StartupInfo().Version)
+	assert.Equal(t, "info", log.StartupInfo().Level)
+	assert.Equal(t, "k8s", log.Diagnostics().Build.Env)
+}
+
+func TestLogStartupInfo_IncludePID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), &Config{IncludePID: true})
+
+	log.LogStartupInfo(BuildInfo{Version: "1.5.1", PID: 42})
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.NotContains(t, entries[0].ContextMap(), "pid")
+	}
+	assert.Equal(t, 42, log.StartupInfo().PID)
+	assert.Equal(t, 42, log.Diagnostics().Build.PID)
+}
+
+func TestLogStartupInfo_OmitsMissing(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.LogStartupInfo(BuildInfo{Env: "develop"})
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, map[string]interface{}{"env": "develop", "level": "info"}, entries[0].ContextMap())
+	}
+}
+
+func TestNewBuildInfo(t *testing.T) {
+	info := NewBuildInfo("1.5.1", "", "")
+
+	assert.Equal(t, "1.5.1", info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS, info.OS)
+	assert.NotZero(t, info.PID)
+}
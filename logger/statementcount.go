@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// statementCountMessage is the message of the warning of the request which issued too many sqls.
+const statementCountMessage = "Too many sql statements in a request"
+
+// statementCounterKey is the context key of the counter of the sqls issued by a request.
+type statementCounterKey struct{}
+
+// StatementCounter is the number and the sqls of the statements issued with the context of WithStatementCounter,
+// which detects an N+1 query, such as in the requests by sql.max_statements_per_request
+// and in the tests by repotest.AssertMaxQueries.
+type StatementCounter struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+// Count returns the number of the statements issued so far.
+func (c *StatementCounter) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.statements)
+}
+
+// Statements returns the sqls of the statements issued so far with the placeholders, in order of the execution.
+func (c *StatementCounter) Statements() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.statements...)
+}
+
+// WithStatementCounter returns the context which counts the sqls issued with it, such as the context of a request.
+func WithStatementCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statementCounterKey{}, &StatementCounter{})
+}
+
+// StatementCounterFromContext returns the counter of the context, which is nil when the context doesn't count.
+func StatementCounterFromContext(ctx context.Context) *StatementCounter {
+	if ctx == nil {
+		return nil
+	}
+	counter, _ := ctx.Value(statementCounterKey{}).(*StatementCounter)
+	return counter
+}
+
+// StatementCount returns the number of the sqls issued with the context. It is zero when the context doesn't count.
+func StatementCount(ctx context.Context) int64 {
+	if counter := StatementCounterFromContext(ctx); counter != nil {
+		return int64(counter.Count())
+	}
+	return 0
+}
+
+// countStatement records the sql issued with the context, with the placeholders when the statement is attached
+// to the context. It falls back to the sql explained by gorm, which is built only when the context counts.
+func countStatement(ctx context.Context, fc func() (string, int64)) {
+	counter := StatementCounterFromContext(ctx)
+	if counter == nil {
+		return
+	}
+	var sql string
+	if stmt, ok := ctx.Value(statementKey{}).(*statement); ok {
+		sql = stmt.sql
+	} else {
+		sql, _ = fc()
+	}
+	counter.mu.Lock()
+	counter.statements = append(counter.statements, sql)
+	counter.mu.Unlock()
+}
+
+// LogStatementCount warns the request which issued more sqls than sql.max_statements_per_request,
+// which is likely an N+1 query. It is called when the request ends.
+func (log *logger) LogStatementCount(ctx context.Context) {
+	threshold := log.config.SQL.MaxStatementsPerRequest
+	if threshold <= 0 {
+		return
+	}
+	if count := StatementCount(ctx); count > int64(threshold) {
+		WithContextFields(ctx, log.Zap).Warnw(statementCountMessage, "statements", count, "threshold", threshold)
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogStatementCount(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := &Config{}
+	cfg.SQL.MaxStatementsPerRequest = 2
+	log := NewLoggerWithConfig(zap.New(core).Sugar(), cfg)
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	ctx := WithStatementCounter(context.Background())
+	log.Trace(ctx, time.Now(), sql, nil)
+	log.Trace(ctx, time.Now(), sql, nil)
+	log.LogStatementCount(ctx)
+	assert.Equal(t, int64(2), StatementCount(ctx))
+	assert.Zero(t, logs.Len())
+
+	log.Trace(ctx, time.Now(), sql, nil)
+	log.LogStatementCount(ctx)
+	entries := logs.FilterMessage(statementCountMessage).All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, int64(3), entries[0].ContextMap()["statements"])
+		assert.Equal(t, int64(2), entries[0].ContextMap()["threshold"])
+	}
+}
+
+func TestStatementCount_NoCounter(t *testing.T) {
+	log := NewLogger(zap.NewNop().Sugar())
+	log.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Zero(t, StatementCount(context.Background()))
+}
+
+func TestStatementCounter_Statements(t *testing.T) {
+	log := NewLogger(zap.NewNop().Sugar())
+	ctx := WithStatementCounter(context.Background())
+
+	log.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	stmtCtx := context.WithValue(ctx, statementKey{}, &statement{sql: "SELECT * FROM `book` WHERE id = ?",
+		vars: []interface{}{1}, dialect: "sqlite"})
+	log.Trace(stmtCtx, time.Now(), func() (string, int64) { return "SELECT * FROM `book` WHERE id = 1", 1 }, nil)
+
+	counter := StatementCounterFromContext(ctx)
+	assert.Equal(t, 2, counter.Count())
+	assert.Equal(t, []string{"SELECT 1", "SELECT * FROM `book` WHERE id = ?"}, counter.Statements())
+	assert.Nil(t, StatementCounterFromContext(context.Background()))
+}
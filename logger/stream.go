@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultStreamBufferSize is the size of the buffer used when it isn't specified in the setting.
+const defaultStreamBufferSize = 1024
+
+// StreamConfig represents the setting for the log stream.
+type StreamConfig struct {
+	Enabled    bool `json:"enabled" yaml:"enabled"`
+	BufferSize int  `json:"buffer_size" yaml:"buffer_size"`
+}
+
+// Event represents a log entry which is sent to the log stream.
+type Event struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Logger  string                 `json:"logger,omitempty"`
+	Message string                 `json:"message"`
+	Caller  string                 `json:"caller,omitempty"`
+	Stack   string                 `json:"stack,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// EventStream is a buffered stream of the log events which is consumed by a client such as gRPC.
+// Logging is never blocked by the stream. When the buffer is full, the event is dropped and counted.
+type EventStream struct {
+	events  chan *Event
+	dropped atomic.Uint64
+}
+
+// newEventStream is constructor. It returns nil when the stream isn't enabled.
+func newEventStream(cfg *StreamConfig) *EventStream {
+	if !cfg.Enabled {
+		return nil
+	}
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = defaultStreamBufferSize
+	}
+	return &EventStream{events: make(chan *Event, size)}
+}
+
+// Events returns the channel to receive the log events.
+func (s *EventStream) Events() <-chan *Event {
+	return s.events
+}
+
+// Dropped returns the number of the events dropped because the buffer was full.
+func (s *EventStream) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// send puts an event into the buffer without blocking.
+func (s *EventStream) send(event *Event) {
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// streamCore is the zapcore.Core which converts the log entries into the events of the stream.
+type streamCore struct {
+	zapcore.LevelEnabler
+	stream *EventStream
+	fields []zapcore.Field
+}
+
+func newStreamCore(stream *EventStream, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &streamCore{LevelEnabler: enabler, stream: stream}
+}
+
+// With adds structured context to the core.
+func (c *streamCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+// Check determines whether the entry should be sent to the stream.
+func (c *streamCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write converts the entry into an event and sends it to the stream.
+func (c *streamCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	c.stream.send(newEvent(entry, c.fields, fields))
+	return nil
+}
+
+// newEvent converts the entry into an event, whose fields are the ones of the core and the ones of the entry.
+func newEvent(entry zapcore.Entry, coreFields []zapcore.Field, fields []zapcore.Field) *Event {
+	event := &Event{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+		Stack:   entry.Stack,
+	}
+	if entry.Caller.Defined {
+		event.Caller = entry.Caller.TrimmedPath()
+	}
+	if len(coreFields)+len(fields) > 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, field := range coreFields {
+			field.AddTo(enc)
+		}
+		for _, field := range fields {
+			field.AddTo(enc)
+		}
+		event.Fields = enc.Fields
+	}
+	return event
+}
+
+// Sync does nothing because the events are consumed asynchronously.
+func (c *streamCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventStream_Delivered(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Stream = StreamConfig{Enabled: true, BufferSize: 10}
+	stream := newEventStream(&cfg.Stream)
+	log, err := build(cfg, stream, nil, &atomic.Uint64{}, &diagnostics{}, nil)
+	require.NoError(t, err)
+
+	received := make(chan *Event)
+	go func() {
+		for event := range stream.Events() {
+			received <- event
+		}
+	}()
+
+	log.With(zap.String("request_id", "abc")).Info("test message", zap.Int("count", 3))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "info", event.Level)
+		assert.Equal(t, "test message", event.Message)
+		assert.Equal(t, "abc", event.Fields["request_id"])
+		assert.Equal(t, int64(3), event.Fields["count"])
+	case <-time.After(time.Second):
+		t.Fatal("the event wasn't delivered")
+	}
+	assert.Equal(t, uint64(0), stream.Dropped())
+}
+
+func TestEventStream_Overflow(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Stream = StreamConfig{Enabled: true, BufferSize: 1}
+	stream := newEventStream(&cfg.Stream)
+	log, err := build(cfg, stream, nil, &atomic.Uint64{}, &diagnostics{}, nil)
+	require.NoError(t, err)
+
+	log.Info("first")
+	log.Info("second")
+	log.Info("third")
+
+	assert.Len(t, stream.Events(), 1)
+	assert.Equal(t, uint64(2), stream.Dropped())
+	assert.Equal(t, "first", (<-stream.Events()).Message)
+}
+
+func TestEventStream_Disabled(t *testing.T) {
+	assert.Nil(t, newEventStream(&StreamConfig{}))
+}
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkConfig represents the setting for the destinations of the logs.
+type SinkConfig struct {
+	// WriteTimeout is the deadline of a write to the files. Zero means no deadline.
+	// The write exceeding it is abandoned and counted as dropped, so the caller isn't blocked by a stalled sink.
+	WriteTimeout config.Duration `json:"write_timeout" yaml:"write_timeout" unit:"ms"`
+	// Backpressure is the policy when a file can't keep up with the logs: block, drop_oldest or drop_newest.
+	// It is block by default. The others queue the logs, and drop one when the queue is full.
+	Backpressure string `json:"backpressure" yaml:"backpressure"`
+	// QueueSize is the number of the logs queued for a file by drop_oldest and drop_newest. It is 1024 by default.
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+	// Outputs overrides Backpressure and QueueSize for the files by their paths, such as block for the audit log
+	// which must not lose an entry, while the application log drops the newest ones.
+	Outputs map[string]SinkOutputConfig `json:"outputs" yaml:"outputs"`
+}
+
+// SinkOutputConfig represents the setting for a file, whose empty fields are the ones of SinkConfig.
+type SinkOutputConfig struct {
+	Backpressure string `json:"backpressure" yaml:"backpressure"`
+	QueueSize    int    `json:"queue_size" yaml:"queue_size"`
+}
+
+// output returns the backpressure policy and the size of the queue of the file of the path.
+func (c *SinkConfig) output(path string) SinkOutputConfig {
+	output := SinkOutputConfig{Backpressure: c.Backpressure, QueueSize: c.QueueSize}
+	if override, ok := c.Outputs[path]; ok {
+		if override.Backpressure != "" {
+			output.Backpressure = override.Backpressure
+		}
+		if override.QueueSize != 0 {
+			output.QueueSize = override.QueueSize
+		}
+	}
+	return output
+}
+
+// writeJob is a write or a sync requested to timeoutWriter.
+type writeJob struct {
+	data []byte
+	sync bool
+	done chan error
+}
+
+// timeoutWriter is the zapcore.WriteSyncer which abandons the writes exceeding the deadline.
+// The writes are done one by one by a dedicated goroutine, so they keep their order.
+type timeoutWriter struct {
+	writer  zapcore.WriteSyncer
+	timeout time.Duration
+	jobs    chan writeJob
+	dropped *atomic.Uint64
+	// stop stops the goroutine, and done is closed when it has returned.
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newTimeoutWriter wraps the writer. The abandoned writes are counted to dropped.
+func newTimeoutWriter(writer zapcore.WriteSyncer, timeout time.Duration, dropped *atomic.Uint64) *timeoutWriter {
+	w := &timeoutWriter{writer: writer, timeout: timeout, jobs: make(chan writeJob), dropped: dropped,
+		stop: make(chan struct{}), done: make(chan struct{})}
+	go w.run()
+	return w
+}
+
+// run does the requested writes and syncs until the writer is closed.
+func (w *timeoutWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.stop:
+			return
+		case job := <-w.jobs:
+			if job.sync {
+				job.done <- w.writer.Sync()
+				continue
+			}
+			_, err := w.writer.Write(job.data)
+			job.done <- err
+		}
+	}
+}
+
+// close stops the goroutine. It doesn't wait for the write in progress, which may be stalled.
+// The writes after it are abandoned by the deadline.
+func (w *timeoutWriter) close() {
+	close(w.stop)
+}
+
+// Write writes the data, or abandons it when the deadline is exceeded.
+func (w *timeoutWriter) Write(p []byte) (int, error) {
+	// the buffer is copied because it is reused by the caller after returning.
+	if err := w.do(writeJob{data: append([]byte(nil), p...)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the writer, or gives up when the deadline is exceeded.
+func (w *timeoutWriter) Sync() error {
+	return w.do(writeJob{sync: true})
+}
+
+func (w *timeoutWriter) do(job writeJob) error {
+	job.done = make(chan error, 1)
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+
+	select {
+	case w.jobs <- job:
+	case <-timer.C:
+		w.drop(job)
+		return nil
+	}
+	select {
+	case err := <-job.done:
+		return err
+	case <-timer.C:
+		w.drop(job)
+		return nil
+	}
+}
+
+func (w *timeoutWriter) drop(job writeJob) {
+	if !job.sync {
+		countDropped(w.dropped)
+	}
+}
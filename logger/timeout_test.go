@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter is the writer which blocks until it is released.
+type slowWriter struct {
+	mutex   sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) Sync() error {
+	return nil
+}
+
+func TestTimeoutWriter_NotBlockedBySlowSink(t *testing.T) {
+	slow := &slowWriter{release: make(chan struct{})}
+	defer close(slow.release)
+	dropped := &atomic.Uint64{}
+	writer := newTimeoutWriter(slow, 50*time.Millisecond, dropped)
+
+	start := time.Now()
+	n, err := writer.Write([]byte("first\n"))
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	assert.Equal(t, uint64(1), dropped.Load())
+
+	// the next write is also abandoned while the sink is stalled.
+	_, _ = writer.Write([]byte("second\n"))
+	assert.Equal(t, uint64(2), dropped.Load())
+}
+
+func TestTimeoutWriter_Write(t *testing.T) {
+	slow := &slowWriter{release: make(chan struct{})}
+	close(slow.release)
+	dropped := &atomic.Uint64{}
+	writer := newTimeoutWriter(slow, time.Second, dropped)
+
+	_, err := writer.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Sync())
+
+	slow.mutex.Lock()
+	defer slow.mutex.Unlock()
+	assert.Equal(t, "first\nsecond\n", slow.buf.String())
+	assert.Equal(t, uint64(0), dropped.Load())
+}
+
+func TestTimeoutWriter_Close(t *testing.T) {
+	slow := &slowWriter{release: make(chan struct{})}
+	close(slow.release)
+	writer := newTimeoutWriter(slow, time.Second, &atomic.Uint64{})
+
+	writer.close()
+
+	select {
+	case <-writer.done:
+	case <-time.After(time.Second):
+		t.Fatal("the goroutine of the writer didn't exit")
+	}
+}
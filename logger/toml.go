@@ -0,0 +1,15 @@
+package logger
+
+import "encoding/json"
+
+// UnmarshalTOML decodes the setting written to the toml file.
+// zap.Config has only json and yaml tags, and zap.AtomicLevel can't be decoded from toml,
+// so the decoded toml is passed to the json decoder, which is driven by the json tags.
+func (c *Config) UnmarshalTOML(data interface{}) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	type plain Config
+	return json.Unmarshal(bytes, (*plain)(c))
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReadConfigFile_AllFormats(t *testing.T) {
+	configs := map[string]*Config{}
+	for _, format := range []string{"yml", "json", "toml"} {
+		cfg := &Config{}
+		_, err := config.ReadConfigFile(os.DirFS("testdata/"+format), "zaplogger.test", cfg)
+		require.NoError(t, err)
+
+		// the encoders are functions, which can't be compared.
+		assert.NotNil(t, cfg.ZapConfig.EncoderConfig.EncodeLevel)
+		cfg.ZapConfig.EncoderConfig.EncodeLevel = nil
+		configs[format] = cfg
+	}
+
+	assert.Equal(t, zapcore.InfoLevel, configs["toml"].ZapConfig.Level.Level())
+	// the bare numbers are read in the legacy units.
+	assert.Equal(t, config.ByteSize(3<<20), configs["toml"].LogRotate.MaxSize)
+	assert.Equal(t, config.Duration(7*24*time.Hour), configs["toml"].LogRotate.MaxAge)
+	assert.Equal(t, 500*time.Millisecond, configs["toml"].SQL.slowThreshold())
+	assert.Equal(t, configs["yml"], configs["json"])
+	assert.Equal(t, configs["yml"], configs["toml"])
+}
+
+func TestNew_SameLoggerFromAllFormats(t *testing.T) {
+	outputs := map[string]string{}
+	for _, format := range []string{"yml", "json", "toml"} {
+		cfg := &Config{}
+		_, err := config.ReadConfigFile(os.DirFS("testdata/"+format), "zaplogger.test", cfg)
+		require.NoError(t, err)
+		// the time differs between the loggers, so it isn't written.
+		cfg.ZapConfig.EncoderConfig.TimeKey = ""
+		path := filepath.Join(t.TempDir(), "app.log")
+		cfg.ZapConfig.OutputPaths = []string{path}
+
+		log, err := New(cfg)
+		require.NoError(t, err)
+		log.GetZapLogger().Debugw("hidden by the level")
+		log.GetZapLogger().Infow("written", "password", "secret", "user", "test")
+		log.GetZapLogger().Warn("warned")
+		require.NoError(t, log.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		outputs[format] = string(data)
+	}
+
+	assert.Contains(t, outputs["yml"], `"Level":"INFO"`)
+	assert.NotContains(t, outputs["yml"], "hidden by the level")
+	assert.NotContains(t, outputs["yml"], "secret")
+	assert.Equal(t, outputs["yml"], outputs["json"])
+	assert.Equal(t, outputs["yml"], outputs["toml"])
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// TransactionIDKey is the name of the log field which has the ID of the transaction.
+	TransactionIDKey = "tx_id"
+	// TransactionBegin, TransactionCommit and TransactionRollback are the events of a transaction.
+	TransactionBegin    = "BEGIN"
+	TransactionCommit   = "COMMIT"
+	TransactionRollback = "ROLLBACK"
+)
+
+// transactionIDKey is the context key of the transaction ID.
+type transactionIDKey struct{}
+
+// NewTransactionID generates a short random ID of a transaction.
+func NewTransactionID() string {
+	var id [6]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// SavepointID returns the ID of the n-th savepoint in the transaction or the savepoint which has the given ID,
+// such as abc.1 for the first savepoint of the transaction abc.
+func SavepointID(parent string, n int64) string {
+	return fmt.Sprintf("%s.%d", parent, n)
+}
+
+// WithTransactionID returns the context which has the given transaction ID.
+func WithTransactionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, transactionIDKey{}, id)
+}
+
+// TransactionIDFromContext returns the transaction ID of the context. It returns an empty string when there is none.
+func TransactionIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(transactionIDKey{}).(string)
+	return id
+}
+
+// LogTransaction writes the event of the transaction, such as BEGIN, at the debug level
+// with the transaction ID of the context, which the sql logs in the transaction have too.
+func LogTransaction(ctx context.Context, sugar *zap.SugaredLogger, event string) {
+	WithContextFields(ctx, sugar).Debugf(sqlFormat, event)
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+)
+
+// Validate checks the setting of the logger, and returns every problem found in it.
+// It is called before the logger is built, so the contradictory setting fails at startup with a clear message.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.ZapConfig.Level == (zap.AtomicLevel{}) {
+		errs = append(errs, config.NewFieldError("zap_config.level", "must not be empty"))
+	}
+	if !slices.Contains([]string{"console", "json", "pretty"}, c.ZapConfig.Encoding) {
+		errs = append(errs, config.NewFieldError("zap_config.encoding", "must be console, json or pretty"))
+	}
+	if len(c.ZapConfig.OutputPaths) == 0 && !c.Stream.Enabled {
+		errs = append(errs, config.NewFieldError("zap_config.outputPaths", "must not be empty unless the stream is enabled"))
+	}
+	if slices.Contains(c.ZapConfig.OutputPaths, "") {
+		errs = append(errs, config.NewFieldError("zap_config.outputPaths", "must not contain an empty path"))
+	}
+	if slices.Contains(c.ZapConfig.ErrorOutputPaths, "") {
+		errs = append(errs, config.NewFieldError("zap_config.errorOutputPaths", "must not contain an empty path"))
+	}
+	if _, ok := schemas[c.Schema]; c.Schema != "" && !ok {
+		errs = append(errs, config.NewFieldError("schema", "must be gcp or ecs"))
+	}
+	if _, ok := durationEncoders[c.DurationEncoding]; c.DurationEncoding != "" && !ok {
+		errs = append(errs, config.NewFieldError("duration_encoding", "must be string, seconds, millis or nanos"))
+	}
+	if c.Schema == "" && c.ZapConfig.EncoderConfig.MessageKey == "" {
+		errs = append(errs, config.NewFieldError("zap_config.encoderConfig.messageKey",
+			"must not be empty unless the schema is given"))
+	}
+	if c.writesFile() && c.LogRotate.MaxSize <= 0 {
+		errs = append(errs, config.NewFieldError("log_rotate.maxsize", "must be greater than 0 when logging to a file"))
+	}
+	if c.LogRotate.MaxAge < 0 {
+		errs = append(errs, config.NewFieldError("log_rotate.maxage", "must not be negative"))
+	}
+	if c.LogRotate.MaxBackups < 0 {
+		errs = append(errs, config.NewFieldError("log_rotate.maxbackups", "must not be negative"))
+	}
+	if c.SQL.MaxFormattedValues < 0 {
+		errs = append(errs, config.NewFieldError("sql.max_formatted_values", "must not be negative"))
+	}
+	if !slices.Contains([]string{"", QuoteStandard, QuoteMySQL, QuotePostgres}, c.SQL.QuoteStyle) {
+		errs = append(errs, config.NewFieldError("sql.quote_style", "must be standard, mysql or postgres"))
+	}
+	if c.SQL.MaxStatementsPerRequest < 0 {
+		errs = append(errs, config.NewFieldError("sql.max_statements_per_request", "must not be negative"))
+	}
+	if c.Audit.Journal.MaxSize < 0 {
+		errs = append(errs, config.NewFieldError("audit.journal.max_size", "must not be negative"))
+	}
+	if c.Audit.Journal.Path != "" && len(c.Audit.OutputPaths) == 0 {
+		errs = append(errs, config.NewFieldError("audit.journal.path", "must be empty unless the audit log is enabled"))
+	}
+	if c.Audit.FlushInterval < 0 {
+		errs = append(errs, config.NewFieldError("audit.flush_interval", "must not be negative"))
+	}
+	if c.Audit.FlushInterval > 0 && c.Audit.Journal.Path != "" {
+		errs = append(errs, config.NewFieldError("audit.flush_interval", "must be zero when the journal is enabled"))
+	}
+	if c.RecentBuffer.Size < 0 {
+		errs = append(errs, config.NewFieldError("recent_buffer.size", "must not be negative"))
+	}
+	if c.Stream.BufferSize < 0 {
+		errs = append(errs, config.NewFieldError("stream.buffer_size", "must not be negative"))
+	}
+	if c.Sink.WriteTimeout < 0 {
+		errs = append(errs, config.NewFieldError("sink.write_timeout", "must not be negative"))
+	}
+	if !slices.Contains([]string{"", BackpressureBlock, BackpressureDropOldest, BackpressureDropNewest},
+		c.Sink.Backpressure) {
+		errs = append(errs, config.NewFieldError("sink.backpressure", "must be block, drop_oldest or drop_newest"))
+	}
+	if c.Sink.QueueSize < 0 {
+		errs = append(errs, config.NewFieldError("sink.queue_size", "must not be negative"))
+	}
+	for path, output := range c.Sink.Outputs {
+		if !slices.Contains([]string{"", BackpressureBlock, BackpressureDropOldest, BackpressureDropNewest},
+			output.Backpressure) {
+			errs = append(errs, config.NewFieldError("sink.outputs."+path+".backpressure",
+				"must be block, drop_oldest or drop_newest"))
+		}
+		if output.QueueSize < 0 {
+			errs = append(errs, config.NewFieldError("sink.outputs."+path+".queue_size", "must not be negative"))
+		}
+	}
+	if c.RateLimit.Threshold < 0 {
+		errs = append(errs, config.NewFieldError("rate_limit.threshold", "must not be negative"))
+	}
+	if c.RateLimit.Window < 0 {
+		errs = append(errs, config.NewFieldError("rate_limit.window", "must not be negative"))
+	}
+	if !slices.Contains([]string{"", RequestIDFormatUUID, RequestIDFormatSortable}, c.RequestID.Format) {
+		errs = append(errs, config.NewFieldError("request_id.format", "must be uuid or sortable"))
+	}
+	return errors.Join(errs...)
+}
+
+// overlappingPaths returns the files which are in both outputPaths and errorOutputPaths.
+func (c *Config) overlappingPaths() []string {
+	var paths []string
+	for _, path := range c.ZapConfig.ErrorOutputPaths {
+		if path != "stdout" && path != "stderr" && slices.Contains(c.ZapConfig.OutputPaths, path) &&
+			!slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// writesFile returns true when the logger writes the logs to any file.
+func (c *Config) writesFile() bool {
+	paths := append(append([]string{}, c.ZapConfig.OutputPaths...), c.ZapConfig.ErrorOutputPaths...)
+	for _, path := range paths {
+		if path != "stdout" && path != "stderr" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"go.uber.org/zap"
+)
+
+func TestValidate_Success(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.OutputPaths = []string{"stdout"}
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_MultipleErrors(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ZapConfig.Level = zap.AtomicLevel{}
+	cfg.ZapConfig.Encoding = "xml"
+	cfg.ZapConfig.OutputPaths = []string{"./application.log"}
+
+	err := cfg.Validate()
+
+	assert.ErrorContains(t, err, "zap_config.level")
+	assert.ErrorContains(t, err, "zap_config.encoding")
+	assert.ErrorContains(t, err, "log_rotate.maxsize")
+}
+
+func TestValidate_InvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   string
+	}{
+		{name: "no output", modify: func(cfg *Config) { cfg.ZapConfig.OutputPaths = nil },
+			want: "zap_config.outputPaths: must not be empty"},
+		{name: "empty output", modify: func(cfg *Config) { cfg.ZapConfig.OutputPaths = []string{"stdout", ""} },
+			want: "zap_config.outputPaths: must not contain an empty path"},
+		{name: "empty error output", modify: func(cfg *Config) { cfg.ZapConfig.ErrorOutputPaths = []string{""} },
+			want: "zap_config.errorOutputPaths: must not contain an empty path"},
+		{name: "unknown encoding", modify: func(cfg *Config) { cfg.ZapConfig.Encoding = "xml" },
+			want: "zap_config.encoding"},
+		{name: "rotation without size", modify: func(cfg *Config) { cfg.ZapConfig.ErrorOutputPaths = []string{"error.log"} },
+			want: "log_rotate.maxsize: must be greater than 0"},
+		{name: "negative backups", modify: func(cfg *Config) { cfg.LogRotate.MaxBackups = -1 },
+			want: "log_rotate.maxbackups"},
+		{name: "unknown request id format", modify: func(cfg *Config) { cfg.RequestID.Format = "short" },
+			want: "request_id.format"},
+		{name: "unknown backpressure", modify: func(cfg *Config) { cfg.Sink.Backpressure = "drop" },
+			want: "sink.backpressure: must be block, drop_oldest or drop_newest"},
+		{name: "negative queue size", modify: func(cfg *Config) { cfg.Sink.QueueSize = -1 },
+			want: "sink.queue_size"},
+		{name: "unknown backpressure of output", modify: func(cfg *Config) {
+			cfg.Sink.Outputs = map[string]SinkOutputConfig{"audit.log": {Backpressure: "drop"}}
+		}, want: "sink.outputs.audit.log.backpressure: must be block, drop_oldest or drop_newest"},
+		{name: "audit batches with journal", modify: func(cfg *Config) {
+			cfg.Audit.OutputPaths = []string{"audit.log"}
+			cfg.Audit.Journal.Path = "audit.journal"
+			cfg.Audit.FlushInterval = config.Duration(time.Second)
+		}, want: "audit.flush_interval: must be zero when the journal is enabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.ZapConfig.OutputPaths = []string{"stdout"}
+			tt.modify(cfg)
+
+			assert.ErrorContains(t, cfg.Validate(), tt.want)
+		})
+	}
+}
+
+func TestValidate_OnlyStream(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Stream.Enabled = true
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestBuild_InvalidConfig(t *testing.T) {
+	cfg := createTestConfig()
+
+	_, err := build(cfg, nil, nil, &atomic.Uint64{}, &diagnostics{}, nil)
+
+	assert.ErrorContains(t, err, "invalid setting of the logger")
+	assert.ErrorContains(t, err, "zap_config.outputPaths")
+}
+
+func TestBuild_OverlappingPaths(t *testing.T) {
+	cfg := createTestConfig()
+	path := filepath.Join(t.TempDir(), "application.log")
+	cfg.ZapConfig.OutputPaths = []string{path, "stderr", path}
+	cfg.ZapConfig.ErrorOutputPaths = []string{"stderr", path}
+	cfg.LogRotate.MaxSize = 10 * megabyte
+	diag := &diagnostics{}
+
+	log, err := build(cfg, nil, nil, &atomic.Uint64{}, diag, nil)
+	require.NoError(t, err)
+	log.Error("failed")
+	_ = log.Sync()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), `"msg":"failed"`))
+	assert.Equal(t, 1, strings.Count(string(content), "is in both zap_config.outputPaths and zap_config.errorOutputPaths"))
+	assert.Len(t, diag.report(), 2)
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// workerPanicMessage is the message of the error log of the panic of a worker.
+	workerPanicMessage = "Recovered the panic of the background job"
+	// JobKey is the name of the log field which has the name of the background job.
+	JobKey = "job"
+)
+
+// SafeGo runs fn in a goroutine as the background job of the name, such as a seeder or a cache refresher.
+// When fn panics, the panic is recovered and logged at the error level with the name of the job and the stack,
+// instead of crashing the application, so the job which died can be found in the logs.
+func (log *logger) SafeGo(name string, fn func()) {
+	go func() {
+		defer log.recoverJob(name)
+		fn()
+	}()
+}
+
+// recoverJob recovers the panic of the background job and logs it. It must be deferred by the job.
+func (log *logger) recoverJob(name string) {
+	if r := recover(); r != nil {
+		log.Zap.Desugar().Error(workerPanicMessage, zap.String(JobKey, name), zap.String("panic", fmt.Sprint(r)),
+			zap.Stack("stack"))
+	}
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSafeGo_Panic(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+
+	log.SafeGo("cache-refresher", func() { panic("refresh failed") })
+
+	assert.Eventually(t, func() bool { return logs.Len() == 1 }, time.Second, time.Millisecond)
+	entry := logs.All()[0]
+	assert.Equal(t, zap.ErrorLevel, entry.Level)
+	assert.Equal(t, workerPanicMessage, entry.Message)
+	assert.Equal(t, "cache-refresher", entry.ContextMap()[JobKey])
+	assert.Equal(t, "refresh failed", entry.ContextMap()["panic"])
+	assert.Contains(t, entry.ContextMap()["stack"], "logger.TestSafeGo_Panic")
+}
+
+func TestSafeGo_Done(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := NewLogger(zap.New(core).Sugar())
+	done := make(chan struct{})
+
+	log.SafeGo("seeder", func() { close(done) })
+
+	<-done
+	assert.Zero(t, logs.Len())
+}
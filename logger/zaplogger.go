@@ -2,23 +2,71 @@ package logger
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-func build(cfg *Config) (*zap.Logger, error) {
-	var zapCfg = cfg.ZapConfig
-	enc, _ := newEncoder(zapCfg)
-	writer, errWriter := openWriters(cfg)
+// overlapWarning explains the file which is in both outputPaths and errorOutputPaths.
+const overlapWarning = "%s is in both zap_config.outputPaths and zap_config.errorOutputPaths. " +
+	"It is opened once, and errorOutputPaths receives only the internal errors of zap, not the error logs"
+
+const (
+	// megabyte is the unit of the max size of lumberjack.
+	megabyte = config.ByteSize(1 << 20)
+	// day is the unit of the max age of lumberjack.
+	day = 24 * time.Hour
+)
 
-	if zapCfg.Level == (zap.AtomicLevel{}) {
-		return nil, errors.New("missing Level")
+func build(cfg *Config, stream *EventStream, recent *recentBuffer, dropped *atomic.Uint64,
+	diag *diagnostics, done <-chan struct{}) (*zap.Logger, error) {
+	if err := errors.Join(cfg.Validate(), cfg.normalizeOutputPaths()); err != nil {
+		return nil, fmt.Errorf("invalid setting of the logger:\n%w", err)
 	}
+	fields, err := cfg.processFields()
+	if err != nil {
+		return nil, err
+	}
+	checked, probes, err := preflight(cfg)
+	diag.probes = probes
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the log files:\n%w", err)
+	}
+	var zapCfg = checked.ZapConfig
+	zapCfg.EncoderConfig = cfg.encoderConfig()
+	enc, _ := newEncoder(zapCfg)
+	writer, errWriter := openWriters(checked, dropped, diag)
 
-	log := zap.New(zapcore.NewCore(enc, writer, zapCfg.Level), buildOptions(zapCfg, errWriter)...)
+	core := zapcore.NewCore(enc, writer, zapCfg.Level)
+	if stream != nil {
+		core = zapcore.NewTee(core, newStreamCore(stream, zapCfg.Level))
+	}
+	if recent != nil {
+		core = zapcore.NewTee(core, newRecentCore(recent, cfg.RecentBuffer.MinLevel))
+	}
+	core = newRedactCore(core, cfg.Redact.Keys)
+	core = newRateLimitCore(core, &cfg.RateLimit, done)
+	if cfg.CallerMinLevel != nil && !zapCfg.DisableCaller {
+		core = newCallerCore(core, *cfg.CallerMinLevel)
+		zapCfg.DisableCaller = true
+	}
+	opts := append(buildOptions(zapCfg, diag.watchErrorOutput(errWriter)), zap.Fields(fields...))
+	log := zap.New(core, opts...)
+	for _, path := range cfg.overlappingPaths() {
+		log.Warn(fmt.Sprintf(overlapWarning, path))
+	}
+	for _, probe := range probes {
+		if probe.Error != "" {
+			log.Warn(fmt.Sprintf(fallbackWarning, probe.Path, probe.Error))
+		}
+	}
 	return log, nil
 }
 
@@ -28,45 +76,97 @@ func newEncoder(cfg zap.Config) (zapcore.Encoder, error) {
 		return zapcore.NewConsoleEncoder(cfg.EncoderConfig), nil
 	case "json":
 		return zapcore.NewJSONEncoder(cfg.EncoderConfig), nil
+	case "pretty":
+		// the files and the pipes get the plain console output, because the colors break them.
+		if isTerminal(cfg.OutputPaths) {
+			return newPrettyEncoder(), nil
+		}
+		return zapcore.NewConsoleEncoder(cfg.EncoderConfig), nil
 	}
 	return nil, errors.New("failed to set encoder")
 }
 
-func openWriters(cfg *Config) (zapcore.WriteSyncer, zapcore.WriteSyncer) {
-	writer := open(cfg.ZapConfig.OutputPaths, &cfg.LogRotate)
-	errWriter := open(cfg.ZapConfig.ErrorOutputPaths, &cfg.LogRotate)
+// openWriters opens the destinations of the logs and the internal errors of zap.
+// A file which is in both of them is opened once, because the two rotations of the same file break each other.
+func openWriters(cfg *Config, dropped *atomic.Uint64, diag *diagnostics) (zapcore.WriteSyncer, zapcore.WriteSyncer) {
+	opened := map[string]zapcore.WriteSyncer{}
+	writer := open(cfg.ZapConfig.OutputPaths, cfg, dropped, diag, opened)
+	errWriter := open(cfg.ZapConfig.ErrorOutputPaths, cfg, dropped, diag, opened)
 	return writer, errWriter
 }
 
-func open(paths []string, rotateCfg *lumberjack.Logger) zapcore.WriteSyncer {
+// open opens the paths which aren't opened yet, and combines them with the opened ones.
+// The path listed twice is written once.
+func open(paths []string, cfg *Config, dropped *atomic.Uint64, diag *diagnostics,
+	opened map[string]zapcore.WriteSyncer) zapcore.WriteSyncer {
 	writers := make([]zapcore.WriteSyncer, 0, len(paths))
+	listed := map[string]bool{}
 	for _, path := range paths {
-		writer := newWriter(path, rotateCfg)
+		if listed[path] {
+			continue
+		}
+		listed[path] = true
+		writer, ok := opened[path]
+		if !ok {
+			writer = wrapSink(diag.record(newWriter(path, &cfg.LogRotate), path), path, &cfg.Sink, dropped, diag)
+			opened[path] = writer
+		}
 		writers = append(writers, writer)
 	}
 	writer := zap.CombineWriteSyncers(writers...)
 	return writer
 }
 
-func newWriter(path string, rotateCfg *lumberjack.Logger) zapcore.WriteSyncer {
+// wrapSink applies the deadline and the backpressure policy of the path to its writer.
+// stdout and stderr are exempt from them. Their goroutines are stopped when diag is closed.
+func wrapSink(writer zapcore.WriteSyncer, path string, cfg *SinkConfig, dropped *atomic.Uint64,
+	diag *diagnostics) zapcore.WriteSyncer {
+	if path == "stdout" || path == "stderr" {
+		return writer
+	}
+	if cfg.WriteTimeout > 0 {
+		timeout := newTimeoutWriter(writer, cfg.WriteTimeout.Std(), dropped)
+		diag.onClose(timeout.close)
+		writer = timeout
+	}
+	if output := cfg.output(path); output.Backpressure != "" && output.Backpressure != BackpressureBlock {
+		queue := newQueueWriter(writer, output.Backpressure, output.QueueSize, dropped)
+		diag.onClose(queue.close)
+		writer = queue
+	}
+	return writer
+}
+
+func newWriter(path string, rotateCfg *RotateConfig) zapcore.WriteSyncer {
 	switch path {
 	case "stdout":
 		return os.Stdout
 	case "stderr":
 		return os.Stderr
 	}
-	sink := zapcore.AddSync(
+	sink := &fileWriter{
 		&lumberjack.Logger{
 			Filename:   path,
-			MaxSize:    rotateCfg.MaxSize,
+			MaxSize:    rotateCfg.maxSizeMegabytes(),
 			MaxBackups: rotateCfg.MaxBackups,
-			MaxAge:     rotateCfg.MaxAge,
+			MaxAge:     rotateCfg.maxAgeDays(),
+			LocalTime:  rotateCfg.LocalTime,
 			Compress:   rotateCfg.Compress,
 		},
-	)
+	}
 	return sink
 }
 
+// fileWriter is the zapcore.WriteSyncer of a rotated log file, which can be closed.
+type fileWriter struct {
+	*lumberjack.Logger
+}
+
+// Sync does nothing, because lumberjack writes the file without buffering.
+func (w *fileWriter) Sync() error {
+	return nil
+}
+
 func buildOptions(cfg zap.Config, errWriter zapcore.WriteSyncer) []zap.Option {
 	opts := []zap.Option{zap.ErrorOutput(errWriter)}
 	if cfg.Development {
@@ -86,3 +186,13 @@ func buildOptions(cfg zap.Config, errWriter zapcore.WriteSyncer) []zap.Option {
 	}
 	return opts
 }
+
+// maxSizeMegabytes returns the max size in megabytes for lumberjack, rounding up the fraction.
+func (c *RotateConfig) maxSizeMegabytes() int {
+	return int((c.MaxSize + megabyte - 1) / megabyte)
+}
+
+// maxAgeDays returns the max age in days for lumberjack, rounding up the fraction.
+func (c *RotateConfig) maxAgeDays() int {
+	return int((c.MaxAge.Std() + day - 1) / day)
+}
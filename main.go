@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -10,23 +15,29 @@ import (
 	"github.com/ybkuroki/go-webapp-sample/logger"
 	"github.com/ybkuroki/go-webapp-sample/middleware"
 	"github.com/ybkuroki/go-webapp-sample/migration"
+	"github.com/ybkuroki/go-webapp-sample/model"
 	"github.com/ybkuroki/go-webapp-sample/repository"
+	"github.com/ybkuroki/go-webapp-sample/resources"
 	"github.com/ybkuroki/go-webapp-sample/router"
 	"github.com/ybkuroki/go-webapp-sample/session"
 )
 
-//go:embed resources/config/application.*.yml
-var yamlFile embed.FS
-
-//go:embed resources/config/zaplogger.*.yml
-var zapYamlFile embed.FS
-
 //go:embed resources/public/*
 var staticFile embed.FS
 
 //go:embed resources/config/messages.properties
 var propsFile embed.FS
 
+// The build of the executable, which is given by -ldflags such as -X main.version=1.5.1.
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// shutdownTimeout is the deadline of stopping the server and closing the repository after SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
 // @title go-webapp-sample API
 // @version 1.5.1
 // @description This is API specification for go-webapp-sample project.
@@ -39,9 +50,47 @@ var propsFile embed.FS
 func main() {
 	e := echo.New()
 
-	conf, env := config.LoadAppConfig(yamlFile)
-	logger := logger.InitLogger(env, zapYamlFile)
-	logger.GetZapLogger().Infof("Loaded this configuration : application." + env + ".yml")
+	conf, env := config.LoadAppConfig(resources.ConfigFiles)
+	buildInfo := logger.NewBuildInfo(version, commit, buildDate)
+	buildInfo.Env = env
+	logger := logger.InitLogger(env, resources.ConfigFiles)
+	logger.LogStartupInfo(buildInfo)
+	if errs := conf.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			logger.GetZapLogger().Errorf("Invalid configuration: %s", err)
+		}
+		os.Exit(config.ErrExitStatus)
+	}
+	if conf.Security.IDEncoding {
+		if err := model.SetIDEncodingKey(conf.Security.IDEncodingKey); err != nil {
+			logger.GetZapLogger().Errorf("Failed to enable the encoding of the IDs: %s", err)
+			os.Exit(config.ErrExitStatus)
+		}
+	}
+	logger.GetZapLogger().Infof("Using the configuration directory : %s", config.GetConfigDir())
+	logger.GetZapLogger().Infof("Loaded this configuration : application." + env)
+	logger.GetZapLogger().Infof("Effective configuration :\n%s", config.Dump())
+
+	stopWatching, err := config.Watch(func(report *config.ReloadReport, err error) {
+		if err != nil {
+			logger.GetZapLogger().Errorf("Failed to reload the configuration, the running one is kept: %s", err)
+			return
+		}
+		for _, path := range report.Applied {
+			logger.GetZapLogger().Infof("Reloaded the configuration : %s", path)
+		}
+		for _, path := range report.RestartRequired {
+			logger.GetZapLogger().Warnf("Restart is required to apply the configuration : %s", path)
+		}
+		for path, reason := range report.Rejected {
+			logger.GetZapLogger().Warnf("Restart is required to apply the configuration : %s, because %s", path, reason)
+		}
+	})
+	if err != nil {
+		logger.GetZapLogger().Warnf("Failed to watch the configuration files: %s", err)
+	} else {
+		defer func() { _ = stopWatching() }()
+	}
 
 	messages := config.LoadMessagesConfig(propsFile)
 	logger.GetZapLogger().Infof("Loaded messages.properties")
@@ -51,6 +100,20 @@ func main() {
 	container := container.NewContainer(rep, sess, conf, messages, logger, env)
 
 	migration.CreateDatabase(container)
+	if err := migration.MigrateDatabase(container); err != nil {
+		if env == config.PRD {
+			logger.GetZapLogger().Errorf("Failed to migrate the database: %s", err)
+			os.Exit(config.ErrExitStatus)
+		}
+		logger.GetZapLogger().Warnf("Failed to migrate the database, the requests using it may fail: %s", err)
+	}
+	if err := rep.Verify(context.Background(), migration.Tables()...); err != nil {
+		if env == config.PRD {
+			logger.GetZapLogger().Errorf("Failed to verify the database: %s", err)
+			os.Exit(config.ErrExitStatus)
+		}
+		logger.GetZapLogger().Warnf("Failed to verify the database, the requests using it will fail: %s", err)
+	}
 	migration.InitMasterData(container)
 
 	router.Init(e, container)
@@ -58,9 +121,23 @@ func main() {
 	middleware.InitSessionMiddleware(e, container)
 	middleware.StaticContentsMiddleware(e, container, staticFile)
 
-	if err := e.Start(":8080"); err != nil {
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- e.Start(":8080") }()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serverErr:
 		logger.GetZapLogger().Errorf(err.Error())
+	case <-ctx.Done():
+		logger.GetZapLogger().Infof("Shutting down the application")
 	}
 
-	defer rep.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		logger.GetZapLogger().Errorf("Failed to stop the server: %s", err)
+	}
+	if err := container.Shutdown(ctx); err != nil {
+		logger.GetZapLogger().Errorf("Failed to shut down the application: %s", err)
+	}
 }
@@ -11,11 +11,19 @@ import (
 	"github.com/labstack/echo/v4"
 	echomd "github.com/labstack/echo/v4/middleware"
 	"github.com/valyala/fasttemplate"
+	"github.com/ybkuroki/go-webapp-sample/clock"
 	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/logger"
 )
 
 // InitLoggerMiddleware initialize a middleware for logger.
 func InitLoggerMiddleware(e *echo.Echo, container container.Container) {
+	e.Use(RequestIDMiddleware())
+	e.Use(RouteMiddleware())
+	e.Use(StatementCountMiddleware(container))
+	if container.GetConfig().Log.AccessLog {
+		e.Use(AccessLogMiddleware(container))
+	}
 	e.Use(RequestLoggerMiddleware(container))
 	e.Use(ActionLoggerMiddleware(container))
 }
@@ -30,6 +38,80 @@ func InitSessionMiddleware(e *echo.Echo, container container.Container) {
 	}
 }
 
+// maxRequestIDLength is the maximum length of the request ID accepted from the header.
+const maxRequestIDLength = 128
+
+// requestIDPattern is the characters allowed in the request ID accepted from the header,
+// so that the ID from the client can't break the log lines.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// RequestIDMiddleware is middleware for giving the request ID to each request.
+// It takes the ID from the X-Request-ID header when it is valid, or generates a new one,
+// and writes it back to the response header. The ID is set to the context of the request,
+// so the access log and the sql logs of the request have it.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+			if id := req.Header.Get(echo.HeaderXRequestID); len(id) <= maxRequestIDLength && requestIDPattern.MatchString(id) {
+				ctx = logger.WithRequestID(ctx, id)
+			}
+			ctx, id := logger.EnsureRequestID(ctx)
+			c.SetRequest(req.WithContext(ctx))
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			return next(c)
+		}
+	}
+}
+
+// RouteMiddleware is middleware for setting the route of the request, such as GET /api/categories,
+// to the context of the request, so the sqls of the request can be attributed to it.
+func RouteMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.WithRoute(req.Context(), req.Method+" "+c.Path())))
+			return next(c)
+		}
+	}
+}
+
+// StatementCountMiddleware is middleware for counting the sqls issued by each request,
+// and warning the request which issued more of them than sql.max_statements_per_request.
+func StatementCountMiddleware(container container.Container) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := logger.WithStatementCounter(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+			defer container.GetLogger().LogStatementCount(ctx)
+			return next(c)
+		}
+	}
+}
+
+// AccessLogMiddleware is middleware for writing the structured access log of each request after it is handled.
+func AccessLogMiddleware(container container.Container) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			begin := clock.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			req := c.Request()
+			container.GetLogger().Access(logger.AccessFields{
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Status:    c.Response().Status,
+				Duration:  clock.Since(begin),
+				Bytes:     c.Response().Size,
+				RequestID: logger.RequestIDFromContext(req.Context()),
+			})
+			return nil
+		}
+	}
+}
+
 // RequestLoggerMiddleware is middleware for logging the contents of requests.
 func RequestLoggerMiddleware(container container.Container) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -56,11 +138,13 @@ func RequestLoggerMiddleware(container container.Container) echo.MiddlewareFunc
 					return w.Write([]byte(req.Method))
 				case "status":
 					return w.Write([]byte(strconv.Itoa(res.Status)))
+				case "request_id":
+					return w.Write([]byte(logger.RequestIDFromContext(req.Context())))
 				default:
 					return w.Write([]byte(""))
 				}
 			})
-			container.GetLogger().GetZapLogger().Infof(logstr)
+			logger.WithContextFields(c.Request().Context(), container.GetLogger().GetZapLogger()).Infof(logstr)
 			return nil
 		}
 	}
@@ -104,12 +188,17 @@ func StaticContentsMiddleware(e *echo.Echo, container container.Container, stati
 }
 
 // AuthenticationMiddleware is the middleware of session authentication for echo.
+// The name of the account is added to the logs of the request, including its sql logs.
 func AuthenticationMiddleware(container container.Container) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if !hasAuthorization(c, container) {
 				return c.JSON(http.StatusUnauthorized, false)
 			}
+			if account := container.GetSession().GetAccount(c); account != nil {
+				req := c.Request()
+				c.SetRequest(req.WithContext(logger.WithFields(req.Context(), "account_name", account.Name)))
+			}
 			if err := next(c); err != nil {
 				c.Error(err)
 			}
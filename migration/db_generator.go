@@ -5,21 +5,30 @@ import (
 	"github.com/ybkuroki/go-webapp-sample/model"
 )
 
+// models are the models whose tables are used in this application, in the order of their creation.
+var models = []interface{ TableName() string }{
+	&model.Book{}, &model.Category{}, &model.Format{}, &model.Account{}, &model.Authority{},
+}
+
 // CreateDatabase creates the tables used in this application.
 func CreateDatabase(container container.Container) {
 	if container.GetConfig().Database.Migration {
 		db := container.GetRepository()
 
-		_ = db.DropTableIfExists(&model.Book{})
-		_ = db.DropTableIfExists(&model.Category{})
-		_ = db.DropTableIfExists(&model.Format{})
-		_ = db.DropTableIfExists(&model.Account{})
-		_ = db.DropTableIfExists(&model.Authority{})
+		for _, m := range models {
+			_ = db.DropTableIfExists(m)
+		}
+		for _, m := range models {
+			_ = db.AutoMigrate(m)
+		}
+	}
+}
 
-		_ = db.AutoMigrate(&model.Book{})
-		_ = db.AutoMigrate(&model.Category{})
-		_ = db.AutoMigrate(&model.Format{})
-		_ = db.AutoMigrate(&model.Account{})
-		_ = db.AutoMigrate(&model.Authority{})
+// Tables returns the names of the tables used in this application, which the database must have at startup.
+func Tables() []string {
+	tables := make([]string, 0, len(models))
+	for _, m := range models {
+		tables = append(tables, m.TableName())
 	}
+	return tables
 }
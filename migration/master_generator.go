@@ -17,12 +17,17 @@ func InitMasterData(container container.Container) {
 		a = model.NewAccountWithPlainPassword("test2", "test2", r.ID)
 		_, _ = a.Create(rep)
 
-		c := model.NewCategory("Technical Book")
-		_, _ = c.Create(rep)
-		c = model.NewCategory("Magazine")
-		_, _ = c.Create(rep)
-		c = model.NewCategory("Novel")
-		_, _ = c.Create(rep)
+		created, skipped := 0, 0
+		for _, name := range []string{"Technical Book", "Magazine", "Novel"} {
+			if _, ok, err := model.GetOrCreateByName(rep, name); err != nil {
+				container.GetLogger().GetZapLogger().Warnf("Failed to create the category %s: %s", name, err)
+			} else if ok {
+				created++
+			} else {
+				skipped++
+			}
+		}
+		container.GetLogger().GetZapLogger().Infof("Created the categories: created %d, skipped %d", created, skipped)
 
 		f := model.NewFormat("Paper Book")
 		_, _ = f.Create(rep)
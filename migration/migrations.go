@@ -0,0 +1,121 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/model"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+)
+
+// Migrations are the versioned changes of the schema, applied in order by repository.Migrate.
+// A change of a model must be added here too, so the databases created by the older versions get it,
+// because the migration of CreateDatabase is only for development.
+var Migrations = []repository.Migration{
+	{
+		Version: 1,
+		Name:    "create_tables",
+		Up: func(tx repository.Repository) error {
+			migrator := tx.DB().Migrator()
+			for _, m := range models {
+				if migrator.HasTable(m) {
+					continue
+				}
+				if err := migrator.CreateTable(m); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2,
+		Name:    "add_category_parent_id",
+		Up: func(tx repository.Repository) error {
+			if migrator := tx.DB().Migrator(); !migrator.HasColumn(&model.Category{}, "ParentID") {
+				return migrator.AddColumn(&model.Category{}, "ParentID")
+			}
+			return nil
+		},
+		Down: func(tx repository.Repository) error {
+			return tx.DB().Migrator().DropColumn(&model.Category{}, "ParentID")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add_category_created_at",
+		Up: func(tx repository.Repository) error {
+			if migrator := tx.DB().Migrator(); !migrator.HasColumn(&model.Category{}, "CreatedAt") {
+				if err := migrator.AddColumn(&model.Category{}, "CreatedAt"); err != nil {
+					return err
+				}
+			}
+			// The existing categories are regarded as created now, because their creation times are unknown.
+			return tx.Model(&model.Category{}).Where("created_at IS NULL").
+				Update("created_at", clock.Now().Local()).Error
+		},
+		Down: func(tx repository.Repository) error {
+			return tx.DB().Migrator().DropColumn(&model.Category{}, "CreatedAt")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_category_name_unique_index",
+		Up: func(tx repository.Repository) error {
+			if migrator := tx.DB().Migrator(); !migrator.HasIndex(&model.Category{}, "Name") {
+				return migrator.CreateIndex(&model.Category{}, "Name")
+			}
+			return nil
+		},
+		Down: func(tx repository.Repository) error {
+			return tx.DB().Migrator().DropIndex(&model.Category{}, "Name")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_category_idempotency_key",
+		Up: func(tx repository.Repository) error {
+			migrator := tx.DB().Migrator()
+			if !migrator.HasColumn(&model.Category{}, "IdempotencyKey") {
+				if err := migrator.AddColumn(&model.Category{}, "IdempotencyKey"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(&model.Category{}, "IdempotencyKey") {
+				return migrator.CreateIndex(&model.Category{}, "IdempotencyKey")
+			}
+			return nil
+		},
+		Down: func(tx repository.Repository) error {
+			migrator := tx.DB().Migrator()
+			if err := migrator.DropIndex(&model.Category{}, "IdempotencyKey"); err != nil {
+				return err
+			}
+			return migrator.DropColumn(&model.Category{}, "IdempotencyKey")
+		},
+	},
+}
+
+// MigrateDatabase applies the pending migrations when database.apply_migrations is enabled.
+// Otherwise, it returns an error when any migration is pending, because the application doesn't work
+// with the schema which is older than its models.
+func MigrateDatabase(container container.Container) error {
+	rep := container.GetRepository()
+	if container.GetConfig().Database.ApplyMigrations {
+		applied, err := repository.Migrate(rep, Migrations)
+		if applied > 0 {
+			container.GetLogger().GetZapLogger().Infof("Applied %d migrations", applied)
+		}
+		return err
+	}
+	pending, err := repository.Pending(rep, Migrations)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations are pending from %d %s, which database.apply_migrations applies",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package migration
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"github.com/ybkuroki/go-webapp-sample/model"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"go.uber.org/zap/zaptest"
+)
+
+// prepareForMigrationTest connects an empty SQLite database.
+func prepareForMigrationTest(t *testing.T) repository.Repository {
+	conf := &config.Config{}
+	conf.Database.Dialect = repository.SQLITE
+	conf.Database.DSN = fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	rep := repository.NewBookRepository(logger.NewLogger(zaptest.NewLogger(t).Sugar()), conf)
+	t.Cleanup(func() { _ = rep.Close() })
+	return rep
+}
+
+func TestMigrations_Baseline(t *testing.T) {
+	rep := prepareForMigrationTest(t)
+	// The table of the categories created by the first version, which has only the ID and the name.
+	require.NoError(t, rep.Exec("CREATE TABLE category_master (id integer PRIMARY KEY AUTOINCREMENT, name text)").Error)
+	require.NoError(t, rep.Exec("INSERT INTO category_master (name) VALUES ('Novel'), ('Magazine')").Error)
+	now := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	defer clock.Use(clock.NewFake(now))()
+
+	applied, err := repository.Migrate(rep, Migrations)
+	require.NoError(t, err)
+	assert.Equal(t, len(Migrations), applied)
+
+	migrator := rep.DB().Migrator()
+	for _, column := range []string{"ParentID", "CreatedAt", "IdempotencyKey"} {
+		assert.True(t, migrator.HasColumn(&model.Category{}, column), column)
+	}
+	for _, index := range []string{"Name", "IdempotencyKey"} {
+		assert.True(t, migrator.HasIndex(&model.Category{}, index), index)
+	}
+	for _, table := range Tables() {
+		assert.True(t, migrator.HasTable(table), table)
+	}
+
+	category := &model.Category{}
+	count, err := category.CountCreatedBetween(rep, now.Add(-time.Minute), now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = category.CountDistinct(rep, "created_at")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	pending, err := repository.Pending(rep, Migrations)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
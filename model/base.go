@@ -7,7 +7,10 @@ type DomainObject interface {
 	Account | Authority | Book | Category | Format
 }
 
-// toString returns the JSON data of the domain models.
+// toString returns the JSON data of the domain models, written by their MarshalJSON.
+// The models are written in json by the same policy, so the clients don't special-case each field:
+// an optional field, such as a related model or a parent ID, is omitted when it is nil instead of being null,
+// and the internal columns, such as the creation time of a category, aren't written.
 func toString[T DomainObject](o *T) string {
 	var bytes []byte
 	var err error
@@ -2,8 +2,10 @@ package model
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"math"
+	"time"
 
 	"github.com/moznion/go-optional"
 	"github.com/ybkuroki/go-webapp-sample/repository"
@@ -14,8 +16,8 @@ import (
 // Book defines struct of book data.
 type Book struct {
 	ID         uint      `gorm:"primary_key" json:"id"`
-	Title      string    `json:"title"`
-	Isbn       string    `json:"isbn"`
+	Title      string    `validate:"required,notblank" json:"title"`
+	Isbn       string    `validate:"isbn13" json:"isbn"`
 	CategoryID uint      `json:"categoryId"`
 	Category   *Category `json:"category"`
 	FormatID   uint      `json:"formatId"`
@@ -24,18 +26,20 @@ type Book struct {
 
 // RecordBook defines struct represents the record of the database.
 type RecordBook struct {
-	ID           uint
-	Title        string
-	Isbn         string
-	CategoryID   uint
-	CategoryName string
-	FormatID     uint
-	FormatName   string
+	ID                uint
+	Title             string
+	Isbn              string
+	CategoryID        uint
+	CategoryName      string
+	CategoryCreatedAt time.Time
+	FormatID          uint
+	FormatName        string
 }
 
 const (
 	selectBook = "select b.id as id, b.title as title, b.isbn as isbn, " +
-		"c.id as category_id, c.name as category_name, f.id as format_id, f.name as format_name " +
+		"c.id as category_id, c.name as category_name, c.created_at as category_created_at, " +
+		"f.id as format_id, f.name as format_name " +
 		"from book b inner join category_master c on c.id = b.category_id inner join format_master f on f.id = b.format_id "
 	findByID    = " where b.id = ?"
 	findByTitle = " where title like ? "
@@ -46,6 +50,34 @@ func (Book) TableName() string {
 	return "book"
 }
 
+// bookJSON is the book in json, whose category ID is the token when the encoding of the IDs is enabled.
+type bookJSON struct {
+	ID         uint      `json:"id"`
+	Title      string    `json:"title"`
+	Isbn       string    `json:"isbn"`
+	CategoryID EncodedID `json:"categoryId"`
+	Category   *Category `json:"category,omitempty"`
+	FormatID   uint      `json:"formatId"`
+	Format     *Format   `json:"format,omitempty"`
+}
+
+// MarshalJSON writes this book, whose category ID is the token when the encoding of the IDs is enabled.
+// The category and the format are omitted when they aren't loaded.
+func (b Book) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bookJSON{ID: b.ID, Title: b.Title, Isbn: b.Isbn, CategoryID: EncodedID(b.CategoryID),
+		Category: b.Category, FormatID: b.FormatID, Format: b.Format})
+}
+
+// UnmarshalJSON reads the book written by MarshalJSON. The fields read before an error are kept.
+func (b *Book) UnmarshalJSON(data []byte) error {
+	aux := bookJSON{ID: b.ID, Title: b.Title, Isbn: b.Isbn, CategoryID: EncodedID(b.CategoryID),
+		Category: b.Category, FormatID: b.FormatID, Format: b.Format}
+	err := json.Unmarshal(data, &aux)
+	*b = Book{ID: aux.ID, Title: aux.Title, Isbn: aux.Isbn, CategoryID: uint(aux.CategoryID),
+		Category: aux.Category, FormatID: aux.FormatID, Format: aux.Format}
+	return err
+}
+
 // NewBook is constructor
 func NewBook(title string, isbn string, categoryID uint, formatID uint) *Book {
 	return &Book{Title: title, Isbn: isbn, CategoryID: categoryID, FormatID: formatID}
@@ -60,6 +92,9 @@ func (b *Book) FindByID(rep repository.Repository, id uint) optional.Option[*Boo
 	return convertToBook(&rec)
 }
 
+// ErrCategoryNotFound is returned when the category of a book doesn't exist.
+var ErrCategoryNotFound = errors.New("the category of the book doesn't exist")
+
 // FindAll returns all books of the book table.
 func (b *Book) FindAll(rep repository.Repository) (*[]Book, error) {
 	var books []Book
@@ -96,6 +131,20 @@ func (b *Book) FindByTitle(rep repository.Repository, title string, page string,
 	return p, nil
 }
 
+// FindAllWithRelated returns the page object of all books, whose categories are preloaded.
+// The categories are fetched by a single query for the page, instead of a query for each book.
+func (b *Book) FindAllWithRelated(rep repository.Repository, page string, size string) (*Page, error) {
+	var books []Book
+	query := rep.Preload("Category").Order("id")
+	if util.IsNumeric(page) && util.IsNumeric(size) {
+		query = query.Limit(util.ConvertToInt(size)).Offset(util.ConvertToInt(page) * util.ConvertToInt(size))
+	}
+	if err := query.Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return createPage(&books, page, size), nil
+}
+
 func findRows(rep repository.Repository, sqlquery string, page string,
 	size string, args []interface{}) ([]Book, error) {
 	var books []Book
@@ -150,6 +199,11 @@ func createPage(books *[]Book, page string, size string) *Page {
 	return p
 }
 
+// Validate checks this book data, and returns ValidationErrors when it is invalid.
+func (b *Book) Validate() error {
+	return validateStruct(b)
+}
+
 // Save persists this book data.
 func (b *Book) Save(rep repository.Repository) (*Book, error) {
 	if err := rep.Save(b).Error; err != nil {
@@ -159,7 +213,11 @@ func (b *Book) Save(rep repository.Repository) (*Book, error) {
 }
 
 // Update updates this book data.
+// It returns ValidationErrors when this book is invalid, such as when the ISBN isn't a valid ISBN-13.
 func (b *Book) Update(rep repository.Repository) (*Book, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
 	if err := rep.Model(Book{}).Where("id = ?", b.ID).
 		Select("title", "isbn", "category_id", "format_id").Updates(b).Error; err != nil {
 		return nil, err
@@ -168,7 +226,19 @@ func (b *Book) Update(rep repository.Repository) (*Book, error) {
 }
 
 // Create persists this book data.
+// It returns ValidationErrors when this book is invalid, such as when the ISBN isn't a valid ISBN-13,
+// and ErrCategoryNotFound when the category of this book doesn't exist.
 func (b *Book) Create(rep repository.Repository) (*Book, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	count, err := (&Category{}).CountByID(rep, b.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrCategoryNotFound
+	}
 	if err := rep.Select("title", "isbn", "category_id", "format_id").Create(b).Error; err != nil {
 		return nil, err
 	}
@@ -187,7 +257,7 @@ func convertToBook(rec *RecordBook) optional.Option[*Book] {
 	if rec.ID == 0 {
 		return optional.None[*Book]()
 	}
-	c := &Category{ID: rec.CategoryID, Name: rec.CategoryName}
+	c := &Category{ID: rec.CategoryID, Name: rec.CategoryName, CreatedAt: rec.CategoryCreatedAt}
 	f := &Format{ID: rec.FormatID, Name: rec.FormatName}
 	return optional.Some(
 		&Book{ID: rec.ID, Title: rec.Title, Isbn: rec.Isbn,
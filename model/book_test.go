@@ -0,0 +1,111 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"github.com/ybkuroki/go-webapp-sample/repository/repotest"
+)
+
+func TestBook_FindAllWithRelated(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	novel, _ := NewCategory("Novel").Create(rep)
+	magazine, _ := NewCategory("Magazine").Create(rep)
+	for i, categoryID := range []uint{novel.ID, magazine.ID, novel.ID} {
+		_, err := NewBook("Book"+string(rune('A'+i)), "9784000000000", categoryID, 1).Create(rep)
+		require.NoError(t, err)
+	}
+
+	var result *Page
+	var err error
+	// the books and their categories, regardless of the number of the books.
+	repotest.AssertMaxQueries(t, 2, func(ctx context.Context) {
+		result, err = (&Book{}).FindAllWithRelated(rep.WithContext(ctx), "0", "10")
+	})
+
+	assert.NoError(t, err)
+	books := *result.Content
+	if assert.Len(t, books, 3) {
+		assert.Equal(t, "Novel", books[0].Category.Name)
+		assert.Equal(t, "Magazine", books[1].Category.Name)
+		assert.Equal(t, "Novel", books[2].Category.Name)
+	}
+}
+
+func TestBook_FindAllWithRelated_Page(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		c, _ := NewCategory("Novel").Create(rep)
+		for _, title := range []string{"A", "B", "C"} {
+			_, _ = NewBook(title, "9784000000000", c.ID, 1).Create(rep)
+		}
+
+		result, err := (&Book{}).FindAllWithRelated(rep, "1", "2")
+
+		assert.NoError(t, err)
+		books := *result.Content
+		if assert.Len(t, books, 1) {
+			assert.Equal(t, "C", books[0].Title)
+			assert.Equal(t, "Novel", books[0].Category.Name)
+		}
+	})
+}
+
+func TestBook_CreateWithUnknownCategory(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, err := NewBook("Title", "9784000000000", 99, 1).Create(rep)
+
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+	})
+}
+
+func TestBook_InvalidIsbn(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		c, _ := NewCategory("Novel").Create(rep)
+
+		_, err := NewBook("Title", "978-4-87311-842-1", c.ID, 1).Create(rep)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+
+		book, err := NewBook("Title", "978-4-87311-842-0", c.ID, 1).Create(rep)
+		require.NoError(t, err)
+		book.Isbn = "123-123-123-1"
+		_, err = book.Update(rep)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+
+		var found Book
+		require.NoError(t, rep.First(&found, book.ID).Error)
+		assert.Equal(t, "978-4-87311-842-0", found.Isbn)
+	})
+}
+
+func TestCategory_DeleteInUse(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		used, _ := NewCategory("Novel").Create(rep)
+		unused, _ := NewCategory("Magazine").Create(rep)
+		_, _ = NewBook("Title", "9784000000000", used.ID, 1).Create(rep)
+
+		_, err := used.Delete(rep)
+		assert.ErrorIs(t, err, ErrCategoryInUse)
+
+		_, err = unused.Delete(rep)
+		assert.NoError(t, err)
+		exist, _ := (&Category{}).Exist(rep, unused.ID)
+		assert.False(t, exist)
+	})
+}
+
+func TestCategory_CountBooks(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		c, _ := NewCategory("Novel").Create(rep)
+		_, _ = NewBook("A", "9784000000000", c.ID, 1).Create(rep)
+		_, _ = NewBook("B", "9784000000000", c.ID, 1).Create(rep)
+
+		count, err := (&Category{}).CountBooks(rep, c.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
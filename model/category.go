@@ -1,21 +1,113 @@
 package model
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/moznion/go-optional"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
 	"github.com/ybkuroki/go-webapp-sample/repository"
+	"github.com/ybkuroki/go-webapp-sample/repository/scope"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// upsertBatchSize is the number of the categories inserted by a statement in UpsertCategories.
+	upsertBatchSize = 500
+	// exportFlushInterval is the number of the categories written by ExportJSON between the flushes.
+	exportFlushInterval = 100
 )
 
 // Category defines struct of category data.
 type Category struct {
-	ID   uint   `gorm:"primary_key" json:"id"`
-	Name string `validate:"required" json:"name"`
+	ID        uint      `gorm:"primary_key" json:"id"`
+	Name      string    `gorm:"size:255;uniqueIndex" validate:"required,notblank,reservedname" json:"name"`
+	ParentID  *uint     `json:"parentId,omitempty"`
+	CreatedAt time.Time `json:"-"`
+	// IdempotencyKey is the key of the request which created the category by CreateIdempotent.
+	IdempotencyKey *string `gorm:"size:255;uniqueIndex" json:"-"`
 }
 
+// CategoryWithCount is a category with the number of the books which belong to it.
+type CategoryWithCount struct {
+	Category
+	Count int `json:"count"`
+}
+
+var (
+	// ErrCyclicCategory is returned when a category is going to be an ancestor of itself.
+	ErrCyclicCategory = apperror.New(apperror.Validation, "a category can't be its own ancestor")
+	// ErrInvalidTimeRange is returned when the start of a time range is after its end.
+	ErrInvalidTimeRange = apperror.New(apperror.Validation, "the start of the time range must not be after its end")
+	// ErrCategoryInUse is returned when a category referenced by books is going to be deleted.
+	ErrCategoryInUse = apperror.New(apperror.Conflict, "the category is referenced by books")
+	// ErrInvalidLimit is returned when the size of a page isn't positive.
+	ErrInvalidLimit = apperror.New(apperror.Validation, "the limit must be greater than 0")
+	// ErrUnknownColumn is returned when a column which isn't allowed is given by the caller.
+	ErrUnknownColumn = apperror.New(apperror.Validation, "the column isn't allowed")
+	// ErrEmptyIdempotencyKey is returned when a category is going to be created by an empty idempotency key.
+	ErrEmptyIdempotencyKey = apperror.New(apperror.Validation, "the idempotency key must not be empty")
+	// ErrLockOutsideTransaction is returned when a row is going to be locked outside of any transaction,
+	// where the lock is released as soon as the statement ends.
+	ErrLockOutsideTransaction = apperror.New(apperror.Internal, "a row can be locked only in a transaction")
+)
+
 // TableName returns the table name of category struct and it is used by gorm.
 func (Category) TableName() string {
 	return "category_master"
 }
 
+// categoryJSON is the category in json, whose IDs are the tokens when the encoding of the IDs is enabled.
+type categoryJSON struct {
+	ID       EncodedID  `json:"id"`
+	Name     string     `json:"name"`
+	ParentID *EncodedID `json:"parentId,omitempty"`
+}
+
+// MarshalJSON writes this category, whose IDs are the tokens when the encoding of the IDs is enabled.
+func (c Category) MarshalJSON() ([]byte, error) {
+	return json.Marshal(categoryJSON{ID: EncodedID(c.ID), Name: c.Name, ParentID: (*EncodedID)(c.ParentID)})
+}
+
+// UnmarshalJSON reads the category written by MarshalJSON. The fields read before an error are kept.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	aux := categoryJSON{ID: EncodedID(c.ID), Name: c.Name, ParentID: (*EncodedID)(c.ParentID)}
+	err := json.Unmarshal(data, &aux)
+	c.ID, c.Name, c.ParentID = uint(aux.ID), aux.Name, (*uint)(aux.ParentID)
+	return err
+}
+
+// categoryWithCountJSON is the category with the number of its books in json.
+type categoryWithCountJSON struct {
+	categoryJSON
+	Count int `json:"count"`
+}
+
+// MarshalJSON writes this category with the number of its books. It is needed because the MarshalJSON of Category
+// is promoted to CategoryWithCount, which would drop the count.
+func (c CategoryWithCount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(categoryWithCountJSON{
+		categoryJSON: categoryJSON{ID: EncodedID(c.ID), Name: c.Name, ParentID: (*EncodedID)(c.ParentID)},
+		Count:        c.Count,
+	})
+}
+
+// UnmarshalJSON reads the category written by MarshalJSON. The fields read before an error are kept.
+func (c *CategoryWithCount) UnmarshalJSON(data []byte) error {
+	aux := categoryWithCountJSON{
+		categoryJSON: categoryJSON{ID: EncodedID(c.ID), Name: c.Name, ParentID: (*EncodedID)(c.ParentID)},
+		Count:        c.Count,
+	}
+	err := json.Unmarshal(data, &aux)
+	c.ID, c.Name, c.ParentID, c.Count = uint(aux.ID), aux.Name, (*uint)(aux.ParentID), aux.Count
+	return err
+}
+
 // NewCategory is constructor
 func NewCategory(name string) *Category {
 	return &Category{Name: name}
@@ -24,8 +116,8 @@ func NewCategory(name string) *Category {
 // Exist returns true if a given category exits.
 func (c *Category) Exist(rep repository.Repository, id uint) (bool, error) {
 	var count int64
-	if err := rep.Where("id = ?", id).Count(&count).Error; err != nil {
-		return false, err
+	if err := rep.Model(&Category{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, wrapError(rep, err, "failed to find the category")
 	}
 	if count > 0 {
 		return true, nil
@@ -33,29 +125,537 @@ func (c *Category) Exist(rep repository.Repository, id uint) (bool, error) {
 	return false, nil
 }
 
+// CountByID returns the number of the categories which have the given ID, which is 0 or 1.
+func (c *Category) CountByID(rep repository.Repository, id uint) (int, error) {
+	var count int64
+	if err := rep.Model(&Category{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return 0, wrapError(rep, err, "failed to count the categories")
+	}
+	return int(count), nil
+}
+
+// CountBooks returns the number of the books which belong to the given category.
+func (c *Category) CountBooks(rep repository.Repository, id uint) (int, error) {
+	var count int64
+	if err := rep.Model(&Book{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+		return 0, wrapError(rep, err, "failed to count the books of the category")
+	}
+	return int(count), nil
+}
+
 // FindByID returns a category full matched given category's ID.
 func (c *Category) FindByID(rep repository.Repository, id uint) optional.Option[*Category] {
 	var category Category
-	if err := rep.Where("id = ?", id).First(&category).Error; err != nil {
+	if err := findOne(rep, rep.Where("id = ?", id), &category, "failed to find the category"); err != nil {
 		return optional.None[*Category]()
 	}
 	return optional.Some(&category)
 }
 
+// FindByIDForUpdate returns the category of the given ID, locking its row by SELECT ... FOR UPDATE
+// until the transaction of the repository ends, so the concurrent transactions which lock the same category wait.
+// It returns ErrLockOutsideTransaction outside of any transaction, and apperror.ErrNotFound when there is none.
+// SQLite doesn't have the row locks, so it is a plain SELECT on SQLite, whose transactions lock the whole database
+// when they write.
+func (c *Category) FindByIDForUpdate(rep repository.Repository, id uint) (*Category, error) {
+	if _, ok := rep.DB().Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return nil, ErrLockOutsideTransaction
+	}
+	var category Category
+	db := rep.DB().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id)
+	if err := findOne(rep, db, &category, fmt.Sprintf("failed to lock the category %d", id)); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Reload fetches the category of its ID again, and overwrites its fields with the current ones,
+// such as after it is updated by the other request. It returns apperror.ErrNotFound when it has been deleted,
+// leaving the category as it is.
+func (c *Category) Reload(rep repository.Repository) error {
+	var category Category
+	msg := fmt.Sprintf("failed to reload the category %d", c.ID)
+	if err := findOne(rep, rep.Where("id = ?", c.ID), &category, msg); err != nil {
+		return err
+	}
+	*c = category
+	return nil
+}
+
+// CountCreatedBetween returns the number of the categories created between from and to, both inclusive.
+func (c *Category) CountCreatedBetween(rep repository.Repository, from time.Time, to time.Time) (int, error) {
+	if from.After(to) {
+		return 0, ErrInvalidTimeRange
+	}
+	var count int64
+	if err := rep.Scoped(scope.CreatedBetween(from, to)).Model(&Category{}).Count(&count).Error; err != nil {
+		return 0, wrapError(rep, err, "failed to count the categories")
+	}
+	return int(count), nil
+}
+
+// categoryDistinctColumns are the columns whose distinct values can be counted by CountDistinct.
+var categoryDistinctColumns = map[string]bool{"id": true, "name": true, "parent_id": true, "created_at": true}
+
+// CountDistinct returns the number of the distinct values of the column, such as parent_id, which doesn't count
+// NULL. The column must be one of the columns of the category table, and ErrUnknownColumn is returned otherwise,
+// because it is written into the statement as it is.
+func (c *Category) CountDistinct(rep repository.Repository, column string) (int, error) {
+	if !categoryDistinctColumns[column] {
+		return 0, ErrUnknownColumn
+	}
+	var count int64
+	if err := rep.Model(&Category{}).Distinct(column).Count(&count).Error; err != nil {
+		return 0, wrapError(rep, err, "failed to count the distinct values of the categories")
+	}
+	return int(count), nil
+}
+
 // FindAll returns all categories of the category table.
 func (c *Category) FindAll(rep repository.Repository) (*[]Category, error) {
 	var categories []Category
 	if err := rep.Find(&categories).Error; err != nil {
-		return nil, err
+		return nil, wrapError(rep, err, "failed to find the categories")
+	}
+	return &categories, nil
+}
+
+// AllIDs returns the IDs of all categories in order of id, without loading their rows,
+// such as to check that the referenced categories exist. It returns an empty slice when there is none.
+func (c *Category) AllIDs(rep repository.Repository) ([]uint, error) {
+	ids := []uint{}
+	if err := rep.Model(&Category{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the IDs of the categories")
+	}
+	return ids, nil
+}
+
+// FindAfter returns the page of at most limit categories whose IDs are greater than afterID in order of id,
+// which is the keyset pagination. The ID of the last category of a page is the cursor of the next page,
+// and the first page is returned by 0, so the pages have no gap or overlap even when the categories are inserted.
+// It returns an empty page after the last one, and ErrInvalidLimit when the limit isn't positive.
+func (c *Category) FindAfter(rep repository.Repository, afterID uint, limit int) (*[]Category, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidLimit
+	}
+	categories := []Category{}
+	if err := rep.Scoped(scope.IDAfter(afterID), scope.Limit(limit)).Find(&categories).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the categories")
+	}
+	return &categories, nil
+}
+
+// FindAllWithCounts returns all categories with the number of their books in order of id by a query,
+// instead of counting the books of every category. The categories which have no book have 0.
+func (c *Category) FindAllWithCounts(rep repository.Repository) ([]CategoryWithCount, error) {
+	const columns = "category_master.id, category_master.name, category_master.parent_id, category_master.created_at"
+	var categories []CategoryWithCount
+	err := rep.Model(&Category{}).
+		Select(columns + ", COUNT(book.id) AS count").
+		Joins("LEFT JOIN book ON book.category_id = category_master.id").
+		Group(columns).
+		Order("category_master.id").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, wrapError(rep, err, "failed to find the categories")
+	}
+	return categories, nil
+}
+
+// StreamAll calls fn with every category of the category table in order of id, reading the rows one by one,
+// so only a category is in memory at a time. The category given to fn is reused for the next row,
+// so fn must copy it to keep it. It stops and returns the error when fn returns an error,
+// or when the context of the repository is canceled.
+func (c *Category) StreamAll(rep repository.Repository, fn func(*Category) error) error {
+	query := rep.Model(&Category{}).Order("id")
+	ctx := query.Statement.Context
+	rows, err := query.Rows()
+	if err != nil {
+		return wrapError(rep, err, "failed to find the categories")
+	}
+	defer rows.Close()
+
+	var category Category
+	for rows.Next() {
+		if ctx != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		category = Category{}
+		if err := rep.ScanRows(rows, &category); err != nil {
+			return wrapError(rep, err, "failed to read the categories")
+		}
+		if err := fn(&category); err != nil {
+			return err
+		}
+	}
+	return wrapError(rep, rows.Err(), "failed to read the categories")
+}
+
+// ExportJSON writes all categories to w as a JSON array, streaming them by StreamAll.
+// The buffered output is flushed every exportFlushInterval categories,
+// and w is flushed too when it can be, such as a http.ResponseWriter.
+func (c *Category) ExportJSON(rep repository.Repository, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+
+	if err := buf.WriteByte('['); err != nil {
+		return err
+	}
+	count := 0
+	err := c.StreamAll(rep, func(category *Category) error {
+		if count > 0 {
+			if err := buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(category); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushInterval == 0 {
+			return flushExport(buf, w)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := buf.WriteString("]\n"); err != nil {
+		return err
+	}
+	return flushExport(buf, w)
+}
+
+// EncodeCategories streams all categories to w as a JSON array without buffering the whole list,
+// such as to write a huge list of the categories to the response. It is the same as ExportJSON.
+func EncodeCategories(w io.Writer, rep repository.Repository) error {
+	return (&Category{}).ExportJSON(rep, w)
+}
+
+// ExportNDJSON writes all categories to w as the newline-delimited JSON, a compact JSON object per line,
+// streaming them by StreamAll. The output is flushed like ExportJSON.
+// When it fails in the middle, the lines written so far are flushed and the error is returned.
+func (c *Category) ExportNDJSON(rep repository.Repository, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+
+	count := 0
+	err := c.StreamAll(rep, func(category *Category) error {
+		if err := encoder.Encode(category); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushInterval == 0 {
+			return flushExport(buf, w)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = flushExport(buf, w)
+		return err
+	}
+	return flushExport(buf, w)
+}
+
+// flushExport flushes the buffered output to w, and flushes w too when it can be, such as a http.ResponseWriter.
+func flushExport(buf *bufio.Writer, w io.Writer) error {
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// ScanAll scans all categories into the given destination, such as a pointer to a slice of DTOs,
+// so the caller gets the shape it needs without mapping the categories by itself.
+// The columns are selected into the fields of the same names, and they can be computed by the expressions
+// with aliases, such as "(SELECT COUNT(*) FROM book WHERE book.category_id = category_master.id) AS book_count".
+// All columns are selected when no column is given.
+func (c *Category) ScanAll(rep repository.Repository, dest interface{}, columns ...string) error {
+	query := rep.Model(&Category{}).Order("id")
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	}
+	return wrapError(rep, query.Scan(dest).Error, "failed to find the categories")
+}
+
+// FindByNames returns the categories whose names are in the given names.
+// The names are normalized by NormalizeName and deduplicated,
+// and it returns an empty list without querying when no name is given.
+func (c *Category) FindByNames(rep repository.Repository, names []string) (*[]Category, error) {
+	categories := []Category{}
+	unique := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = NormalizeName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	if len(unique) == 0 {
+		return &categories, nil
+	}
+	if err := rep.Where("name IN ?", unique).Find(&categories).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the categories")
 	}
 	return &categories, nil
 }
 
-// Create persists this category data.
+// FindChildren returns the categories which are the direct children of the given category.
+func (c *Category) FindChildren(rep repository.Repository, parentID uint) (*[]Category, error) {
+	var categories []Category
+	if err := rep.Where("parent_id = ?", parentID).Order("id").Find(&categories).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the children of the category")
+	}
+	return &categories, nil
+}
+
+// FindRoots returns the categories which have no parent.
+func (c *Category) FindRoots(rep repository.Repository) (*[]Category, error) {
+	var categories []Category
+	if err := rep.Where("parent_id IS NULL").Order("id").Find(&categories).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the root categories")
+	}
+	return &categories, nil
+}
+
+// FindSubtree returns all descendants of the given category in breadth-first order.
+// The given category itself isn't included.
+func (c *Category) FindSubtree(rep repository.Repository, id uint) (*[]Category, error) {
+	var subtree []Category
+	visited := map[uint]bool{id: true}
+	parents := []uint{id}
+
+	for len(parents) > 0 {
+		var children []Category
+		if err := rep.Where("parent_id IN ?", parents).Order("id").Find(&children).Error; err != nil {
+			return nil, wrapError(rep, err, "failed to find the descendants of the category")
+		}
+		parents = parents[:0]
+		for _, child := range children {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			subtree = append(subtree, child)
+			parents = append(parents, child.ID)
+		}
+	}
+	return &subtree, nil
+}
+
+// SetParent changes the parent of this category. A nil parentID makes this category a root.
+// It returns ErrCyclicCategory when the new parent is this category itself or one of its descendants.
+func (c *Category) SetParent(rep repository.Repository, parentID *uint) error {
+	visited := map[uint]bool{}
+	for ancestor := parentID; ancestor != nil; {
+		if *ancestor == c.ID {
+			return ErrCyclicCategory
+		}
+		if visited[*ancestor] {
+			break
+		}
+		visited[*ancestor] = true
+
+		var parent Category
+		if err := rep.Where("id = ?", *ancestor).First(&parent).Error; err != nil {
+			return wrapError(rep, err, fmt.Sprintf("failed to find the category %d", *ancestor))
+		}
+		ancestor = parent.ParentID
+	}
+
+	if err := rep.Model(c).Update("parent_id", parentID).Error; err != nil {
+		return wrapError(rep, err, "failed to update the parent of the category")
+	}
+	c.ParentID = parentID
+	return nil
+}
+
+// Clone returns a copy of this category which shares nothing with it,
+// so a cached category can be handed out without the callers corrupting the cache.
+func (c *Category) Clone() *Category {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	if c.ParentID != nil {
+		parentID := *c.ParentID
+		clone.ParentID = &parentID
+	}
+	return &clone
+}
+
+// Validate checks this category data, and returns ValidationErrors when it is invalid.
+func (c *Category) Validate() error {
+	return validateStruct(c)
+}
+
+// Create persists this category data. The name is normalized by NormalizeName.
+// It returns ValidationErrors when this category is invalid, such as when the name is blank.
 func (c *Category) Create(rep repository.Repository) (*Category, error) {
+	c.Name = NormalizeName(c.Name)
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
 	if err := rep.Create(c).Error; err != nil {
+		return nil, wrapError(rep, err, fmt.Sprintf("failed to create the category %q", c.Name))
+	}
+	return c, nil
+}
+
+// CreateIdempotent creates this category with the idempotency key, such as the ID of a message of a queue
+// delivered at least once. When a category has already been created by the key, it is returned instead
+// of creating another one, so the retried request returns the same category. It is returned even when
+// the retried request has another name, because the key identifies the request. The concurrent requests
+// of the same key create one category, like GetOrCreateByName. The name is normalized by NormalizeName.
+func (c *Category) CreateIdempotent(rep repository.Repository, key string) (*Category, error) {
+	if key == "" {
+		return nil, ErrEmptyIdempotencyKey
+	}
+	if existing, err := findByIdempotencyKey(rep, key); err != nil || existing != nil {
+		return existing, err
+	}
+	c.Name = NormalizeName(c.Name)
+	c.IdempotencyKey = &key
+	if err := c.Validate(); err != nil {
 		return nil, err
 	}
+
+	err := rep.Transaction(func(tx repository.Repository) error {
+		return tx.Create(c).Error
+	})
+	if err == nil {
+		return c, nil
+	}
+	if !rep.IsDuplicateKeyError(err) {
+		return nil, wrapError(rep, err, fmt.Sprintf("failed to create the category %q", c.Name))
+	}
+	existing, findErr := findByIdempotencyKey(rep, key)
+	if findErr != nil || existing != nil {
+		return existing, findErr
+	}
+	// the name is duplicated by the category of another key.
+	return nil, wrapError(rep, err, fmt.Sprintf("failed to create the category %q", c.Name))
+}
+
+// findByIdempotencyKey returns the category created by the idempotency key. It returns nil when there is none.
+func findByIdempotencyKey(rep repository.Repository, key string) (*Category, error) {
+	var categories []Category
+	if err := rep.Where("idempotency_key = ?", key).Limit(1).Find(&categories).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to find the category by the idempotency key")
+	}
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	return &categories[0], nil
+}
+
+// categoryUpsert inserts the categories, and updates the parents of the existing ones which have the same names.
+var categoryUpsert = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "name"}},
+	DoUpdates: clause.AssignmentColumns([]string{"parent_id"}),
+}
+
+// UpsertCategories inserts the given categories, and updates the existing ones which have the same names,
+// by INSERT ... ON CONFLICT DO UPDATE (ON DUPLICATE KEY UPDATE on MySQL).
+// The large batches are split into the statements of upsertBatchSize categories in a transaction.
+// It is idempotent, so the categories synced from an external source can be upserted repeatedly.
+// The names are normalized by NormalizeName.
+func UpsertCategories(rep repository.Repository, categories []Category) error {
+	unique := make([]Category, 0, len(categories))
+	index := map[string]int{}
+	for _, category := range categories {
+		category.Name = NormalizeName(category.Name)
+		if err := category.Validate(); err != nil {
+			return err
+		}
+		// a statement can't update the same row twice, so the later category of the same name wins.
+		if i, ok := index[category.Name]; ok {
+			unique[i] = category
+			continue
+		}
+		index[category.Name] = len(unique)
+		unique = append(unique, category)
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	err := rep.Transaction(func(tx repository.Repository) error {
+		return tx.DB().Clauses(categoryUpsert).CreateInBatches(&unique, upsertBatchSize).Error
+	})
+	return wrapError(rep, err, "failed to upsert the categories")
+}
+
+// GetOrCreateByName returns the category of the given name, creating it when there is none.
+// The second result is true when it has been created. When a concurrent request creates the same category
+// between the lookup and the insert, the violation of the unique name is caught and the existing category
+// is returned instead, so it is idempotent. The insert runs in a savepoint inside a transaction,
+// so the violation doesn't abort the transaction. The name is normalized by NormalizeName.
+func GetOrCreateByName(rep repository.Repository, name string) (*Category, bool, error) {
+	name = NormalizeName(name)
+	category := NewCategory(name)
+	if err := category.Validate(); err != nil {
+		return nil, false, err
+	}
+	if existing, err := findByName(rep, name); err != nil || existing != nil {
+		return existing, false, err
+	}
+
+	err := rep.Transaction(func(tx repository.Repository) error {
+		return tx.Create(category).Error
+	})
+	if err == nil {
+		return category, true, nil
+	}
+	if !rep.IsDuplicateKeyError(err) {
+		return nil, false, wrapError(rep, err, fmt.Sprintf("failed to create the category %q", name))
+	}
+	existing, err := findByName(rep, name)
+	if err == nil && existing == nil {
+		err = apperror.New(apperror.Internal, fmt.Sprintf("category %q violated the unique name but isn't found", name))
+	}
+	return existing, false, err
+}
+
+// FindOneByName returns the category of the given name normalized by NormalizeName.
+// It returns apperror.ErrNotFound when there is none, and ErrMultipleRecords when the name isn't unique,
+// such as before the unique index of the name is created.
+func (c *Category) FindOneByName(rep repository.Repository, name string) (*Category, error) {
+	var category Category
+	name = NormalizeName(name)
+	msg := fmt.Sprintf("failed to find the category %q", name)
+	if err := findOne(rep, rep.Where("name = ?", name), &category, msg); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// findByName returns the category of the given name normalized by NormalizeName. It returns nil when there is none.
+func findByName(rep repository.Repository, name string) (*Category, error) {
+	category, err := (&Category{}).FindOneByName(rep, name)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return nil, nil
+	}
+	return category, err
+}
+
+// Delete deletes this category data.
+// It returns ErrCategoryInUse when any book belongs to this category.
+func (c *Category) Delete(rep repository.Repository) (*Category, error) {
+	count, err := c.CountBooks(rep, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrCategoryInUse
+	}
+	if err := rep.Delete(c).Error; err != nil {
+		return nil, wrapError(rep, err, "failed to delete the category")
+	}
 	return c, nil
 }
 
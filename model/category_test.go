@@ -0,0 +1,936 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestCategory_CreateAndFindByID(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		c, err := NewCategory("Novel").Create(rep)
+		assert.NoError(t, err)
+
+		result, err := (&Category{}).FindByID(rep, c.ID).Take()
+		assert.NoError(t, err)
+		assert.Equal(t, "Novel", result.Name)
+	})
+}
+
+func TestCategory_FindAll(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, _ = NewCategory("Technical Book").Create(rep)
+		_, _ = NewCategory("Magazine").Create(rep)
+
+		result, err := (&Category{}).FindAll(rep)
+		assert.NoError(t, err)
+		assert.Len(t, *result, 2)
+	})
+}
+
+func TestCategory_FindAllWithRequestID(t *testing.T) {
+	rep, logs := prepareForObservedModelTest(t)
+	_, _ = NewCategory("Novel").Create(rep)
+
+	ctx, id := logger.EnsureRequestID(context.Background())
+	result, err := (&Category{}).FindAll(rep.WithContext(ctx))
+
+	assert.NoError(t, err)
+	assert.Len(t, *result, 1)
+	withID := logs.FilterField(zap.String(logger.RequestIDKey, id)).FilterMessageSnippet("SELECT")
+	assert.Equal(t, 1, withID.Len())
+}
+
+func TestCategory_Exist(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		c, _ := NewCategory("Novel").Create(rep)
+
+		exist, err := (&Category{}).Exist(rep, c.ID)
+		assert.NoError(t, err)
+		assert.True(t, exist)
+
+		exist, err = (&Category{}).Exist(rep, c.ID+1)
+		assert.NoError(t, err)
+		assert.False(t, exist)
+	})
+}
+
+func TestCategory_CountCreatedBetween(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		base := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		fake := clock.NewFake(base)
+		t.Cleanup(repository.SetNowFunc(fake.Now))
+		for _, name := range []string{"Technical Book", "Magazine", "Novel", "Comic"} {
+			_, err := NewCategory(name).Create(rep)
+			require.NoError(t, err)
+			fake.Advance(24 * time.Hour)
+		}
+
+		count, err := (&Category{}).CountCreatedBetween(rep, base.AddDate(0, 0, 1), base.AddDate(0, 0, 2))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = (&Category{}).CountCreatedBetween(rep, base.AddDate(0, 1, 0), base.AddDate(0, 2, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestCategory_CreatedAt(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		frozen := time.Date(2024, 4, 1, 9, 30, 15, 0, time.UTC)
+		t.Cleanup(clock.Use(clock.NewFake(frozen)))
+
+		created, err := NewCategory("Novel").Create(rep)
+		require.NoError(t, err)
+		found, err := (&Category{}).FindOneByName(rep, "Novel")
+		require.NoError(t, err)
+
+		assert.True(t, frozen.Equal(created.CreatedAt), "created at %s", created.CreatedAt)
+		assert.True(t, frozen.Equal(found.CreatedAt), "found at %s", found.CreatedAt)
+	})
+}
+
+func TestCategory_CountCreatedBetweenInvertedRange(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := (&Category{}).CountCreatedBetween(rep, now, now.Add(-time.Hour))
+		assert.ErrorIs(t, err, ErrInvalidTimeRange)
+	})
+}
+
+func TestCategory_FindByNames(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, _ = NewCategory("Technical Book").Create(rep)
+		_, _ = NewCategory("Magazine").Create(rep)
+		_, _ = NewCategory("Novel").Create(rep)
+
+		result, err := (&Category{}).FindByNames(rep, []string{" Magazine", "Comic", "Novel ", "Magazine", ""})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Magazine", "Novel"}, categoryNames(*result))
+	})
+}
+
+func TestCategory_FindByNamesEmpty(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, _ = NewCategory("Novel").Create(rep)
+
+		result, err := (&Category{}).FindByNames(rep, []string{" ", ""})
+		assert.NoError(t, err)
+		assert.Empty(t, *result)
+	})
+}
+
+func TestCategory_FindRootsAndChildren(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		fiction, _ := NewCategory("Fiction").Create(rep)
+		_, _ = NewCategory("Magazine").Create(rep)
+		scifi := createChildCategory(t, rep, "Sci-Fi", fiction.ID)
+		_ = createChildCategory(t, rep, "Mystery", fiction.ID)
+		_ = createChildCategory(t, rep, "Space Opera", scifi.ID)
+
+		roots, err := (&Category{}).FindRoots(rep)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Fiction", "Magazine"}, categoryNames(*roots))
+
+		children, err := (&Category{}).FindChildren(rep, fiction.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Sci-Fi", "Mystery"}, categoryNames(*children))
+	})
+}
+
+func TestCategory_FindSubtree(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		fiction, _ := NewCategory("Fiction").Create(rep)
+		scifi := createChildCategory(t, rep, "Sci-Fi", fiction.ID)
+		_ = createChildCategory(t, rep, "Mystery", fiction.ID)
+		_ = createChildCategory(t, rep, "Space Opera", scifi.ID)
+
+		subtree, err := (&Category{}).FindSubtree(rep, fiction.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Sci-Fi", "Mystery", "Space Opera"}, categoryNames(*subtree))
+
+		subtree, err = (&Category{}).FindSubtree(rep, scifi.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Space Opera"}, categoryNames(*subtree))
+	})
+}
+
+func TestCategory_SetParentRejectsCycle(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		fiction, _ := NewCategory("Fiction").Create(rep)
+		scifi := createChildCategory(t, rep, "Sci-Fi", fiction.ID)
+		opera := createChildCategory(t, rep, "Space Opera", scifi.ID)
+
+		assert.ErrorIs(t, fiction.SetParent(rep, &fiction.ID), ErrCyclicCategory)
+		assert.ErrorIs(t, fiction.SetParent(rep, &opera.ID), ErrCyclicCategory)
+		assert.Nil(t, fiction.ParentID)
+
+		assert.NoError(t, opera.SetParent(rep, nil))
+		roots, _ := (&Category{}).FindRoots(rep)
+		assert.Equal(t, []string{"Fiction", "Space Opera"}, categoryNames(*roots))
+	})
+}
+
+func TestCategory_SetParentNotFound(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		fiction, _ := NewCategory("Fiction").Create(rep)
+		missing := fiction.ID + 100
+
+		err := fiction.SetParent(rep, &missing)
+
+		assert.ErrorIs(t, err, apperror.ErrNotFound)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, fiction.ParentID)
+	})
+}
+
+func createChildCategory(t *testing.T, rep repository.Repository, name string, parentID uint) *Category {
+	c, err := NewCategory(name).Create(rep)
+	require.NoError(t, err)
+	require.NoError(t, c.SetParent(rep, &parentID))
+	return c
+}
+
+func categoryNames(categories []Category) []string {
+	names := make([]string, 0, len(categories))
+	for _, c := range categories {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestCategory_ScanAll(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		novel, _ := NewCategory("Novel").Create(rep)
+		_, _ = NewCategory("Magazine").Create(rep)
+		_, _ = NewBook("Title", "9784000000000", novel.ID, 1).Create(rep)
+
+		type categorySummary struct {
+			ID        uint
+			Name      string
+			BookCount int
+		}
+		var summaries []categorySummary
+		err := (&Category{}).ScanAll(rep, &summaries, "id", "name",
+			"(SELECT COUNT(*) FROM book WHERE book.category_id = category_master.id) AS book_count")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []categorySummary{{novel.ID, "Novel", 1}, {novel.ID + 1, "Magazine", 0}}, summaries)
+	})
+}
+
+func TestCategory_FindAllWithCounts(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		novel, _ := NewCategory("Novel").Create(rep)
+		magazine, _ := NewCategory("Magazine").Create(rep)
+		_, _ = NewBook("Title1", "9784000000000", novel.ID, 1).Create(rep)
+		_, _ = NewBook("Title2", "9784000000017", novel.ID, 1).Create(rep)
+
+		result, err := (&Category{}).FindAllWithCounts(rep)
+
+		assert.NoError(t, err)
+		if assert.Len(t, result, 2) {
+			assert.Equal(t, novel.ID, result[0].ID)
+			assert.Equal(t, "Novel", result[0].Name)
+			assert.Equal(t, 2, result[0].Count)
+			assert.Equal(t, magazine.ID, result[1].ID)
+			assert.Equal(t, "Magazine", result[1].Name)
+			assert.Equal(t, 0, result[1].Count)
+		}
+	})
+}
+
+func TestCategory_ScanAllColumns(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, _ = NewCategory("Novel").Create(rep)
+
+		var names []struct{ Name string }
+		err := (&Category{}).ScanAll(rep, &names)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Novel", names[0].Name)
+	})
+}
+
+func TestCategory_CreateConstraintViolationLogged(t *testing.T) {
+	rep, logs := prepareForObservedModelTest(t)
+	existing, _ := NewCategory("Novel").Create(rep)
+
+	duplicate := NewCategory("Magazine")
+	duplicate.ID = existing.ID
+	_, err := duplicate.Create(rep)
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	assert.Equal(t, apperror.Conflict, apperror.CodeOf(err))
+	entries := logs.FilterMessage("[gorm] sql_error").All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Contains(t, fields["statement"], "INSERT INTO `category_master`")
+		assert.Contains(t, fields["statement"], "'Magazine'")
+		assert.Contains(t, fields["params"], "'Magazine'")
+		assert.Equal(t, "sqlite", fields["dialect"])
+		assert.Contains(t, fields["error"], "UNIQUE constraint failed")
+	}
+}
+
+func TestCategory_RolledBackTransactionLogged(t *testing.T) {
+	rep, logs := prepareForObservedModelTest(t)
+
+	err := rep.Transaction(func(tx repository.Repository) error {
+		if _, err := NewCategory("Novel").Create(tx); err != nil {
+			return err
+		}
+		if _, err := NewCategory("Magazine").Create(tx); err != nil {
+			return err
+		}
+		return errors.New("rollback the categories")
+	})
+
+	assert.Error(t, err)
+	entries := logs.FilterFieldKey(logger.TransactionIDKey).All()
+	require.Len(t, entries, 4)
+	assert.Equal(t, "[gorm] BEGIN", entries[0].Message)
+	assert.Contains(t, entries[1].Message, "'Novel'")
+	assert.Contains(t, entries[2].Message, "'Magazine'")
+	assert.Equal(t, "[gorm] ROLLBACK", entries[3].Message)
+	id := entries[0].ContextMap()[logger.TransactionIDKey]
+	assert.NotEmpty(t, id)
+	for _, entry := range entries {
+		assert.Equal(t, zap.DebugLevel, entry.Level)
+		assert.Equal(t, id, entry.ContextMap()[logger.TransactionIDKey])
+	}
+}
+
+func TestUpsertCategories_Idempotent(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		root, err := NewCategory("Book").Create(rep)
+		require.NoError(t, err)
+		categories := []Category{{Name: "Novel"}, {Name: "Magazine"}}
+		require.NoError(t, UpsertCategories(rep, categories))
+
+		categories = []Category{{Name: "Novel", ParentID: &root.ID}, {Name: "Magazine"}, {Name: "Comic"}}
+		require.NoError(t, UpsertCategories(rep, categories))
+
+		result, err := (&Category{}).FindAll(rep)
+		require.NoError(t, err)
+		parents := map[string]*uint{}
+		for _, c := range *result {
+			assert.NotContains(t, parents, c.Name)
+			parents[c.Name] = c.ParentID
+		}
+		assert.Len(t, parents, 4)
+		if assert.NotNil(t, parents["Novel"]) {
+			assert.Equal(t, root.ID, *parents["Novel"])
+		}
+		assert.Nil(t, parents["Magazine"])
+	})
+}
+
+func TestUpsertCategories_Batches(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	categories := make([]Category, upsertBatchSize*2+1)
+	for i := range categories {
+		categories[i].Name = fmt.Sprintf("Category %d", i)
+	}
+	require.NoError(t, UpsertCategories(rep, categories))
+	require.NoError(t, UpsertCategories(rep, append(categories, Category{Name: "Category 0"})))
+
+	result, err := (&Category{}).FindAll(rep)
+	require.NoError(t, err)
+	assert.Len(t, *result, len(categories))
+}
+
+func TestCategory_ExportJSON(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	var empty bytes.Buffer
+	require.NoError(t, (&Category{}).ExportJSON(rep, &empty))
+	assert.JSONEq(t, "[]", empty.String())
+
+	seedCategories(t, rep, 0, 3000)
+	var buf bytes.Buffer
+	require.NoError(t, (&Category{}).ExportJSON(rep, &buf))
+
+	var categories []Category
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &categories))
+	if assert.Len(t, categories, 3000) {
+		assert.Equal(t, "Category 0", categories[0].Name)
+		assert.Equal(t, "Category 2999", categories[2999].Name)
+	}
+	stored, err := (&Category{}).FindAll(rep)
+	require.NoError(t, err)
+	expected, err := json.Marshal(stored)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), buf.String())
+}
+
+func TestEncodeCategories(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	seedCategories(t, rep, 0, 100)
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeCategories(&buf, rep))
+
+	var categories []Category
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &categories))
+	stored, err := (&Category{}).FindAll(rep)
+	require.NoError(t, err)
+	// the fields which aren't written in JSON, such as the creation time, are dropped from the stored ones too.
+	storedJSON, err := json.Marshal(stored)
+	require.NoError(t, err)
+	var expected []Category
+	require.NoError(t, json.Unmarshal(storedJSON, &expected))
+	assert.Len(t, categories, 100)
+	assert.Equal(t, expected, categories)
+}
+
+func TestCategory_StreamAllStopsEarly(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	seedCategories(t, rep, 0, 100)
+	stop := errors.New("stop")
+
+	count := 0
+	err := (&Category{}).StreamAll(rep, func(*Category) error {
+		count++
+		if count == 10 {
+			return stop
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 10, count)
+}
+
+func TestCategory_StreamAllCanceled(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	seedCategories(t, rep, 0, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	count := 0
+	err := (&Category{}).StreamAll(rep.WithContext(ctx), func(*Category) error {
+		count++
+		if count == 10 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 10, count)
+	// the cursor has been closed, so the connection can be used again.
+	_, err = (&Category{}).CountByID(rep, 1)
+	assert.NoError(t, err)
+}
+
+func TestCategory_StreamAllAllocations(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	allocsPerRow := func(rows int) float64 {
+		return testing.AllocsPerRun(3, func() {
+			_ = (&Category{}).StreamAll(rep, func(*Category) error { return nil })
+		}) / float64(rows)
+	}
+
+	seedCategories(t, rep, 0, 1000)
+	small := allocsPerRow(1000)
+	seedCategories(t, rep, 1000, 4000)
+	large := allocsPerRow(4000)
+
+	// a row allocates the same regardless of the size of the table, since nothing is accumulated.
+	assert.InDelta(t, small, large, small*0.1)
+}
+
+// seedCategories inserts the categories named "Category <i>" for i in [from, to).
+func seedCategories(t *testing.T, rep repository.Repository, from int, to int) {
+	categories := make([]Category, 0, to-from)
+	for i := from; i < to; i++ {
+		categories = append(categories, Category{Name: fmt.Sprintf("Category %d", i)})
+	}
+	require.NoError(t, UpsertCategories(rep, categories))
+}
+
+func TestCategory_Clone(t *testing.T) {
+	parentID := uint(1)
+	createdAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	original := &Category{ID: 2, Name: "Novel", ParentID: &parentID, CreatedAt: createdAt}
+
+	clone := original.Clone()
+	assert.Equal(t, original, clone)
+
+	clone.Name = "Magazine"
+	*clone.ParentID = 3
+	clone.CreatedAt = clone.CreatedAt.Add(time.Hour)
+
+	assert.Equal(t, "Novel", original.Name)
+	assert.Equal(t, uint(1), *original.ParentID)
+	assert.Equal(t, createdAt, original.CreatedAt)
+	assert.Nil(t, (*Category)(nil).Clone())
+}
+
+func TestGetOrCreateByName_Concurrent(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, ok, err := GetOrCreateByName(rep, "Novel")
+			if err == nil && c.Name != "Novel" {
+				err = fmt.Errorf("unexpected category: %s", c.ToString())
+			}
+			if ok {
+				created.Add(1)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), created.Load())
+	count, err := (&Category{}).CountByID(rep, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	result, err := (&Category{}).FindAll(rep)
+	assert.NoError(t, err)
+	assert.Len(t, *result, 1)
+}
+
+func TestGetOrCreateByName_InTransaction(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		existing, err := NewCategory("Novel").Create(rep)
+		require.NoError(t, err)
+
+		err = rep.Transaction(func(tx repository.Repository) error {
+			c, created, err := GetOrCreateByName(tx, "Novel")
+			assert.False(t, created)
+			assert.Equal(t, existing.ID, c.ID)
+			if err != nil {
+				return err
+			}
+			c, created, err = GetOrCreateByName(tx, "Magazine")
+			assert.True(t, created)
+			assert.NotZero(t, c.ID)
+			return err
+		})
+
+		assert.NoError(t, err)
+		result, err := (&Category{}).FindAll(rep)
+		assert.NoError(t, err)
+		assert.Len(t, *result, 2)
+	})
+}
+
+func TestGetOrCreateByName_LostRace(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	var rival *Category
+	// the rival creates the same category between the lookup and the insert.
+	require.NoError(t, rep.DB().Callback().Create().Before("gorm:create").Register("test:rival", func(db *gorm.DB) {
+		if rival == nil {
+			rival = NewCategory("Novel")
+			require.NoError(t, rep.Create(rival).Error)
+		}
+	}))
+
+	c, created, err := GetOrCreateByName(rep, "Novel")
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	if assert.NotNil(t, rival) && assert.NotNil(t, c) {
+		assert.Equal(t, rival.ID, c.ID)
+	}
+}
+
+func TestCategory_ExportNDJSON(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	seedCategories(t, rep, 0, 250)
+	var buf bytes.Buffer
+
+	require.NoError(t, (&Category{}).ExportNDJSON(rep, &buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 250)
+	decoder := json.NewDecoder(&buf)
+	var categories []Category
+	for decoder.More() {
+		var c Category
+		require.NoError(t, decoder.Decode(&c))
+		categories = append(categories, c)
+	}
+	if assert.Len(t, categories, 250) {
+		assert.Equal(t, "Category 0", categories[0].Name)
+		assert.Equal(t, "Category 249", categories[249].Name)
+	}
+}
+
+func TestCategory_ExportNDJSONWriteError(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	seedCategories(t, rep, 0, 250)
+	broken := errors.New("broken pipe")
+
+	err := (&Category{}).ExportNDJSON(rep, failingWriter{err: broken})
+
+	assert.ErrorIs(t, err, broken)
+}
+
+// failingWriter fails every write with the error.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestGetOrCreateByName_Normalized(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		existing, _ := NewCategory("Novel").Create(rep)
+
+		category, created, err := GetOrCreateByName(rep, "Novel　")
+
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, existing.ID, category.ID)
+	})
+}
+
+func TestCategory_FindByIDForUpdate(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		novel, _ := NewCategory("Novel").Create(rep)
+
+		_, err := (&Category{}).FindByIDForUpdate(rep, novel.ID)
+		assert.ErrorIs(t, err, ErrLockOutsideTransaction)
+
+		err = rep.Transaction(func(tx repository.Repository) error {
+			locked, err := (&Category{}).FindByIDForUpdate(tx, novel.ID)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "Novel", locked.Name)
+			}
+			_, err = (&Category{}).FindByIDForUpdate(tx, novel.ID+100)
+			assert.ErrorIs(t, err, apperror.ErrNotFound)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestCategory_FindByIDForUpdateBlocks(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		if rep.DB().Dialector.Name() == "sqlite" {
+			t.Skip("SQLite doesn't have the row locks")
+		}
+		novel, _ := NewCategory("Novel").Create(rep)
+		locked := make(chan struct{})
+		release := make(chan struct{})
+		var committedAt, readAt time.Time
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = rep.Transaction(func(tx repository.Repository) error {
+				_, err := (&Category{}).FindByIDForUpdate(tx, novel.ID)
+				close(locked)
+				<-release
+				committedAt = time.Now()
+				return err
+			})
+		}()
+		<-locked
+		time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+		err := rep.Transaction(func(tx repository.Repository) error {
+			_, err := (&Category{}).FindByIDForUpdate(tx, novel.ID)
+			readAt = time.Now()
+			return err
+		})
+		wg.Wait()
+
+		assert.NoError(t, err)
+		assert.False(t, readAt.Before(committedAt), "the second reader must wait for the commit of the first")
+	})
+}
+
+func TestCategory_FindByIDLogsRows(t *testing.T) {
+	rep, logs := prepareForObservedModelTest(t)
+	novel, _ := NewCategory("Novel").Create(rep)
+	_ = logs.TakeAll()
+
+	assert.True(t, (&Category{}).FindByID(rep, novel.ID).IsSome())
+
+	selects := logs.FilterMessageSnippet("SELECT").All()
+	if assert.Len(t, selects, 1) {
+		assert.Equal(t, int64(1), selects[0].ContextMap()["rows"])
+	}
+	assert.Zero(t, logs.FilterMessageSnippet("record not found").Len())
+}
+
+func TestCategory_FindByIDLogsNotFound(t *testing.T) {
+	rep, logs := prepareForObservedModelTest(t)
+
+	assert.True(t, (&Category{}).FindByID(rep, 100).IsNone())
+
+	selects := logs.FilterMessageSnippet("SELECT").All()
+	if assert.Len(t, selects, 1) {
+		assert.Equal(t, zap.DebugLevel, selects[0].Level)
+		assert.Equal(t, int64(0), selects[0].ContextMap()["rows"])
+	}
+	assert.Zero(t, logs.FilterMessage("[gorm] sql_error").Len())
+	hints := logs.FilterMessage("[gorm] record not found for category_master").All()
+	if assert.Len(t, hints, 1) {
+		assert.Equal(t, zap.DebugLevel, hints[0].Level)
+		assert.Equal(t, "id = 100", hints[0].ContextMap()["where"])
+	}
+}
+
+func TestCategory_FindAfter(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		for i := 1; i <= 7; i++ {
+			_, err := NewCategory(fmt.Sprintf("Category %d", i)).Create(rep)
+			require.NoError(t, err)
+		}
+
+		var names []string
+		var pages int
+		for cursor := uint(0); ; pages++ {
+			page, err := (&Category{}).FindAfter(rep, cursor, 3)
+			require.NoError(t, err)
+			if len(*page) == 0 {
+				break
+			}
+			assert.LessOrEqual(t, len(*page), 3)
+			names = append(names, categoryNames(*page)...)
+			cursor = (*page)[len(*page)-1].ID
+			if pages == 0 {
+				// the category inserted during the walk is read once at the end, without shifting the pages.
+				_, err := NewCategory("Category 8").Create(rep)
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Equal(t, 3, pages)
+		assert.Equal(t, []string{"Category 1", "Category 2", "Category 3", "Category 4", "Category 5", "Category 6",
+			"Category 7", "Category 8"}, names)
+	})
+}
+
+func TestCategory_FindAfterInvalidLimit(t *testing.T) {
+	rep, _ := prepareForObservedModelTest(t)
+
+	_, err := (&Category{}).FindAfter(rep, 0, 0)
+
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
+
+func TestGetOrCreateByName_CreatedOnce(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		first, created, err := GetOrCreateByName(rep, "Novel")
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		second, created, err := GetOrCreateByName(rep, "Novel")
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, first.ID, second.ID)
+		count, err := (&Category{}).CountByID(rep, first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestCategory_FindOneByName(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, err := (&Category{}).FindOneByName(rep, "Novel")
+		assert.ErrorIs(t, err, apperror.ErrNotFound)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		novel, _ := NewCategory("Novel").Create(rep)
+		found, err := (&Category{}).FindOneByName(rep, "Novel　")
+		if assert.NoError(t, err) {
+			assert.Equal(t, novel.ID, found.ID)
+		}
+
+		// the duplicate names are possible before the unique index is created.
+		require.NoError(t, rep.DB().Migrator().DropIndex(&Category{}, "Name"))
+		require.NoError(t, rep.Create(NewCategory("Novel")).Error)
+		_, err = (&Category{}).FindOneByName(rep, "Novel")
+		assert.ErrorIs(t, err, ErrMultipleRecords)
+		assert.Equal(t, apperror.Internal, apperror.CodeOf(err))
+	})
+}
+
+func TestCategory_FindOneByID(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		assert.True(t, (&Category{}).FindByID(rep, 1).IsNone())
+
+		novel, _ := NewCategory("Novel").Create(rep)
+		found, err := (&Category{}).FindByID(rep, novel.ID).Take()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Novel", found.Name)
+		}
+		err = rep.Transaction(func(tx repository.Repository) error {
+			_, err := (&Category{}).FindByIDForUpdate(tx, novel.ID)
+			return err
+		})
+		assert.NoError(t, err)
+		// the IDs are the primary key, so the two rows of an ID are checked by the condition which isn't unique.
+		_, _ = NewCategory("Magazine").Create(rep)
+		var category Category
+		err = findOne(rep, rep.Where("id IN ?", []uint{novel.ID, novel.ID + 1}), &category, "failed to find the category")
+		assert.ErrorIs(t, err, ErrMultipleRecords)
+	})
+}
+
+func TestCategory_Reload(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		novel, _ := NewCategory("Novel").Create(rep)
+		stale := novel.Clone()
+		require.NoError(t, rep.Model(&Category{}).Where("id = ?", novel.ID).Update("name", "Fiction").Error)
+
+		assert.NoError(t, stale.Reload(rep))
+		assert.Equal(t, novel.ID, stale.ID)
+		assert.Equal(t, "Fiction", stale.Name)
+
+		_, err := stale.Delete(rep)
+		require.NoError(t, err)
+		err = stale.Reload(rep)
+		assert.ErrorIs(t, err, apperror.ErrNotFound)
+		assert.Equal(t, "Fiction", stale.Name)
+	})
+}
+
+func TestCategory_CreateReadOnly(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		novel, _ := NewCategory("Novel").Create(rep)
+		readOnly := rep.ReadOnly()
+
+		_, err := NewCategory("Magazine").Create(readOnly)
+
+		assert.ErrorIs(t, err, repository.ErrReadOnlyRepository)
+		categories, err := (&Category{}).FindAll(readOnly)
+		if assert.NoError(t, err) && assert.Len(t, *categories, 1) {
+			assert.Equal(t, novel.ID, (*categories)[0].ID)
+		}
+		found, err := (&Category{}).FindByID(readOnly, novel.ID).Take()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Novel", found.Name)
+		}
+	})
+}
+
+func TestCategory_AllIDs(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		ids, err := (&Category{}).AllIDs(rep)
+		assert.NoError(t, err)
+		assert.NotNil(t, ids)
+		assert.Empty(t, ids)
+
+		novel, _ := NewCategory("Novel").Create(rep)
+		magazine, _ := NewCategory("Magazine").Create(rep)
+		ids, err = (&Category{}).AllIDs(rep)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{novel.ID, magazine.ID}, ids)
+	})
+}
+
+func TestCategory_CountDistinct(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		count, err := (&Category{}).CountDistinct(rep, "parent_id")
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		novel, _ := NewCategory("Novel").Create(rep)
+		magazine, _ := NewCategory("Magazine").Create(rep)
+		for _, child := range []*Category{
+			{Name: "Mystery", ParentID: &novel.ID}, {Name: "Fantasy", ParentID: &novel.ID},
+			{Name: "Weekly", ParentID: &magazine.ID}, {Name: "Monthly", ParentID: &magazine.ID},
+		} {
+			_, err := child.Create(rep)
+			assert.NoError(t, err)
+		}
+
+		count, err = (&Category{}).CountDistinct(rep, "parent_id")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		count, err = (&Category{}).CountDistinct(rep, "name")
+		assert.NoError(t, err)
+		assert.Equal(t, 6, count)
+		_, err = (&Category{}).CountDistinct(rep, "name) FROM account_master --")
+		assert.ErrorIs(t, err, ErrUnknownColumn)
+	})
+}
+
+func TestCategory_CreateIdempotent(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		first, err := NewCategory("Novel").CreateIdempotent(rep, "message-1")
+		require.NoError(t, err)
+		retried, err := NewCategory("Novel").CreateIdempotent(rep, "message-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, retried.ID)
+		ids, err := (&Category{}).AllIDs(rep)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{first.ID}, ids)
+
+		_, err = NewCategory("Novel").CreateIdempotent(rep, "message-2")
+		assert.ErrorIs(t, err, apperror.ErrConflict)
+		_, err = NewCategory("Magazine").CreateIdempotent(rep, "")
+		assert.ErrorIs(t, err, ErrEmptyIdempotencyKey)
+		magazine, err := NewCategory("Magazine").Create(rep)
+		assert.NoError(t, err)
+		assert.Nil(t, magazine.IdempotencyKey)
+		_, err = NewCategory("Comic").Create(rep)
+		assert.NoError(t, err)
+	})
+}
+
+func TestCategory_CreateIdempotent_Concurrent(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+
+	var wg sync.WaitGroup
+	ids := make(chan uint, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := NewCategory("Novel").CreateIdempotent(rep, "message-1")
+			if assert.NoError(t, err) {
+				ids <- c.ID
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	all, err := (&Category{}).AllIDs(rep)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 1) {
+		for id := range ids {
+			assert.Equal(t, all[0], id)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package model
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+)
+
+// importBatchSize is the number of the rows imported by a transaction when ImportOptions.BatchSize isn't given.
+const importBatchSize = 1000
+
+var (
+	// ErrInvalidBatchSize is returned when the size of a batch is negative.
+	ErrInvalidBatchSize = apperror.New(apperror.Validation, "the batch size must not be negative")
+	// ErrInvalidImportHeader is returned when the header of the imported csv has no name column.
+	ErrInvalidImportHeader = apperror.New(apperror.Validation, "the header of the csv must have the name column")
+)
+
+// ImportOptions is the setting of ImportCSVStream.
+type ImportOptions struct {
+	// BatchSize is the number of the rows imported by a transaction. It is 1000 when it is zero.
+	BatchSize int
+	// AllOrNothing imports all rows in a transaction, which is rolled back by the first invalid row or failed batch.
+	// Otherwise, a failed batch is rolled back alone, and the other batches are imported.
+	AllOrNothing bool
+}
+
+// ImportProgress is the progress of ImportCSVStream reported after every batch.
+type ImportProgress struct {
+	// Batch is the number of the batch which has just been imported, starting from 1.
+	Batch     int `json:"batch"`
+	Processed int `json:"processed"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Failed    int `json:"failed"`
+}
+
+// ImportRowError is the error of a row which isn't imported, with its line in the csv.
+type ImportRowError struct {
+	Line int
+	Name string
+	Err  error
+}
+
+// Error returns the message of the error with the line.
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ImportRowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult is the result of ImportCSVStream, which is partial when it is stopped.
+type ImportResult struct {
+	ImportProgress
+	// Errors are the errors of the rows which aren't imported, in order of their lines.
+	Errors []*ImportRowError
+}
+
+// importRow is a row of the csv parsed into a category.
+type importRow struct {
+	line     int
+	category Category
+}
+
+// categoryImporter reads the rows of the csv by the batches and upserts them.
+type categoryImporter struct {
+	reader    *csv.Reader
+	nameCol   int
+	parentCol int
+	opts      ImportOptions
+	progress  func(ImportProgress)
+	result    ImportResult
+}
+
+// ImportCSVStream imports the categories of the csv, whose header has the name column and optionally
+// the parent_id column, upserting them by their names like UpsertCategories. The rows are read incrementally
+// and imported by the transactions of ImportOptions.BatchSize rows, so a large file isn't held in memory.
+// The invalid rows and the rows of a failed batch are recorded in the errors of the result with their lines.
+// The progress, which may be nil, is called after every batch, such as to stream it to the client.
+// It stops between the batches when the context of the repository is done, and returns the partial result
+// with the error of the context.
+func ImportCSVStream(rep repository.Repository, r io.Reader, opts ImportOptions,
+	progress func(ImportProgress)) (*ImportResult, error) {
+	if opts.BatchSize < 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = importBatchSize
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.Validation, "failed to read the header of the csv")
+	}
+	im := &categoryImporter{reader: reader, nameCol: -1, parentCol: -1, opts: opts, progress: progress}
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "name":
+			im.nameCol = i
+		case "parent_id":
+			im.parentCol = i
+		}
+	}
+	if im.nameCol < 0 {
+		return nil, ErrInvalidImportHeader
+	}
+
+	if !opts.AllOrNothing {
+		return &im.result, im.run(rep)
+	}
+	if err := rep.Transaction(im.run); err != nil {
+		im.result.Created, im.result.Updated = 0, 0
+		return &im.result, err
+	}
+	return &im.result, nil
+}
+
+// run imports the batches until the end of the csv.
+func (im *categoryImporter) run(rep repository.Repository) error {
+	ctx := rep.DB().Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		rows, eof, err := im.readBatch()
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := im.importBatch(rep, rows); err != nil {
+				return err
+			}
+		}
+		if eof {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// readBatch reads the rows of the next batch, recording the invalid ones. It returns true at the end of the csv.
+func (im *categoryImporter) readBatch() ([]importRow, bool, error) {
+	rows := make([]importRow, 0, im.opts.BatchSize)
+	for read := 0; read < im.opts.BatchSize; read++ {
+		record, err := im.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, true, nil
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return rows, false, apperror.Wrap(err, apperror.Internal, "failed to read the csv")
+		}
+		im.result.Processed++
+		var row importRow
+		if parseErr != nil {
+			row.line = parseErr.StartLine
+			err = apperror.Wrap(parseErr, apperror.Validation, "the row isn't valid csv")
+		} else {
+			row.line, _ = im.reader.FieldPos(0)
+			err = im.parseRow(&row, record)
+		}
+		if err != nil {
+			im.fail(row.line, row.category.Name, err)
+			if im.opts.AllOrNothing {
+				return rows, false, im.result.Errors[len(im.result.Errors)-1]
+			}
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, false, nil
+}
+
+// parseRow parses the record into the category of the row, and validates it.
+func (im *categoryImporter) parseRow(row *importRow, record []string) error {
+	if im.nameCol >= len(record) {
+		return apperror.New(apperror.Validation, "the row has no name")
+	}
+	row.category.Name = NormalizeName(record[im.nameCol])
+	if im.parentCol >= 0 && im.parentCol < len(record) && strings.TrimSpace(record[im.parentCol]) != "" {
+		parentID, err := strconv.ParseUint(strings.TrimSpace(record[im.parentCol]), 10, 0)
+		if err != nil {
+			return apperror.Wrap(err, apperror.Validation, "the parent_id isn't a number")
+		}
+		id := uint(parentID)
+		row.category.ParentID = &id
+	}
+	return row.category.Validate()
+}
+
+// importBatch upserts the rows of the batch in a transaction, and reports the progress.
+// The rows of the batch are recorded as failed when it is rolled back.
+func (im *categoryImporter) importBatch(rep repository.Repository, rows []importRow) error {
+	// a statement can't update the same row twice, so the later row of the same name wins.
+	unique := make([]Category, 0, len(rows))
+	index := map[string]int{}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if i, ok := index[row.category.Name]; ok {
+			unique[i] = row.category
+			continue
+		}
+		index[row.category.Name] = len(unique)
+		unique = append(unique, row.category)
+		names = append(names, row.category.Name)
+	}
+
+	var existing int64
+	err := rep.Transaction(func(tx repository.Repository) error {
+		if err := tx.Model(&Category{}).Where("name IN ?", names).Count(&existing).Error; err != nil {
+			return err
+		}
+		return tx.DB().Clauses(categoryUpsert).CreateInBatches(&unique, upsertBatchSize).Error
+	})
+	im.result.Batch++
+	if err != nil {
+		err = wrapError(rep, err, "failed to import the batch of the categories")
+		for _, row := range rows {
+			im.fail(row.line, row.category.Name, err)
+		}
+		if im.opts.AllOrNothing {
+			return err
+		}
+	} else {
+		im.result.Created += len(unique) - int(existing)
+		im.result.Updated += len(rows) - len(unique) + int(existing)
+	}
+	if im.progress != nil {
+		im.progress(im.result.ImportProgress)
+	}
+	return nil
+}
+
+// fail records the row which isn't imported.
+func (im *categoryImporter) fail(line int, name string, err error) {
+	im.result.Failed++
+	im.result.Errors = append(im.result.Errors, &ImportRowError{Line: line, Name: name, Err: err})
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"gorm.io/gorm"
+)
+
+// importCSV returns the csv of the categories named by their numbers from 1 to n,
+// whose rows of the given numbers are replaced.
+func importCSV(n int, replaced map[int]string) string {
+	var builder strings.Builder
+	builder.WriteString("name,parent_id\n")
+	for i := 1; i <= n; i++ {
+		if row, ok := replaced[i]; ok {
+			builder.WriteString(row + "\n")
+			continue
+		}
+		fmt.Fprintf(&builder, "Category %05d,\n", i)
+	}
+	return builder.String()
+}
+
+// failCreatingCategory makes the statements which create the category of the name fail.
+func failCreatingCategory(t *testing.T, rep repository.Repository, name string) {
+	err := rep.DB().Callback().Create().Before("gorm:create").Register("test:fail", func(db *gorm.DB) {
+		if categories, ok := db.Statement.Dest.([]Category); ok {
+			for _, category := range categories {
+				if category.Name == name {
+					_ = db.AddError(fmt.Errorf("failed to create %s", name))
+				}
+			}
+		}
+	})
+	require.NoError(t, err)
+}
+
+func TestImportCSVStream(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	_, err := NewCategory("Category 00001").Create(rep)
+	require.NoError(t, err)
+	// the empty line is skipped, and the bare quote is the error of its row.
+	bad := map[int]string{500: " ,", 1234: "Category 01234,x", 7777: "", 9999: "Category \"09999,1"}
+
+	var progress []ImportProgress
+	result, err := ImportCSVStream(rep, strings.NewReader(importCSV(10000, bad)), ImportOptions{},
+		func(p ImportProgress) { progress = append(progress, p) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, ImportProgress{Batch: 10, Processed: 9999, Created: 9995, Updated: 1, Failed: 3},
+		result.ImportProgress)
+	if assert.Len(t, result.Errors, 3) {
+		assert.Equal(t, []int{501, 1235, 10000},
+			[]int{result.Errors[0].Line, result.Errors[1].Line, result.Errors[2].Line})
+		assert.ErrorIs(t, result.Errors[0], apperror.ErrValidation)
+	}
+	if assert.Len(t, progress, 10) {
+		assert.Equal(t, ImportProgress{Batch: 1, Processed: 1000, Created: 998, Updated: 1, Failed: 1}, progress[0])
+		assert.Equal(t, result.ImportProgress, progress[9])
+	}
+	count, err := (&Category{}).CountDistinct(rep, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, 9996, count)
+}
+
+func TestImportCSVStream_FailedBatch(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	failCreatingCategory(t, rep, "Category 00015")
+
+	result, err := ImportCSVStream(rep, strings.NewReader(importCSV(30, nil)), ImportOptions{BatchSize: 10}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ImportProgress{Batch: 3, Processed: 30, Created: 20, Failed: 10}, result.ImportProgress)
+	if assert.Len(t, result.Errors, 10) {
+		assert.Equal(t, 12, result.Errors[0].Line)
+		assert.Equal(t, "Category 00011", result.Errors[0].Name)
+	}
+	names, err := (&Category{}).FindByNames(rep, []string{"Category 00010", "Category 00011", "Category 00021"})
+	assert.NoError(t, err)
+	assert.Len(t, *names, 2)
+}
+
+func TestImportCSVStream_AllOrNothing(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	failCreatingCategory(t, rep, "Category 00015")
+
+	result, err := ImportCSVStream(rep, strings.NewReader(importCSV(30, nil)),
+		ImportOptions{BatchSize: 10, AllOrNothing: true}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, ImportProgress{Batch: 2, Processed: 20, Failed: 10}, result.ImportProgress)
+	ids, err := (&Category{}).AllIDs(rep)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	_, err = ImportCSVStream(rep, strings.NewReader(importCSV(30, map[int]string{5: ","})),
+		ImportOptions{BatchSize: 10, AllOrNothing: true}, nil)
+
+	var rowErr *ImportRowError
+	if assert.ErrorAs(t, err, &rowErr) {
+		assert.Equal(t, 6, rowErr.Line)
+	}
+	ids, err = (&Category{}).AllIDs(rep)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestImportCSVStream_Cancel(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result, err := ImportCSVStream(rep.WithContext(ctx), strings.NewReader(importCSV(100, nil)),
+		ImportOptions{BatchSize: 10}, func(p ImportProgress) {
+			if p.Batch == 2 {
+				cancel()
+			}
+		})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ImportProgress{Batch: 2, Processed: 20, Created: 20}, result.ImportProgress)
+	ids, err := (&Category{}).AllIDs(rep)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 20)
+}
+
+func TestImportCSVStream_InvalidInput(t *testing.T) {
+	rep := prepareForModelTest(t, repository.SQLITE, "")
+
+	_, err := ImportCSVStream(rep, strings.NewReader("title\nNovel\n"), ImportOptions{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidImportHeader)
+	_, err = ImportCSVStream(rep, strings.NewReader(""), ImportOptions{}, nil)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	_, err = ImportCSVStream(rep, strings.NewReader("name\nNovel\n"), ImportOptions{BatchSize: -1}, nil)
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+}
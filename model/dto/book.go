@@ -27,6 +27,29 @@ func NewBookDto(messages map[string]string) *BookDto {
 	return &BookDto{messages: messages}
 }
 
+// bookDtoJSON is the DTO in json, whose category ID is the token when the encoding of the IDs is enabled.
+type bookDtoJSON struct {
+	Title      string          `json:"title"`
+	Isbn       string          `json:"isbn"`
+	CategoryID model.EncodedID `json:"categoryId"`
+	FormatID   uint            `json:"formatId"`
+}
+
+// MarshalJSON writes this DTO, whose category ID is the token when the encoding of the IDs is enabled.
+func (b BookDto) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bookDtoJSON{Title: b.Title, Isbn: b.Isbn, CategoryID: model.EncodedID(b.CategoryID),
+		FormatID: b.FormatID})
+}
+
+// UnmarshalJSON reads the DTO whose category ID is the token when the encoding of the IDs is enabled.
+// It returns model.InvalidIDError when the category ID isn't an ID. The fields read before the error are kept.
+func (b *BookDto) UnmarshalJSON(data []byte) error {
+	aux := bookDtoJSON{Title: b.Title, Isbn: b.Isbn, CategoryID: model.EncodedID(b.CategoryID), FormatID: b.FormatID}
+	err := json.Unmarshal(data, &aux)
+	b.Title, b.Isbn, b.CategoryID, b.FormatID = aux.Title, aux.Isbn, uint(aux.CategoryID), aux.FormatID
+	return err
+}
+
 // Create creates a book model from this DTO.
 func (b *BookDto) Create() *model.Book {
 	return model.NewBook(b.Title, b.Isbn, b.CategoryID, b.FormatID)
@@ -1,9 +1,12 @@
 package dto
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/model"
 )
 
 const (
@@ -86,7 +89,23 @@ func TestValidate_Isbn21Error(t *testing.T) {
 func TestToString(t *testing.T) {
 	dto := createBookForTitle4()
 	result, _ := dto.ToString()
-	assert.Equal(t, "{\"title\":\"Test\",\"isbn\":\"123-123-123-1\",\"categoryId\":1,\"formatId\":1}", result)
+	assert.Equal(t, "{\"title\":\"Test\",\"isbn\":\"978-4-87311-842-0\",\"categoryId\":1,\"formatId\":1}", result)
+}
+
+func TestUnmarshalJSON_EncodedCategoryID(t *testing.T) {
+	require.NoError(t, model.SetIDEncodingKey("secret"))
+	t.Cleanup(func() { _ = model.SetIDEncodingKey("") })
+	token := model.EncodeID(3)
+
+	dto := NewBookDto(createValidationMessages())
+	err := json.Unmarshal([]byte(`{"title":"Test","isbn":"978-4-87311-842-0","categoryId":"`+token+`","formatId":1}`), dto)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), dto.CategoryID)
+	result, _ := dto.ToString()
+	assert.Equal(t, `{"title":"Test","isbn":"978-4-87311-842-0","categoryId":"`+token+`","formatId":1}`, result)
+	var invalidErr *model.InvalidIDError
+	assert.ErrorAs(t, json.Unmarshal([]byte(`{"categoryId":"3"}`), dto), &invalidErr)
 }
 
 func createValidationMessages() map[string]string {
@@ -98,7 +117,7 @@ func createValidationMessages() map[string]string {
 func createBookForTitle2() *BookDto {
 	return &BookDto{
 		Title:      "Te",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
@@ -108,7 +127,7 @@ func createBookForTitle2() *BookDto {
 func createBookForTitle3() *BookDto {
 	return &BookDto{
 		Title:      "Tes",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
@@ -118,7 +137,7 @@ func createBookForTitle3() *BookDto {
 func createBookForTitle4() *BookDto {
 	return &BookDto{
 		Title:      "Test",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
@@ -128,7 +147,7 @@ func createBookForTitle4() *BookDto {
 func createBookForTitle49() *BookDto {
 	return &BookDto{
 		Title:      "Test012345Test012345Test012345Test012345Test01234",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
@@ -138,7 +157,7 @@ func createBookForTitle49() *BookDto {
 func createBookForTitle50() *BookDto {
 	return &BookDto{
 		Title:      "Test012345Test012345Test012345Test012345Test012345",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
@@ -148,7 +167,7 @@ func createBookForTitle50() *BookDto {
 func createBookForTitle51() *BookDto {
 	return &BookDto{
 		Title:      "Test012345Test012345Test012345Test012345Test012345T",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 		messages:   createValidationMessages(),
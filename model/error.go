@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// wrapError returns err as apperror.Error, whose code is Timeout when the query ran out of time,
+// Conflict when it violated an unique constraint, NotFound when no row is found, and Internal otherwise.
+// It returns nil when err is nil, and apperror.Error as it is. The unique constraint is judged by the error codes
+// of the dialect of rep.
+func wrapError(rep repository.Repository, err error, msg string) error {
+	var appErr *apperror.Error
+	switch {
+	case err == nil || errors.As(err, &appErr):
+		return err
+	case errors.Is(err, repository.ErrQueryTimeout) || errors.Is(err, context.DeadlineExceeded):
+		return apperror.Wrap(err, apperror.Timeout, msg)
+	case rep.IsDuplicateKeyError(err):
+		return apperror.Wrap(err, apperror.Conflict, msg)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.Wrap(err, apperror.NotFound, msg)
+	default:
+		return apperror.Wrap(err, apperror.Internal, msg)
+	}
+}
+
+// ErrMultipleRecords is returned by the single-row finders when more than one row matches the condition
+// which is expected to be unique, so the broken data surfaces instead of an arbitrary row being returned.
+var ErrMultipleRecords = apperror.New(apperror.Internal, "more than one record matches")
+
+// findOne is the pattern of the single-row finders, such as FindOneByName, which the finders of the other models
+// should follow too. It reads at most two rows of the query in order of the primary key into dest,
+// and returns the error of gorm.ErrRecordNotFound wrapped as NotFound when no row matches,
+// and ErrMultipleRecords when more than one row matches. Use First or Take instead when any row will do.
+func findOne[T any](rep repository.Repository, db *gorm.DB, dest *T, msg string) error {
+	var rows []T
+	tx := db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}}).
+		Limit(2)
+	// the same as First and Take, so no row is logged as not found too.
+	tx.Statement.RaiseErrorOnNotFound = true
+	switch err := tx.Find(&rows).Error; {
+	case err != nil:
+		return wrapError(rep, err, msg)
+	case len(rows) > 1:
+		return apperror.Wrap(ErrMultipleRecords, apperror.Internal, msg)
+	}
+	*dest = rows[0]
+	return nil
+}
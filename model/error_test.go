@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+)
+
+func TestWrapError(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		format := NewFormat("Paper")
+		require.NoError(t, rep.Create(format).Error)
+		err := rep.Create(&Format{ID: format.ID, Name: "E-Book"}).Error
+
+		assert.Equal(t, apperror.Conflict, apperror.CodeOf(wrapError(rep, err, "failed to create the format")))
+		assert.Equal(t, apperror.Internal, apperror.CodeOf(wrapError(rep, errors.New("test"), "failed")))
+		assert.NoError(t, wrapError(rep, nil, "failed"))
+	})
+}
+
+func TestFindOne(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		require.NoError(t, rep.Create(NewFormat("Paper")).Error)
+
+		var format Format
+		err := findOne(rep, rep.Where("name = ?", "Paper"), &format, "failed to find the format")
+		assert.NoError(t, err)
+		assert.Equal(t, "Paper", format.Name)
+
+		err = findOne(rep, rep.Where("name = ?", "E-Book"), &format, "failed to find the format")
+		assert.ErrorIs(t, err, apperror.ErrNotFound)
+	})
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+)
+
+// idEncoding is the cipher which encodes the IDs in json. The IDs are written as the numbers when it is nil.
+var idEncoding atomic.Pointer[cipher.Block]
+
+// InvalidIDError is returned when a token isn't an ID encoded by this application.
+type InvalidIDError struct {
+	Token string
+}
+
+// Error returns the invalid token.
+func (e *InvalidIDError) Error() string {
+	return "invalid id: " + strconv.Quote(e.Token)
+}
+
+// SetIDEncodingKey enables the encoding of the IDs of the categories in json by the key,
+// so the responses don't expose the auto-increment IDs. The empty key disables it.
+// It is called once at startup, because the tokens issued by another key can't be decoded.
+func SetIDEncodingKey(key string) error {
+	if key == "" {
+		idEncoding.Store(nil)
+		return nil
+	}
+	// the key is derived from the setting, so it can be a passphrase of any length.
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return err
+	}
+	idEncoding.Store(&block)
+	return nil
+}
+
+// EncodeID returns the token of the ID, which is the same for the same ID and key.
+// It returns the decimal ID when the encoding is disabled.
+func EncodeID(id uint) string {
+	block := idEncoding.Load()
+	if block == nil {
+		return strconv.FormatUint(uint64(id), 10)
+	}
+	// a block is the ID followed by zeros, which are checked by DecodeID to reject the forged tokens.
+	var data [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(data[:8], uint64(id))
+	(*block).Encrypt(data[:], data[:])
+	return base64.RawURLEncoding.EncodeToString(data[:])
+}
+
+// DecodeID returns the ID of the token returned by EncodeID, such as the ID given to a controller.
+// It returns InvalidIDError when the token isn't an ID, instead of zero.
+func DecodeID(token string) (uint, error) {
+	block := idEncoding.Load()
+	if block == nil {
+		id, err := strconv.ParseUint(token, 10, strconv.IntSize)
+		if err != nil {
+			return 0, &InvalidIDError{Token: token}
+		}
+		return uint(id), nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) != aes.BlockSize {
+		return 0, &InvalidIDError{Token: token}
+	}
+	(*block).Decrypt(data, data)
+	id := binary.BigEndian.Uint64(data[:8])
+	if binary.BigEndian.Uint64(data[8:]) != 0 || uint64(uint(id)) != id {
+		return 0, &InvalidIDError{Token: token}
+	}
+	return uint(id), nil
+}
+
+// EncodedID is an ID in json, which is the token of EncodeID when the encoding is enabled, and the number otherwise.
+type EncodedID uint
+
+// MarshalJSON writes the token or the number of the ID.
+func (id EncodedID) MarshalJSON() ([]byte, error) {
+	if idEncoding.Load() == nil {
+		return strconv.AppendUint(nil, uint64(id), 10), nil
+	}
+	return json.Marshal(EncodeID(uint(id)))
+}
+
+// UnmarshalJSON reads the token or the number of the ID. It returns InvalidIDError when it isn't an ID.
+func (id *EncodedID) UnmarshalJSON(data []byte) error {
+	token := string(data)
+	if idEncoding.Load() != nil {
+		if err := json.Unmarshal(data, &token); err != nil {
+			return &InvalidIDError{Token: string(data)}
+		}
+	}
+	decoded, err := DecodeID(token)
+	if err != nil {
+		return err
+	}
+	*id = EncodedID(decoded)
+	return nil
+}
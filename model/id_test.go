@@ -0,0 +1,109 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableIDEncoding enables the encoding of the IDs by the key during the test.
+func enableIDEncoding(t *testing.T, key string) {
+	require.NoError(t, SetIDEncodingKey(key))
+	t.Cleanup(func() { _ = SetIDEncodingKey("") })
+}
+
+func TestEncodeID_RoundTrip(t *testing.T) {
+	enableIDEncoding(t, "secret")
+
+	for _, id := range []uint{0, 1, 2, 1000, math.MaxUint32, math.MaxUint} {
+		token := EncodeID(id)
+		decoded, err := DecodeID(token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, decoded)
+		assert.NotContains(t, token, "=")
+	}
+}
+
+func TestEncodeID_Stable(t *testing.T) {
+	enableIDEncoding(t, "secret")
+	token := EncodeID(1)
+
+	assert.Equal(t, token, EncodeID(1))
+	assert.NotEqual(t, token, EncodeID(2))
+	require.NoError(t, SetIDEncodingKey("secret"))
+	assert.Equal(t, token, EncodeID(1))
+	require.NoError(t, SetIDEncodingKey("another"))
+	assert.NotEqual(t, token, EncodeID(1))
+}
+
+func TestDecodeID_Invalid(t *testing.T) {
+	enableIDEncoding(t, "secret")
+	token := EncodeID(1)
+	tampered := []byte(token)
+	tampered[0] ^= 1
+
+	for _, invalid := range []string{"", "1", "not a token", token[:10], token + "A", string(tampered)} {
+		id, err := DecodeID(invalid)
+
+		var invalidErr *InvalidIDError
+		assert.True(t, errors.As(err, &invalidErr), invalid)
+		assert.Zero(t, id)
+	}
+	require.NoError(t, SetIDEncodingKey("another"))
+	_, err := DecodeID(token)
+	assert.IsType(t, &InvalidIDError{}, err)
+}
+
+func TestDecodeID_Disabled(t *testing.T) {
+	assert.Equal(t, "12", EncodeID(12))
+	id, err := DecodeID("12")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(12), id)
+	_, err = DecodeID("abc")
+	assert.IsType(t, &InvalidIDError{}, err)
+}
+
+func TestCategory_JSONEncodedID(t *testing.T) {
+	enableIDEncoding(t, "secret")
+	parentID := uint(1)
+	category := &Category{ID: 2, Name: "Novel", ParentID: &parentID}
+
+	data, err := json.Marshal(category)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"`+EncodeID(2)+`","name":"Novel","parentId":"`+EncodeID(1)+`"}`, string(data))
+	assert.JSONEq(t, string(data), category.ToString())
+
+	var decoded Category
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *category, decoded)
+	assert.IsType(t, &InvalidIDError{}, json.Unmarshal([]byte(`{"id":"forged"}`), &decoded))
+}
+
+func TestCategory_JSONNotEncoded(t *testing.T) {
+	category := &Category{ID: 2, Name: "Novel"}
+
+	assert.JSONEq(t, `{"id":2,"name":"Novel"}`, category.ToString())
+}
+
+func TestPage_JSONEncodedID(t *testing.T) {
+	enableIDEncoding(t, "secret")
+	books := []Book{{ID: 1, Title: "Title", CategoryID: 2, Category: &Category{ID: 2, Name: "Novel"}}}
+	page := &Page{Content: &books}
+
+	data, err := json.Marshal(page)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	book := decoded["content"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, EncodeID(2), book["categoryId"])
+	assert.Equal(t, EncodeID(2), book["category"].(map[string]interface{})["id"])
+	var roundTrip Page
+	require.NoError(t, json.Unmarshal(data, &roundTrip))
+	assert.Equal(t, books, *roundTrip.Content)
+}
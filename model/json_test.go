@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestMarshalJSON_Golden(t *testing.T) {
+	parentID := uint(1)
+	tests := []struct {
+		golden string
+		model  interface{ ToString() string }
+	}{
+		{"testdata/category_full.golden", &Category{ID: 2, Name: "Novel", ParentID: &parentID}},
+		{"testdata/category_minimal.golden", &Category{Name: "Novel"}},
+		{"testdata/book_full.golden", &Book{ID: 3, Title: "Go", Isbn: "978-4-87311-842-0", CategoryID: 2,
+			Category: &Category{ID: 2, Name: "Novel", ParentID: &parentID}, FormatID: 1, Format: &Format{ID: 1, Name: "Paper"}}},
+		{"testdata/book_minimal.golden", NewBook("Go", "978-4-87311-842-0", 2, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			output := tt.model.ToString()
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(tt.golden, []byte(output+"\n"), 0o600))
+			}
+			expected, err := os.ReadFile(tt.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), output+"\n")
+		})
+	}
+}
+
+func TestCategoryWithCount_JSON(t *testing.T) {
+	parentID := uint(1)
+	categories := []CategoryWithCount{
+		{Category: Category{ID: 2, Name: "Novel", ParentID: &parentID}, Count: 3},
+		{Category: Category{ID: 3, Name: "Empty"}},
+	}
+
+	data, err := json.Marshal(categories)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id":2,"name":"Novel","parentId":1,"count":3},{"id":3,"name":"Empty","count":0}]`, string(data))
+
+	var decoded []CategoryWithCount
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, categories, decoded)
+}
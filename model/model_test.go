@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	// PostgresDSNEnv is the environment variable has the DSN of PostgreSQL for testing.
+	PostgresDSNEnv = "TEST_POSTGRES_DSN"
+	// MySQLDSNEnv is the environment variable has the DSN of MySQL for testing.
+	MySQLDSNEnv = "TEST_MYSQL_DSN"
+)
+
+// forEachDialect runs a test against SQLite,
+// and also against PostgreSQL and MySQL when their DSN is given by the environment variables.
+func forEachDialect(t *testing.T, test func(t *testing.T, rep repository.Repository)) {
+	dsns := map[string]string{
+		repository.SQLITE:   "",
+		repository.POSTGRES: os.Getenv(PostgresDSNEnv),
+		repository.MYSQL:    os.Getenv(MySQLDSNEnv),
+	}
+	for _, dialect := range []string{repository.SQLITE, repository.POSTGRES, repository.MYSQL} {
+		dsn := dsns[dialect]
+		t.Run(dialect, func(t *testing.T) {
+			if dialect != repository.SQLITE && dsn == "" {
+				t.Skipf("DSN of %s isn't given", dialect)
+			}
+			test(t, prepareForModelTest(t, dialect, dsn))
+		})
+	}
+}
+
+// prepareForModelTest connects the database and creates the tables used in the model tests.
+func prepareForModelTest(t *testing.T, dialect string, dsn string) repository.Repository {
+	return prepareForModelTestWithLogger(t, dialect, dsn, logger.NewLogger(zaptest.NewLogger(t).Sugar()))
+}
+
+// prepareForObservedModelTest connects a SQLite database with the logger whose logs are observed by the test.
+func prepareForObservedModelTest(t *testing.T) (repository.Repository, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	rep := prepareForModelTestWithLogger(t, repository.SQLITE, "", logger.NewLogger(zap.New(core).Sugar()))
+	_ = logs.TakeAll()
+	return rep, logs
+}
+
+func prepareForModelTestWithLogger(t *testing.T, dialect string, dsn string, log logger.Logger) repository.Repository {
+	conf := &config.Config{}
+	conf.Database.Dialect = dialect
+	conf.Database.DSN = dsn
+	if dialect == repository.SQLITE {
+		conf.Database.DSN = fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	}
+
+	rep := repository.NewBookRepository(log, conf)
+	t.Cleanup(func() { _ = rep.Close() })
+
+	models := []interface{}{&Book{}, &Category{}, &Format{}, &Account{}, &Authority{}}
+	for _, m := range models {
+		require.NoError(t, rep.DropTableIfExists(m))
+	}
+	for _, m := range models {
+		require.NoError(t, rep.AutoMigrate(m))
+	}
+	return rep
+}
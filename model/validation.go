@@ -0,0 +1,178 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// reservedNames is the list of the names which can't be used, because they have a special meaning.
+var reservedNames = []string{"uncategorized"}
+
+// validationMessages is the readable messages of the validation rules.
+var validationMessages = map[string]string{
+	"required":     "is required",
+	"min":          "must be at least %s characters",
+	"max":          "must be at most %s characters",
+	"notblank":     "must not be blank, have leading or trailing spaces, or contain control characters",
+	"isbn13":       "must be a valid ISBN-13",
+	"reservedname": "is a reserved name",
+}
+
+var (
+	validate       *validator.Validate
+	validateOnce   sync.Once
+	validateMutex  sync.Mutex
+	registeredTags = map[string]bool{}
+)
+
+// ValidationError represents a field which violates a validation rule.
+type ValidationError struct {
+	// Field is the name of the field in json, such as name.
+	Field   string
+	Tag     string
+	Message string
+}
+
+// Error returns the field and the message.
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors represents all fields which violate the validation rules.
+type ValidationErrors []*ValidationError
+
+// Error returns the messages of all fields.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Is reports whether the target is apperror.ErrValidation, so these errors have the code of Validation.
+func (e ValidationErrors) Is(target error) bool {
+	return target == apperror.ErrValidation
+}
+
+// getValidator returns the validator which has the built-in rules of this package.
+// The rules are registered only once, however many times it is called.
+func getValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(jsonFieldName)
+		for tag, fn := range map[string]validator.Func{
+			"notblank":     notBlank,
+			"isbn13":       isISBN13,
+			"reservedname": notReservedName,
+		} {
+			_ = validate.RegisterValidation(tag, fn)
+			registeredTags[tag] = true
+		}
+	})
+	return validate
+}
+
+// RegisterValidation registers a custom validation rule which is used by the tag in the struct tags.
+// It is called once at startup, before any validation runs.
+// It fails when a rule of the tag has already been registered, including the built-in rules of this package.
+func RegisterValidation(tag string, fn validator.Func) error {
+	v := getValidator()
+	validateMutex.Lock()
+	defer validateMutex.Unlock()
+	if registeredTags[tag] {
+		return fmt.Errorf("the validation rule %s has already been registered", tag)
+	}
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	registeredTags[tag] = true
+	return nil
+}
+
+// validateStruct validates the struct by its validate tags, and returns ValidationErrors when it is invalid.
+func validateStruct(s interface{}) error {
+	err := getValidator().Struct(s)
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+	result := make(ValidationErrors, 0, len(errs))
+	for _, e := range errs {
+		result = append(result, &ValidationError{Field: e.Field(), Tag: e.Tag(), Message: validationMessage(e)})
+	}
+	return result
+}
+
+// validationMessage returns the readable message of the violated rule.
+func validationMessage(e validator.FieldError) string {
+	message, ok := validationMessages[e.Tag()]
+	if !ok {
+		return fmt.Sprintf("violates the %s rule", e.Tag())
+	}
+	if strings.Contains(message, "%s") {
+		return fmt.Sprintf(message, e.Param())
+	}
+	return message
+}
+
+// jsonFieldName returns the name of the field in json, which the clients know.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// NormalizeName trims the leading and trailing whitespace of the name, including the full-width space U+3000,
+// and collapses the internal runs of whitespace to a single space, so the pasted names match the existing ones.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// notBlank checks that the string isn't blank, has no leading or trailing spaces and has no control characters.
+func notBlank(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if strings.TrimSpace(s) == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsControl) < 0
+}
+
+// isISBN13 checks that the string is an ISBN-13 whose check digit is correct. Hyphens and spaces are ignored.
+func isISBN13(fl validator.FieldLevel) bool {
+	digits := strings.NewReplacer("-", "", " ", "").Replace(fl.Field().String())
+	if len(digits) != 13 || !(strings.HasPrefix(digits, "978") || strings.HasPrefix(digits, "979")) {
+		return false
+	}
+	sum := 0
+	for i, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+	return sum%10 == 0
+}
+
+// notReservedName checks that the string isn't one of the reserved names, regardless of the case.
+func notReservedName(fl validator.FieldLevel) bool {
+	s := strings.TrimSpace(fl.Field().String())
+	for _, name := range reservedNames {
+		if strings.EqualFold(s, name) {
+			return false
+		}
+	}
+	return true
+}
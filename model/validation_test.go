@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/apperror"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+func TestValidation_Rules(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{ Validate() error }
+		tag   string
+	}{
+		{name: "valid category", model: NewCategory("Novel")},
+		{name: "blank name", model: NewCategory("   "), tag: "notblank"},
+		{name: "leading space", model: NewCategory(" Novel"), tag: "notblank"},
+		{name: "trailing space", model: NewCategory("Novel "), tag: "notblank"},
+		{name: "control character", model: NewCategory("No\tvel"), tag: "notblank"},
+		{name: "reserved name", model: NewCategory("Uncategorized"), tag: "reservedname"},
+		{name: "empty name", model: NewCategory(""), tag: "required"},
+		{name: "valid isbn", model: NewBook("Title", "978-4-87311-842-0", 1, 1)},
+		{name: "valid isbn without hyphens", model: NewBook("Title", "9784873118420", 1, 1)},
+		{name: "wrong check digit", model: NewBook("Title", "978-4-87311-842-1", 1, 1), tag: "isbn13"},
+		{name: "isbn10", model: NewBook("Title", "4873118425", 1, 1), tag: "isbn13"},
+		{name: "not a number", model: NewBook("Title", "978-4-87311-84X-0", 1, 1), tag: "isbn13"},
+		{name: "blank title", model: NewBook(" Title", "9784873118420", 1, 1), tag: "notblank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.model.Validate()
+			if tt.tag == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var errs ValidationErrors
+			if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+				assert.Equal(t, tt.tag, errs[0].Tag)
+				assert.Equal(t, validationMessages[tt.tag], errs[0].Message)
+			}
+		})
+	}
+}
+
+func TestValidation_FieldNameAndMessage(t *testing.T) {
+	err := NewCategory("uncategorized").Validate()
+
+	assert.EqualError(t, err, "name is a reserved name")
+}
+
+func TestRegisterValidation(t *testing.T) {
+	type nickname struct {
+		Name string `validate:"lowercase_only" json:"name"`
+	}
+	lowercase := func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "abc"
+	}
+
+	assert.NoError(t, RegisterValidation("lowercase_only", lowercase))
+	assert.NoError(t, validateStruct(&nickname{Name: "abc"}))
+	assert.EqualError(t, validateStruct(&nickname{Name: "ABC"}), "name violates the lowercase_only rule")
+}
+
+func TestRegisterValidation_Duplicate(t *testing.T) {
+	always := func(validator.FieldLevel) bool { return true }
+
+	assert.ErrorContains(t, RegisterValidation("notblank", always), "already been registered")
+	assert.NoError(t, RegisterValidation("duplicate_test", always))
+	assert.ErrorContains(t, RegisterValidation("duplicate_test", always), "already been registered")
+	// the built-in rule isn't replaced by the rejected registration.
+	assert.Error(t, NewCategory(" ").Validate())
+}
+
+func TestCategory_CreateInvalid(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, err := NewCategory("uncategorized").Create(rep)
+
+		var errs ValidationErrors
+		assert.True(t, errors.As(err, &errs))
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		exist, _ := (&Category{}).Exist(rep, 1)
+		assert.False(t, exist)
+	})
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "ascii", input: "  Novel \t", want: "Novel"},
+		{name: "full-width", input: "　Novel　", want: "Novel"},
+		{name: "internal runs", input: "Science \t　 Fiction", want: "Science Fiction"},
+		{name: "mixed", input: "\n　 Light　　Novel \t", want: "Light Novel"},
+		{name: "blank", input: " \t　\n", want: ""},
+		{name: "normalized", input: "Novel", want: "Novel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeName(tt.input))
+		})
+	}
+}
+
+func TestCategory_CreateNormalizesName(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		created, err := NewCategory("　Light \t Novel ").Create(rep)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Light Novel", created.Name)
+		found, err := findByName(rep, " Light　Novel")
+		assert.NoError(t, err)
+		if assert.NotNil(t, found) {
+			assert.Equal(t, created.ID, found.ID)
+		}
+	})
+}
+
+func TestCategory_CreateBlankAfterNormalization(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, rep repository.Repository) {
+		_, err := NewCategory("　 \t").Create(rep)
+
+		var errs ValidationErrors
+		assert.True(t, errors.As(err, &errs))
+		exist, _ := (&Category{}).Exist(rep, 1)
+		assert.False(t, exist)
+	})
+}
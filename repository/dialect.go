@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"gorm.io/gorm"
+)
+
+// dialect defines how to connect the database and how to classify the errors of its driver.
+// Each dialect is registered by the file guarded by the build tag, so that the drivers which aren't needed
+// can be excluded from the binary. (e.g. go build -tags nomysql,nopostgres)
+type dialect struct {
+	open        func(conf *config.Config) gorm.Dialector
+	isDuplicate func(err error) bool
+	isDeadlock  func(err error) bool
+	// version is the query which returns the version of the database server.
+	version string
+}
+
+var dialects = map[string]*dialect{}
+
+// registerDialect registers the dialect by its name.
+func registerDialect(name string, d *dialect) {
+	dialects[name] = d
+}
+
+// openDialector returns the dialector of the dialect written in the configuration.
+func openDialector(conf *config.Config) (gorm.Dialector, error) {
+	name := dialectName(conf)
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported dialect: %s", name)
+	}
+	return d.open(conf), nil
+}
+
+// dialectName returns the name of the dialect written in the configuration, which is SQLite by default.
+func dialectName(conf *config.Config) string {
+	if conf.Database.Dialect == "" {
+		return SQLITE
+	}
+	return conf.Database.Dialect
+}
+
+// IsDuplicateKeyError judges whether a given error is caused by the violation of an unique constraint,
+// by the error codes of the dialect of this repository.
+func (rep *repository) IsDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	d, ok := dialects[rep.dialect]
+	return ok && d.isDuplicate(err)
+}
+
+// IsDeadlockError judges whether a given error is caused by a deadlock or a lock conflict,
+// by the error codes of the dialect of this repository.
+func (rep *repository) IsDeadlockError(err error) bool {
+	if err == nil {
+		return false
+	}
+	d, ok := dialects[rep.dialect]
+	return ok && d.isDeadlock(err)
+}
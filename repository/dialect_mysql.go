@@ -0,0 +1,43 @@
+//go:build !nomysql
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// The error numbers of MySQL.
+// ref: https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlDuplicateEntry = 1062
+	mysqlLockDeadlock   = 1213
+)
+
+func init() {
+	registerDialect(MYSQL, &dialect{
+		open: func(conf *config.Config) gorm.Dialector {
+			dsn := conf.Database.DSN
+			if dsn == "" {
+				dsn = fmt.Sprintf("%s:%s@(%s)/%s?charset=utf8&parseTime=True&loc=Local",
+					conf.Database.Username, conf.Database.Password,
+					conf.Database.Host, conf.Database.Dbname)
+			}
+			return mysql.Open(dsn)
+		},
+		isDuplicate: func(err error) bool {
+			var mysqlErr *mysqlDriver.MySQLError
+			return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+		},
+		isDeadlock: func(err error) bool {
+			var mysqlErr *mysqlDriver.MySQLError
+			return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlLockDeadlock
+		},
+		version: "SELECT VERSION()",
+	})
+}
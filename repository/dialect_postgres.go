@@ -0,0 +1,43 @@
+//go:build !nopostgres
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// The error codes of PostgreSQL.
+// ref: https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	postgresUniqueViolation  = "23505"
+	postgresDeadlockDetected = "40P01"
+)
+
+func init() {
+	registerDialect(POSTGRES, &dialect{
+		open: func(conf *config.Config) gorm.Dialector {
+			dsn := conf.Database.DSN
+			if dsn == "" {
+				dsn = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
+					conf.Database.Host, conf.Database.Port, conf.Database.Username,
+					conf.Database.Dbname, conf.Database.Password)
+			}
+			return postgres.Open(dsn)
+		},
+		isDuplicate: func(err error) bool {
+			var pgErr *pgconn.PgError
+			return errors.As(err, &pgErr) && pgErr.Code == postgresUniqueViolation
+		},
+		isDeadlock: func(err error) bool {
+			var pgErr *pgconn.PgError
+			return errors.As(err, &pgErr) && pgErr.Code == postgresDeadlockDetected
+		},
+		version: "SHOW server_version",
+	})
+}
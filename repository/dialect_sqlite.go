@@ -0,0 +1,55 @@
+//go:build !nosqlite
+
+package repository
+
+import (
+	"errors"
+
+	"github.com/glebarez/sqlite"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"gorm.io/gorm"
+)
+
+// The result codes of SQLite.
+// ref: https://www.sqlite.org/rescode.html
+const (
+	sqliteBusy                 = 5
+	sqliteLocked               = 6
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+	sqlitePrimaryResultMask    = 0xff
+)
+
+// sqliteError is the error of the SQLite driver which has a result code.
+type sqliteError interface {
+	error
+	Code() int
+}
+
+func init() {
+	registerDialect(SQLITE, &dialect{
+		open: func(conf *config.Config) gorm.Dialector {
+			if conf.Database.DSN != "" {
+				return sqlite.Open(conf.Database.DSN)
+			}
+			return sqlite.Open(conf.Database.Host)
+		},
+		isDuplicate: func(err error) bool {
+			var sqliteErr sqliteError
+			if errors.As(err, &sqliteErr) {
+				code := sqliteErr.Code()
+				return code == sqliteConstraintUnique || code == sqliteConstraintPrimaryKey
+			}
+			return false
+		},
+		isDeadlock: func(err error) bool {
+			var sqliteErr sqliteError
+			if errors.As(err, &sqliteErr) {
+				code := sqliteErr.Code() & sqlitePrimaryResultMask
+				return code == sqliteBusy || code == sqliteLocked
+			}
+			return false
+		},
+		version: "SELECT sqlite_version()",
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/config"
+)
+
+type uniqueRecord struct {
+	ID   uint   `gorm:"primary_key"`
+	Name string `gorm:"uniqueIndex"`
+}
+
+func TestOpenDialector_UnsupportedDialect(t *testing.T) {
+	conf := &config.Config{}
+	conf.Database.Dialect = "oracle"
+
+	_, err := openDialector(conf)
+
+	assert.EqualError(t, err, "unsupported dialect: oracle")
+}
+
+func TestOpenDialector_Default(t *testing.T) {
+	conf := &config.Config{}
+
+	dialector, err := openDialector(conf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", dialector.Name())
+}
+
+func TestIsDuplicateKeyError(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "test"}).Error)
+	err := rep.Create(&uniqueRecord{Name: "test"}).Error
+
+	assert.Error(t, err)
+	assert.True(t, rep.IsDuplicateKeyError(err))
+	assert.False(t, rep.IsDeadlockError(err))
+}
+
+func TestIsDuplicateKeyError_OtherError(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+
+	assert.False(t, rep.IsDuplicateKeyError(nil))
+	assert.False(t, rep.IsDuplicateKeyError(errors.New("test")))
+	assert.False(t, rep.IsDeadlockError(nil))
+	assert.False(t, rep.IsDeadlockError(errors.New("test")))
+}
+
+// codeError is the error which has the error code, such as the one of the SQLite driver.
+type codeError struct {
+	code int
+}
+
+func (e codeError) Error() string { return "test" }
+
+func (e codeError) Code() int { return e.code }
+
+func TestIsDuplicateKeyError_OtherDialect(t *testing.T) {
+	duplicate, busy := codeError{code: sqliteConstraintUnique}, codeError{code: sqliteBusy}
+
+	sqliteRep := &repository{dialect: SQLITE}
+	assert.True(t, sqliteRep.IsDuplicateKeyError(duplicate))
+	assert.True(t, sqliteRep.IsDeadlockError(busy))
+	for _, dialect := range []string{POSTGRES, MYSQL, "oracle"} {
+		rep := &repository{dialect: dialect}
+		assert.False(t, rep.IsDuplicateKeyError(duplicate), dialect)
+		assert.False(t, rep.IsDeadlockError(busy), dialect)
+	}
+}
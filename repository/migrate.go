@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+)
+
+// ErrIrreversibleMigration is returned by Rollback when an applied migration has no Down step.
+var ErrIrreversibleMigration = errors.New("the migration can't be rolled back")
+
+// Migration is a versioned change of the schema or the data, such as a backfill, which AutoMigrate can't express.
+// The migrations are applied in order of their versions by Migrate, each in a transaction with its record
+// in the schema_migrations table, so a failed migration leaves neither its changes nor its record.
+// Note that MySQL commits the DDL implicitly, so the DDL of a failed migration isn't rolled back on MySQL.
+type Migration struct {
+	// Version is the positive number which orders the migrations, such as 20240401093000.
+	Version int64
+	// Name describes the change, such as add_category_parent_id.
+	Name string
+	// Up applies the change.
+	Up func(tx Repository) error
+	// Down reverts the change for Rollback. The migration is irreversible when it is nil.
+	Down func(tx Repository) error
+}
+
+// MigrationStatus is the state of a migration returned by Status.
+type MigrationStatus struct {
+	Version int64      `json:"version"`
+	Name    string     `json:"name"`
+	Applied bool       `json:"applied"`
+	At      *time.Time `json:"appliedAt,omitempty"`
+}
+
+// schemaMigration is the record of an applied migration.
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName returns the table of the applied migrations.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies the migrations which haven't been applied in order of their versions, and returns their number.
+// It stops at the first failure, so the migrations after it are applied by the next call.
+func Migrate(rep Repository, migrations []Migration) (int, error) {
+	sorted, applied, err := loadMigrations(rep, migrations)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, m := range sorted {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := rep.Transaction(func(tx Repository) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: clock.Now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to apply the migration %d %s: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Rollback reverts the last steps applied migrations in reverse order of their versions by their Down steps,
+// and returns their number. It stops at the first failure, and at the migration which is irreversible
+// with ErrIrreversibleMigration.
+func Rollback(rep Repository, migrations []Migration, steps int) (int, error) {
+	sorted, applied, err := loadMigrations(rep, migrations)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := len(sorted) - 1; i >= 0 && count < steps; i-- {
+		m := sorted[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return count, fmt.Errorf("failed to roll back the migration %d %s: %w", m.Version, m.Name,
+				ErrIrreversibleMigration)
+		}
+		err := rep.Transaction(func(tx Repository) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to roll back the migration %d %s: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status returns the state of the migrations in order of their versions.
+func Status(rep Repository, migrations []Migration) ([]MigrationStatus, error) {
+	sorted, applied, err := loadMigrations(rep, migrations)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(sorted))
+	for _, m := range sorted {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied, status.At = true, &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations which haven't been applied in order of their versions.
+func Pending(rep Repository, migrations []Migration) ([]MigrationStatus, error) {
+	statuses, err := Status(rep, migrations)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(statuses, func(s MigrationStatus) bool { return s.Applied }), nil
+}
+
+// loadMigrations returns the migrations sorted by their versions and the records of the applied ones,
+// creating the schema_migrations table when it doesn't exist. It returns an error for the duplicate versions
+// and the applied version which isn't one of the migrations, such as the one of a newer binary.
+func loadMigrations(rep Repository, migrations []Migration) ([]Migration, map[int64]schemaMigration, error) {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	known := map[int64]bool{}
+	for _, m := range sorted {
+		if m.Version <= 0 || m.Up == nil {
+			return nil, nil, fmt.Errorf("the migration %d %s must have a positive version and Up", m.Version, m.Name)
+		}
+		if known[m.Version] {
+			return nil, nil, fmt.Errorf("the version %d of the migration %s is duplicated", m.Version, m.Name)
+		}
+		known[m.Version] = true
+	}
+	if err := rep.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to create the table of the migrations: %w", err)
+	}
+	var records []schemaMigration
+	if err := rep.Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to find the applied migrations: %w", err)
+	}
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		if !known[record.Version] {
+			return nil, nil, fmt.Errorf("the applied migration %d %s is unknown", record.Version, record.Name)
+		}
+		applied[record.Version] = record
+	}
+	return sorted, applied, nil
+}
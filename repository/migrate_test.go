@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMigrations creates the table of uniqueRecord, and inserts the records a and b.
+func testMigrations() []Migration {
+	insert := func(name string) Migration {
+		return Migration{
+			Name: "insert_" + name,
+			Up:   func(tx Repository) error { return tx.Create(&uniqueRecord{Name: name}).Error },
+			Down: func(tx Repository) error { return tx.Where("name = ?", name).Delete(&uniqueRecord{}).Error },
+		}
+	}
+	a, b := insert("a"), insert("b")
+	a.Version, b.Version = 2, 3
+	return []Migration{
+		b,
+		{
+			Version: 1,
+			Name:    "create_unique_records",
+			Up:      func(tx Repository) error { return tx.AutoMigrate(&uniqueRecord{}) },
+			Down:    func(tx Repository) error { return tx.DropTableIfExists(&uniqueRecord{}) },
+		},
+		a,
+	}
+}
+
+func appliedVersions(t *testing.T, rep Repository) []int64 {
+	var versions []int64
+	assert.NoError(t, rep.Model(&schemaMigration{}).Order("version").Pluck("version", &versions).Error)
+	return versions
+}
+
+func TestMigrate(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+
+	applied, err := Migrate(rep, testMigrations())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, applied)
+	assert.Equal(t, []string{"a", "b"}, recordNames(t, rep))
+	assert.Equal(t, []int64{1, 2, 3}, appliedVersions(t, rep))
+
+	applied, err = Migrate(rep, testMigrations())
+
+	assert.NoError(t, err)
+	assert.Zero(t, applied)
+	assert.Equal(t, []string{"a", "b"}, recordNames(t, rep))
+}
+
+func TestMigrate_Resume(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	migrations := testMigrations()
+
+	applied, err := Migrate(rep, migrations[1:])
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, applied)
+	pending, err := Pending(rep, migrations)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "insert_b", pending[0].Name)
+	}
+
+	applied, err = Migrate(rep, migrations)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, []string{"a", "b"}, recordNames(t, rep))
+	assert.Equal(t, []int64{1, 2, 3}, appliedVersions(t, rep))
+}
+
+func TestMigrate_Failure(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	errFailed := errors.New("failed")
+	migrations := testMigrations()
+	migrations[0].Up = func(tx Repository) error {
+		if err := tx.Create(&uniqueRecord{Name: "b"}).Error; err != nil {
+			return err
+		}
+		return errFailed
+	}
+
+	applied, err := Migrate(rep, migrations)
+
+	assert.ErrorIs(t, err, errFailed)
+	assert.ErrorContains(t, err, "failed to apply the migration 3 insert_b")
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, []string{"a"}, recordNames(t, rep))
+	assert.Equal(t, []int64{1, 2}, appliedVersions(t, rep))
+}
+
+func TestMigrate_InvalidMigrations(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	migrations := testMigrations()
+	migrations[0].Version = 2
+
+	_, err := Migrate(rep, migrations)
+
+	assert.ErrorContains(t, err, "is duplicated")
+	_, err = Migrate(rep, testMigrations())
+	require.NoError(t, err)
+	_, err = Migrate(rep, testMigrations()[1:])
+	assert.ErrorContains(t, err, "the applied migration 3 insert_b is unknown")
+}
+
+func TestRollback(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	_, err := Migrate(rep, testMigrations())
+	require.NoError(t, err)
+
+	rolledBack, err := Rollback(rep, testMigrations(), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rolledBack)
+	assert.Empty(t, recordNames(t, rep))
+	assert.Equal(t, []int64{1}, appliedVersions(t, rep))
+
+	migrations := testMigrations()
+	migrations[1].Down = nil
+	rolledBack, err = Rollback(rep, migrations, 1)
+
+	assert.ErrorIs(t, err, ErrIrreversibleMigration)
+	assert.Zero(t, rolledBack)
+	assert.Equal(t, []int64{1}, appliedVersions(t, rep))
+}
+
+func TestStatus(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	_, err := Migrate(rep, testMigrations()[1:])
+	require.NoError(t, err)
+
+	statuses, err := Status(rep, testMigrations())
+
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, []string{"create_unique_records", "insert_a", "insert_b"},
+			[]string{statuses[0].Name, statuses[1].Name, statuses[2].Name})
+		assert.True(t, statuses[1].Applied)
+		assert.NotNil(t, statuses[1].At)
+		assert.False(t, statuses[2].Applied)
+		assert.Nil(t, statuses[2].At)
+	}
+}
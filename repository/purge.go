@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchInterval is the pause between the batches of PurgeOlderThan,
+// in which the other requests can take the locks of the table.
+const purgeBatchInterval = 20 * time.Millisecond
+
+var (
+	// ErrInvalidBatchSize is returned by PurgeOlderThan when the size of a batch isn't positive.
+	ErrInvalidBatchSize = errors.New("the batch size must be greater than 0")
+	// ErrColumnNotIndexed is returned by PurgeOlderThan when no index starts with the column,
+	// because every batch would scan the whole table.
+	ErrColumnNotIndexed = errors.New("the column isn't the leading column of any index")
+)
+
+// PurgeOlderThan deletes the records of the model whose column, such as created_at, is older than age,
+// which is the retention of the tables growing without bound. The records are deleted by at most batchSize
+// a statement, outside of any transaction, with a pause between the statements, so the locks are held briefly
+// and the other requests run between the batches. The soft-deleted records are deleted too.
+// It refuses the column which no index starts with, checked by the indexes of the database.
+// The progress is logged after every batch. It returns the number of the deleted records,
+// which have been deleted until the failure when it fails, and it stops when the context of the repository is done.
+func PurgeOlderThan(rep Repository, model interface{}, column string, age time.Duration, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		return 0, ErrInvalidBatchSize
+	}
+	db := rep.DB()
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return 0, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return 0, fmt.Errorf("the table %s must have a primary key", stmt.Table)
+	}
+	if err := checkIndexed(db, model, column); err != nil {
+		return 0, fmt.Errorf("failed to purge the table %s by %s: %w", stmt.Table, column, err)
+	}
+
+	ctx := statementContext(db)
+	primaryKey := stmt.Schema.PrioritizedPrimaryField.DBName
+	older := clause.Lt{Column: clause.Column{Name: column}, Value: clock.Now().Add(-age)}
+	var purged int64
+	for {
+		var ids []interface{}
+		if err := db.Unscoped().Model(model).Where(older).Order(clause.OrderByColumn{Column: clause.Column{Name: column}}).
+			Limit(batchSize).Pluck(primaryKey, &ids).Error; err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+		result := db.Unscoped().Where(clause.IN{Column: clause.Column{Name: primaryKey}, Values: ids}).Where(older).
+			Delete(reflect.New(stmt.Schema.ModelType).Interface())
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+		if log, ok := db.Logger.(logger.Logger); ok {
+			logger.WithContextFields(ctx, log.GetZapLogger()).Infow("Purged the old records",
+				"table", stmt.Table, "column", column, "deleted", result.RowsAffected, "total", purged)
+		}
+		if len(ids) < batchSize {
+			return purged, nil
+		}
+		select {
+		case <-ctx.Done():
+			return purged, ctx.Err()
+		case <-time.After(purgeBatchInterval):
+		}
+	}
+}
+
+// checkIndexed returns ErrColumnNotIndexed when no index of the table of the model starts with the column.
+func checkIndexed(db *gorm.DB, model interface{}, column string) error {
+	indexes, err := db.Migrator().GetIndexes(model)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if columns := index.Columns(); len(columns) > 0 && columns[0] == column {
+			return nil
+		}
+	}
+	return ErrColumnNotIndexed
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyRecord is the record of a table which grows without bound, whose creation time is indexed.
+type historyRecord struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+}
+
+func TestPurgeOlderThan(t *testing.T) {
+	rep, logs := prepareForObservedRepositoryTest(t, createRepositoryTestConfig(t))
+	require.NoError(t, rep.AutoMigrate(&historyRecord{}))
+	now := time.Now()
+	for i, name := range []string{"old1", "new1", "old2", "old3", "new2", "old4", "old5"} {
+		createdAt := now.Add(-48 * time.Hour).Add(time.Duration(i) * time.Minute)
+		if name[:3] == "new" {
+			createdAt = now.Add(-time.Hour)
+		}
+		require.NoError(t, rep.Create(&historyRecord{Name: name, CreatedAt: createdAt}).Error)
+	}
+
+	purged, err := PurgeOlderThan(rep, &historyRecord{}, "created_at", 24*time.Hour, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), purged)
+	var names []string
+	assert.NoError(t, rep.Model(&historyRecord{}).Order("id").Pluck("name", &names).Error)
+	assert.Equal(t, []string{"new1", "new2"}, names)
+	batches := logs.FilterMessage("Purged the old records").All()
+	if assert.Len(t, batches, 3) {
+		assert.Equal(t, int64(1), batches[2].ContextMap()["deleted"])
+		assert.Equal(t, int64(5), batches[2].ContextMap()["total"])
+	}
+}
+
+func TestPurgeOlderThan_NotIndexed(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&historyRecord{}))
+	require.NoError(t, rep.Create(&historyRecord{Name: "old", UpdatedAt: time.Now().Add(-48 * time.Hour)}).Error)
+
+	purged, err := PurgeOlderThan(rep, &historyRecord{}, "updated_at", 24*time.Hour, 2)
+
+	assert.ErrorIs(t, err, ErrColumnNotIndexed)
+	assert.Zero(t, purged)
+	_, err = PurgeOlderThan(rep, &historyRecord{}, "created_at", 24*time.Hour, 0)
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+}
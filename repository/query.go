@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+)
+
+// queryMetric is the name of the metric of the elapsed milliseconds of the hand-written queries.
+const queryMetric = "db.query.elapsed_ms"
+
+var (
+	// ErrMissingParam is returned by Query when the sql has a named parameter which isn't given,
+	// such as a :name token written by concatenating strings.
+	ErrMissingParam = errors.New("the named parameter isn't given")
+	// ErrPositionalParam is returned by Query when the sql has a positional placeholder instead of the named one.
+	ErrPositionalParam = errors.New("the sql must bind the values by the named parameters instead of ?")
+	// ErrUnusedParam is returned by Query when a given parameter isn't in the sql.
+	ErrUnusedParam = errors.New("the parameter isn't used by the sql")
+)
+
+// Query runs the hand-written sql, such as an aggregate for a report, and scans its rows into dest,
+// which is a pointer to a slice of structs or of primitives such as []int, or to a struct.
+// The values are bound by the named parameters, such as :from and :to, which are expanded to the placeholders
+// of the dialect, and a slice is expanded to a list for IN (:ids). Every named parameter must be given
+// and every given parameter must be used, so a sql which has a value concatenated into it fails.
+// The sql logs of the query are labeled by its name, such as books_per_category, they are logged as slow
+// by sql.slow_threshold like the other statements, and its elapsed time is written to the metrics log.
+func Query(ctx context.Context, rep Repository, name string, sql string, params map[string]interface{},
+	dest interface{}) error {
+	expanded, values, err := bindNamedParams(sql, params)
+	if err != nil {
+		return fmt.Errorf("failed to bind the parameters of the query %s: %w", name, err)
+	}
+	begin := clock.Now()
+	err = rep.WithContext(logger.WithQueryName(ctx, name)).Raw(expanded, values...).Scan(dest).Error
+	if log, ok := rep.DB().Logger.(logger.Logger); ok {
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		elapsed := float64(clock.Since(begin).Microseconds()) / 1000
+		log.Metric(queryMetric, elapsed, map[string]string{"query": name, "status": status})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run the query %s: %w", name, err)
+	}
+	return nil
+}
+
+// bindNamedParams replaces the named parameters of the sql with the placeholders, and returns the values
+// in their order. The colons in the quoted strings and identifiers and the casts of PostgreSQL,
+// such as ::date, aren't parameters.
+func bindNamedParams(sql string, params map[string]interface{}) (string, []interface{}, error) {
+	var builder strings.Builder
+	builder.Grow(len(sql))
+	var values []interface{}
+	used := make(map[string]bool, len(params))
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+			builder.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case c == '?':
+			return "", nil, ErrPositionalParam
+		case c == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			builder.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(sql) && isParamStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isParamPart(sql[end]) {
+				end++
+			}
+			param := sql[i+1 : end]
+			value, ok := params[param]
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %s", ErrMissingParam, param)
+			}
+			used[param] = true
+			values = append(values, value)
+			builder.WriteByte('?')
+			i = end - 1
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	var unused []string
+	for param := range params {
+		if !used[param] {
+			unused = append(unused, param)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", nil, fmt.Errorf("%w: %s", ErrUnusedParam, strings.Join(unused, ", "))
+	}
+	return builder.String(), values, nil
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isParamPart(c byte) bool {
+	return isParamStart(c) || '0' <= c && c <= '9'
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"go.uber.org/zap"
+)
+
+func TestQuery(t *testing.T) {
+	rep, logs := prepareForObservedRepositoryTest(t, createRepositoryTestConfig(t))
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	for _, name := range []string{"apple", "banana", "cherry", "durian"} {
+		require.NoError(t, rep.Create(&uniqueRecord{Name: name}).Error)
+	}
+
+	var records []uniqueRecord
+	err := Query(context.Background(), rep, "records_between",
+		"SELECT id, name FROM unique_records WHERE name >= :from AND name <= :to AND name <> ':from' ORDER BY id",
+		map[string]interface{}{"from": "banana", "to": "cherry"}, &records)
+
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "banana", records[0].Name)
+		assert.Equal(t, "cherry", records[1].Name)
+	}
+	labeled := logs.FilterField(zap.String(logger.QueryNameKey, "records_between")).All()
+	assert.NotEmpty(t, labeled)
+
+	var names []string
+	err = Query(context.Background(), rep, "names_in",
+		"SELECT name FROM unique_records WHERE id IN :ids OR name = :name ORDER BY id",
+		map[string]interface{}{"ids": []uint{1, 4}, "name": "cherry"}, &names)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apple", "cherry", "durian"}, names)
+
+	var count int
+	err = Query(context.Background(), rep, "count_after",
+		"SELECT COUNT(*) FROM unique_records WHERE id>:id", map[string]interface{}{"id": 2}, &count)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestQuery_InvalidParams(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	tests := []struct {
+		name   string
+		sql    string
+		params map[string]interface{}
+		err    error
+	}{
+		{"missing", "SELECT name FROM unique_records WHERE name = :name", map[string]interface{}{}, ErrMissingParam},
+		{"concatenated", "SELECT name FROM unique_records WHERE name = :apple", nil, ErrMissingParam},
+		{"extra", "SELECT name FROM unique_records WHERE id = :id",
+			map[string]interface{}{"id": 1, "name": "apple"}, ErrUnusedParam},
+		{"positional", "SELECT name FROM unique_records WHERE id = ?", nil, ErrPositionalParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			err := Query(context.Background(), rep, tt.name, tt.sql, tt.params, &names)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestBindNamedParams(t *testing.T) {
+	sql, values, err := bindNamedParams(
+		"SELECT :a::text, ':b', \"c:d\" FROM t WHERE x = :a AND y=:b_2",
+		map[string]interface{}{"a": 1, "b_2": "two"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ?::text, ':b', \"c:d\" FROM t WHERE x = ? AND y=?", sql)
+	assert.Equal(t, []interface{}{1, 1, "two"}, values)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ybkuroki/go-webapp-sample/logger"
+)
+
+// CountQueries returns the context which counts the statements executed with it, and its counter,
+// which detects an N+1 query, such as in the tests by repotest.AssertMaxQueries.
+// The statements are counted by the sql logger as the ones of logger.WithStatementCounter,
+// so the statements whose context has no counter have no overhead.
+func CountQueries(ctx context.Context) (context.Context, *logger.StatementCounter) {
+	ctx = logger.WithStatementCounter(ctx)
+	return ctx, logger.StatementCounterFromContext(ctx)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	readOnlyCallbackName = "repository:read_only"
+	readOnlyKey          = "repository:read_only"
+)
+
+// ErrReadOnlyRepository is returned when a statement which writes is run by the repository of ReadOnly.
+var ErrReadOnlyRepository = errors.New("the repository is read-only")
+
+// registerReadOnly registers the callbacks which fail the statements which write,
+// Create, Save, Updates, Delete and Exec, when they are run by the repository of ReadOnly.
+// They fail before the statements start their transactions and run their hooks.
+func registerReadOnly(db *gorm.DB) error {
+	rejectWrite := func(db *gorm.DB) {
+		if readOnly, ok := db.Get(readOnlyKey); ok && readOnly == true {
+			_ = db.AddError(ErrReadOnlyRepository)
+		}
+	}
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:begin_transaction").Register(readOnlyCallbackName, rejectWrite),
+		callback.Update().Before("gorm:begin_transaction").Register(readOnlyCallbackName, rejectWrite),
+		callback.Delete().Before("gorm:begin_transaction").Register(readOnlyCallbackName, rejectWrite),
+		callback.Raw().Before("gorm:raw").Register(readOnlyCallbackName, rejectWrite),
+	)
+}
+
+// ReadOnly returns the repository which can't write, such as for the reports, whose Create, Save, Updates, Delete
+// and Exec fail with ErrReadOnlyRepository without running. The queries, such as Find, Count and the SELECTs
+// of Raw, run as usual, so the model finders work with it as they are. Its transactions are read-only too,
+// which begin by BEGIN READ ONLY on the databases which support it.
+func (rep *repository) ReadOnly() Repository {
+	readOnly := rep.withDB(rep.db.Set(readOnlyKey, true).Session(&gorm.Session{}), rep.depth)
+	readOnly.readOnly = true
+	return readOnly
+}
+
+// txOptions returns the options of the transactions of the repository, which are read-only for ReadOnly.
+func (rep *repository) txOptions() []*sql.TxOptions {
+	if rep.readOnly {
+		return []*sql.TxOptions{{ReadOnly: true}}
+	}
+	return nil
+}
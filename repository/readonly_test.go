@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly_RejectsWrites(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	require.NoError(t, rep.Create(&uniqueRecord{Name: "first"}).Error)
+	readOnly := rep.ReadOnly().WithContext(context.Background())
+
+	assert.ErrorIs(t, readOnly.Create(&uniqueRecord{Name: "second"}).Error, ErrReadOnlyRepository)
+	assert.ErrorIs(t, readOnly.Save(&uniqueRecord{ID: 1, Name: "renamed"}).Error, ErrReadOnlyRepository)
+	assert.ErrorIs(t, readOnly.Model(&uniqueRecord{ID: 1}).Update("name", "renamed").Error, ErrReadOnlyRepository)
+	assert.ErrorIs(t, readOnly.Delete(&uniqueRecord{ID: 1}).Error, ErrReadOnlyRepository)
+	assert.ErrorIs(t, readOnly.Exec("DELETE FROM unique_records").Error, ErrReadOnlyRepository)
+
+	var count int64
+	assert.NoError(t, readOnly.Model(&uniqueRecord{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	var names []string
+	assert.NoError(t, readOnly.Raw("SELECT name FROM unique_records").Scan(&names).Error)
+	assert.Equal(t, []string{"first"}, names)
+	// the repository which it is made from can still write.
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "second"}).Error)
+	assert.Equal(t, []string{"first", "second"}, recordNames(t, rep))
+}
+
+func TestReadOnly_Transaction(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.ReadOnly().Transaction(func(tx Repository) error {
+		var records []uniqueRecord
+		if err := tx.Find(&records).Error; err != nil {
+			return err
+		}
+		return tx.Transaction(func(tx Repository) error {
+			return tx.Create(&uniqueRecord{Name: "inner"}).Error
+		})
+	})
+
+	assert.ErrorIs(t, err, ErrReadOnlyRepository)
+	assert.Empty(t, recordNames(t, rep))
+}
@@ -1,15 +1,17 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"sync/atomic"
+	"time"
 
-	"github.com/glebarez/sqlite"
+	"github.com/ybkuroki/go-webapp-sample/clock"
 	"github.com/ybkuroki/go-webapp-sample/config"
 	"github.com/ybkuroki/go-webapp-sample/logger"
-	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -28,16 +30,37 @@ type Repository interface {
 	Where(query interface{}, args ...interface{}) *gorm.DB
 	Preload(column string, conditions ...interface{}) *gorm.DB
 	Scopes(funcs ...func(*gorm.DB) *gorm.DB) *gorm.DB
+	Scoped(scopes ...func(*gorm.DB) *gorm.DB) Repository
 	ScanRows(rows *sql.Rows, result interface{}) error
 	Transaction(fc func(tx Repository) error) (err error)
 	Close() error
+	Ping(ctx context.Context) error
+	Verify(ctx context.Context, tables ...string) error
 	DropTableIfExists(value interface{}) error
 	AutoMigrate(value interface{}) error
+	DB() *gorm.DB
+	WithContext(ctx context.Context) Repository
+	ReadOnly() Repository
+	IsDuplicateKeyError(err error) bool
+	IsDeadlockError(err error) bool
 }
 
 // repository defines a repository for access the database.
 type repository struct {
 	db *gorm.DB
+	// dialect is the name of the dialect of the database, such as sqlite3.
+	dialect string
+	// depth is the nesting level of the transactions. Zero means outside of any transaction.
+	depth int
+	// savepoints is the number of the savepoints created at this nesting level, which numbers their IDs.
+	savepoints *atomic.Int64
+	logger     logger.Logger
+	// slowTransactionThreshold is the duration from which a transaction is logged as slow.
+	slowTransactionThreshold time.Duration
+	// transactionTimeout is the deadline of a transaction. Zero means no deadline.
+	transactionTimeout time.Duration
+	// readOnly is true for the repository of ReadOnly, whose transactions are read-only.
+	readOnly bool
 }
 
 // bookRepository is a concrete repository that implements repository.
@@ -50,11 +73,17 @@ func NewBookRepository(logger logger.Logger, conf *config.Config) Repository {
 	logger.GetZapLogger().Infof("Try database connection")
 	db, err := connectDatabase(logger, conf)
 	if err != nil {
-		logger.GetZapLogger().Errorf("Failure database connection")
+		logger.GetZapLogger().Errorf("Failure database connection, %s", err.Error())
 		os.Exit(config.ErrExitStatus)
 	}
 	logger.GetZapLogger().Infof("Success database connection, %s:%s", conf.Database.Host, conf.Database.Port)
-	return &bookRepository{&repository{db: db}}
+	return &bookRepository{&repository{
+		db:                       db,
+		dialect:                  dialectName(conf),
+		logger:                   logger,
+		slowTransactionThreshold: conf.Database.SlowTransactionThreshold.Std(),
+		transactionTimeout:       conf.Database.TransactionTimeout.Std(),
+	}}
 }
 
 const (
@@ -67,21 +96,33 @@ const (
 )
 
 func connectDatabase(logger logger.Logger, config *config.Config) (*gorm.DB, error) {
-	var dsn string
-	gormConfig := &gorm.Config{Logger: logger}
-
-	if config.Database.Dialect == POSTGRES {
-		dsn = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
-			config.Database.Host, config.Database.Port, config.Database.Username,
-			config.Database.Dbname, config.Database.Password)
-		return gorm.Open(postgres.Open(dsn), gormConfig)
-	} else if config.Database.Dialect == MYSQL {
-		dsn = fmt.Sprintf("%s:%s@(%s)/%s?charset=utf8&parseTime=True&loc=Local",
-			config.Database.Username, config.Database.Password,
-			config.Database.Host, config.Database.Dbname)
-		return gorm.Open(mysql.Open(dsn), gormConfig)
+	gormConfig := &gorm.Config{
+		Logger:  logger,
+		Plugins: map[string]gorm.Plugin{logger.Name(): logger},
+		NowFunc: func() time.Time { return clock.Now().Local() },
 	}
-	return gorm.Open(sqlite.Open(config.Database.Host), gormConfig)
+
+	dialector, err := openDialector(config)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = errors.Join(registerStatementCounter(db), registerQueryTimeout(db, config.Database.QueryTimeout.Std()),
+		registerReadOnly(db))
+	if config.Database.SQLComment {
+		err = errors.Join(err, registerSQLComment(db))
+	}
+	return db, err
+}
+
+// SetNowFunc replaces the function which returns the current time, such as clock.Fake.Now in the tests.
+// It is used for the timestamp columns, the audit log and the elapsed time of the sqls and the transactions.
+// It returns the function which restores the previous one.
+func SetNowFunc(fn func() time.Time) (restore func()) {
+	return clock.Set(fn)
 }
 
 // Model specify the model you would like to run db operations
@@ -150,6 +191,17 @@ func (rep *repository) Scopes(funcs ...func(*gorm.DB) *gorm.DB) *gorm.DB {
 	return rep.db.Scopes(funcs...)
 }
 
+// Scoped returns the repository whose queries have the conditions of the scopes, such as the ones of the scope package.
+// Every query started from it has its own copy of the conditions, so the conditions added to a query,
+// such as by Where, never leak into the next one. The scopes apply to its transactions too.
+func (rep *repository) Scoped(scopes ...func(*gorm.DB) *gorm.DB) Repository {
+	db := rep.db
+	for _, scope := range scopes {
+		db = scope(db)
+	}
+	return rep.withDB(db.Session(&gorm.Session{}), rep.depth)
+}
+
 // ScanRows scan `*sql.Rows` to give struct
 func (rep *repository) ScanRows(rows *sql.Rows, result interface{}) error {
 	return rep.db.ScanRows(rows, result)
@@ -161,6 +213,15 @@ func (rep *repository) Close() error {
 	return sqlDB.Close()
 }
 
+// Ping checks that the database is reachable.
+func (rep *repository) Ping(ctx context.Context) error {
+	sqlDB, err := rep.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // DropTableIfExists drop table if it is exist
 func (rep *repository) DropTableIfExists(value interface{}) error {
 	return rep.db.Migrator().DropTable(value)
@@ -171,27 +232,98 @@ func (rep *repository) AutoMigrate(value interface{}) error {
 	return rep.db.AutoMigrate(value)
 }
 
+// DB returns the underlying gorm.DB, which is configured with the logger and the connection pool.
+// It is the escape hatch for the complex queries which the other methods can't build, such as chained scopes.
+// Prefer the other methods when they are enough, because the code using it depends on gorm directly.
+// Inside a transaction, it returns the handle of the transaction.
+func (rep *repository) DB() *gorm.DB {
+	return rep.db
+}
+
+// WithContext returns the repository which runs the queries with the given context.
+// The request ID of the context is added to the sql logs.
+func (rep *repository) WithContext(ctx context.Context) Repository {
+	return rep.withDB(rep.db.WithContext(ctx), rep.depth)
+}
+
 // Transaction start a transaction as a block.
 // If it is failed, will rollback and return error.
 // If it is sccuessed, will commit.
+// When it is called inside a transaction, the block runs within a savepoint instead,
+// so the failure of the block rolls back only its changes, without aborting the outer transaction.
+// The transaction open longer than the threshold is logged as slow with its number of statements.
+// BEGIN, COMMIT and ROLLBACK are logged with the generated tx_id, which every sql in the transaction has too.
+// The savepoints have the suffixed IDs, such as abc.1.
+// The transaction which exceeds database.transaction_timeout is rolled back, and ErrQueryTimeout is returned.
 // ref: https://github.com/jinzhu/gorm/blob/master/main.go#L533
 func (rep *repository) Transaction(fc func(tx Repository) error) (err error) {
+	if rep.depth > 0 {
+		return rep.savepoint(fc)
+	}
+
+	begin := clock.Now()
 	panicked := true
-	tx := rep.db.Begin()
+	ctx := logger.WithTransactionID(statementContext(rep.db), logger.NewTransactionID())
+	if rep.transactionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rep.transactionTimeout)
+		defer cancel()
+	}
+	tx, stats := withTransactionStats(rep.db.WithContext(ctx).Begin(rep.txOptions()...))
+	rep.logTransaction(tx, logger.TransactionBegin)
 	defer func() {
 		if panicked || err != nil {
 			tx.Rollback()
+			rep.logTransaction(tx, logger.TransactionRollback)
 		}
+		rep.logSlowTransaction(begin, stats)
 	}()
 
-	txrep := &repository{}
-	txrep.db = tx
+	txrep := rep.withDB(tx, 1)
+	txrep.savepoints = new(atomic.Int64)
 	err = fc(txrep)
 
 	if err == nil {
-		err = tx.Commit().Error
+		if err = tx.Commit().Error; err == nil {
+			rep.logTransaction(tx, logger.TransactionCommit)
+		}
+	}
+	if err != nil && !errors.Is(err, ErrQueryTimeout) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrQueryTimeout, err)
 	}
 
 	panicked = false
 	return
 }
+
+// savepoint runs the block within a savepoint of the current transaction.
+func (rep *repository) savepoint(fc func(tx Repository) error) (err error) {
+	name := fmt.Sprintf("sp%d", rep.depth)
+	ctx := statementContext(rep.db)
+	id := logger.SavepointID(logger.TransactionIDFromContext(ctx), rep.savepoints.Add(1))
+	sp := rep.db.WithContext(logger.WithTransactionID(ctx, id))
+	if err = sp.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			sp.RollbackTo(name)
+		}
+	}()
+
+	sprep := rep.withDB(sp, rep.depth+1)
+	sprep.savepoints = new(atomic.Int64)
+	err = fc(sprep)
+
+	panicked = false
+	return
+}
+
+// withDB returns the repository which runs the queries by the given db, such as a transaction.
+func (rep *repository) withDB(db *gorm.DB, depth int) *repository {
+	return &repository{db: db, dialect: rep.dialect, depth: depth, savepoints: rep.savepoints, logger: rep.logger,
+		slowTransactionThreshold: rep.slowTransactionThreshold, transactionTimeout: rep.transactionTimeout,
+		readOnly: rep.readOnly}
+}
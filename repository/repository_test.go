@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+func TestTransaction_NestedRollback(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		if err := tx.Create(&uniqueRecord{Name: "outer"}).Error; err != nil {
+			return err
+		}
+		inner := tx.Transaction(func(tx Repository) error {
+			if err := tx.Create(&uniqueRecord{Name: "inner"}).Error; err != nil {
+				return err
+			}
+			return errors.New("rollback the inner block")
+		})
+		assert.EqualError(t, inner, "rollback the inner block")
+		return tx.Create(&uniqueRecord{Name: "after"}).Error
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "after"}, recordNames(t, rep))
+}
+
+func TestTransaction_NestedCommit(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		return tx.Transaction(func(tx Repository) error {
+			return tx.Transaction(func(tx Repository) error {
+				return tx.Create(&uniqueRecord{Name: "innermost"}).Error
+			})
+		})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"innermost"}, recordNames(t, rep))
+}
+
+func TestTransaction_OuterRollback(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		_ = tx.Transaction(func(tx Repository) error {
+			return tx.Create(&uniqueRecord{Name: "inner"}).Error
+		})
+		return errors.New("rollback the outer block")
+	})
+
+	assert.Error(t, err)
+	assert.Empty(t, recordNames(t, rep))
+}
+
+func TestDB_LoggedQuery(t *testing.T) {
+	rep, logs := prepareForObservedRepositoryTest(t, createRepositoryTestConfig(t))
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "raw"}).Error)
+
+	var names []string
+	named := func(db *gorm.DB) *gorm.DB { return db.Where("name = ?", "raw") }
+	err := rep.DB().Model(&uniqueRecord{}).Scopes(named).Pluck("name", &names).Error
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"raw"}, names)
+	assert.NotZero(t, logs.FilterMessageSnippet("WHERE name = 'raw'").Len())
+}
+
+func TestTransaction_SlowWarning(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.SlowTransactionThreshold = config.Duration(time.Nanosecond)
+	rep, logs := prepareForObservedRepositoryTest(t, conf)
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		if err := tx.Create(&uniqueRecord{Name: "first"}).Error; err != nil {
+			return err
+		}
+		return tx.Transaction(func(tx Repository) error {
+			return tx.Create(&uniqueRecord{Name: "second"}).Error
+		})
+	})
+
+	assert.NoError(t, err)
+	warnings := logs.FilterMessage("Slow transaction").All()
+	if assert.Len(t, warnings, 1) {
+		fields := warnings[0].ContextMap()
+		assert.Equal(t, zap.WarnLevel, warnings[0].Level)
+		assert.IsType(t, time.Duration(0), fields["elapsed"])
+		// the two inserts and the savepoint of the nested block.
+		assert.Equal(t, int64(3), fields["statements"])
+	}
+}
+
+func TestTransaction_FastNotWarned(t *testing.T) {
+	rep, logs := prepareForObservedRepositoryTest(t, createRepositoryTestConfig(t))
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		return tx.Create(&uniqueRecord{Name: "fast"}).Error
+	})
+
+	assert.NoError(t, err)
+	assert.Zero(t, logs.FilterMessage("Slow transaction").Len())
+}
+
+func recordNames(t *testing.T, rep Repository) []string {
+	var names []string
+	assert.NoError(t, rep.Model(&uniqueRecord{}).Order("id").Pluck("name", &names).Error)
+	return names
+}
+
+// prepareForRepositoryTest connects a SQLite in-memory database which is dedicated to the test.
+func prepareForRepositoryTest(t *testing.T) Repository {
+	rep := NewBookRepository(logger.NewLogger(zaptest.NewLogger(t).Sugar()), createRepositoryTestConfig(t))
+	t.Cleanup(func() { _ = rep.Close() })
+	return rep
+}
+
+// prepareForObservedRepositoryTest connects the database with the logger whose logs are observed by the test.
+func prepareForObservedRepositoryTest(t *testing.T, conf *config.Config) (Repository, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	rep := NewBookRepository(logger.NewLogger(zap.New(core).Sugar()), conf)
+	t.Cleanup(func() { _ = rep.Close() })
+	return rep, logs
+}
+
+// createRepositoryTestConfig returns the setting of a SQLite in-memory database which is dedicated to the test.
+func createRepositoryTestConfig(t *testing.T) *config.Config {
+	conf := &config.Config{}
+	conf.Database.Dialect = SQLITE
+	conf.Database.DSN = "file:" + t.Name() + "?mode=memory&cache=shared"
+	return conf
+}
+
+func TestTransaction_SavepointID(t *testing.T) {
+	rep, logs := prepareForObservedRepositoryTest(t, createRepositoryTestConfig(t))
+	assert.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		_ = tx.Transaction(func(tx Repository) error {
+			return tx.Create(&uniqueRecord{Name: "first"}).Error
+		})
+		return tx.Transaction(func(tx Repository) error {
+			return tx.Create(&uniqueRecord{Name: "second"}).Error
+		})
+	})
+
+	assert.NoError(t, err)
+	id := logs.FilterMessage("[gorm] BEGIN").All()[0].ContextMap()[logger.TransactionIDKey]
+	first := logs.FilterMessageSnippet("'first'").All()
+	second := logs.FilterMessageSnippet("'second'").All()
+	if assert.Len(t, first, 1) && assert.Len(t, second, 1) {
+		assert.Equal(t, fmt.Sprintf("%s.1", id), first[0].ContextMap()[logger.TransactionIDKey])
+		assert.Equal(t, fmt.Sprintf("%s.2", id), second[0].ContextMap()[logger.TransactionIDKey])
+	}
+	assert.Equal(t, id, logs.FilterMessage("[gorm] COMMIT").All()[0].ContextMap()[logger.TransactionIDKey])
+}
@@ -0,0 +1,25 @@
+// Package repotest provides the helpers of the tests which access the database through the repository.
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ybkuroki/go-webapp-sample/repository"
+)
+
+// AssertMaxQueries runs fn with the context which counts the statements executed with it,
+// and fails the test with the list of the executed sqls when they are more than limit, which is likely an N+1 query.
+// fn must give the context to the repository, such as by rep.WithContext(ctx).
+func AssertMaxQueries(t testing.TB, limit int, fn func(ctx context.Context)) bool {
+	t.Helper()
+	ctx, counter := repository.CountQueries(context.Background())
+	fn(ctx)
+	if count := counter.Count(); count > limit {
+		t.Errorf("%d queries are executed, but the budget is %d:\n\t%s",
+			count, limit, strings.Join(counter.Statements(), "\n\t"))
+		return false
+	}
+	return true
+}
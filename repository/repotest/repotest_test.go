@@ -0,0 +1,46 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"go.uber.org/zap"
+)
+
+// recordingT records the failures of the test instead of failing it.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertMaxQueries(t *testing.T) {
+	conf := &config.Config{}
+	conf.Database.DSN = "file:" + t.Name() + "?mode=memory&cache=shared"
+	rep := repository.NewBookRepository(logger.NewLogger(zap.NewNop().Sugar()), conf)
+	t.Cleanup(func() { _ = rep.Close() })
+	query := func(ctx context.Context) {
+		for i := 0; i < 3; i++ {
+			rep.WithContext(ctx).Exec("SELECT ?", i)
+		}
+	}
+
+	assert.True(t, AssertMaxQueries(t, 3, query))
+
+	recorder := &recordingT{TB: t}
+	assert.False(t, AssertMaxQueries(recorder, 2, query))
+	if assert.Len(t, recorder.failures, 1) {
+		assert.Equal(t, "3 queries are executed, but the budget is 2:\n\tSELECT ?\n\tSELECT ?\n\tSELECT ?",
+			recorder.failures[0])
+	}
+}
@@ -0,0 +1,46 @@
+// Package scope provides the common conditions of the queries, such as the range of the creation time,
+// which are applied by Repository.Scoped or gorm.DB.Scopes, so the models compose the same ones.
+// A scope captures only the values given to it, so it can be reused across the queries and the goroutines.
+package scope
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scope adds the conditions to a query. It is the same as the scope of gorm.
+type Scope = func(*gorm.DB) *gorm.DB
+
+// CreatedBetween returns the scope of the records created between from and to, both inclusive.
+func CreatedBetween(from time.Time, to time.Time) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_at BETWEEN ? AND ?", from, to)
+	}
+}
+
+// IDsIn returns the scope of the records whose IDs are one of ids. It matches nothing when ids is empty.
+func IDsIn(ids []uint) Scope {
+	ids = append([]uint(nil), ids...)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN ?", ids)
+	}
+}
+
+// IDAfter returns the scope of the records whose IDs are greater than id in order of id,
+// which is the cursor of the keyset pagination.
+func IDAfter(id uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id > ?", id).Order("id")
+	}
+}
+
+// Limit returns the scope of at most n records. It doesn't limit them when n isn't positive.
+func Limit(n int) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if n <= 0 {
+			return db
+		}
+		return db.Limit(n)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/repository/scope"
+)
+
+func prepareForScopeTest(t *testing.T) Repository {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, rep.Create(&uniqueRecord{Name: name}).Error)
+	}
+	return rep
+}
+
+func TestScoped_NoConditionLeakage(t *testing.T) {
+	rep := prepareForScopeTest(t)
+	ctx, counter := CountQueries(context.Background())
+	scoped := rep.WithContext(ctx).Scoped(scope.IDsIn([]uint{1, 2, 3}))
+
+	var first, second, third []uniqueRecord
+	assert.NoError(t, scoped.Where("name = ?", "b").Find(&first).Error)
+	assert.NoError(t, scoped.Find(&second).Error)
+	assert.NoError(t, scoped.Scoped(scope.Limit(1)).Find(&third).Error)
+
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 3)
+	assert.Len(t, third, 1)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, recordNames(t, rep))
+	if statements := counter.Statements(); assert.Len(t, statements, 3) {
+		assert.NotContains(t, statements[1], "name")
+		assert.NotContains(t, statements[1], "LIMIT")
+		assert.NotContains(t, statements[2], "name")
+	}
+}
+
+func TestScoped_ConcurrentQueries(t *testing.T) {
+	rep := prepareForScopeTest(t)
+	scoped := rep.Scoped(scope.IDAfter(1), scope.Limit(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var records []uniqueRecord
+			assert.NoError(t, scoped.Where("name <> ?", "z").Find(&records).Error)
+			assert.Len(t, records, 2)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestScope(t *testing.T) {
+	rep := prepareForScopeTest(t)
+	tests := []struct {
+		name   string
+		scopes []scope.Scope
+		want   []uint
+	}{
+		{"IDsIn", []scope.Scope{scope.IDsIn([]uint{2, 4})}, []uint{2, 4}},
+		{"IDsIn empty", []scope.Scope{scope.IDsIn(nil)}, []uint{}},
+		{"IDAfter and Limit", []scope.Scope{scope.IDAfter(1), scope.Limit(2)}, []uint{2, 3}},
+		{"Limit not positive", []scope.Scope{scope.Limit(0)}, []uint{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []uint
+			assert.NoError(t, rep.Scoped(tt.scopes...).Model(&uniqueRecord{}).Pluck("id", &ids).Error)
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	commentCallbackName   = "repository:sql_comment"
+	uncommentCallbackName = "repository:sql_uncomment"
+	sqlCommentFormat      = "/* route='%s' */"
+)
+
+// registerSQLComment registers the callbacks which append the route of the request to the executed statements
+// as a SQLCommenter comment, such as /* route='GET%20%2Fapi%2Fcategories' */,
+// so the slow queries in the monitor of the database are linked to the endpoints.
+// The comment is added only to the statements sent to the database. The sql logs show the statements without it.
+func registerSQLComment(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register(commentCallbackName, addSQLComment),
+		callback.Create().After("gorm:create").Register(uncommentCallbackName, removeSQLComment),
+		callback.Query().Before("gorm:query").Register(commentCallbackName, addSQLComment),
+		callback.Query().After("gorm:query").Register(uncommentCallbackName, removeSQLComment),
+		callback.Update().Before("gorm:update").Register(commentCallbackName, addSQLComment),
+		callback.Update().After("gorm:update").Register(uncommentCallbackName, removeSQLComment),
+		callback.Delete().Before("gorm:delete").Register(commentCallbackName, addSQLComment),
+		callback.Delete().After("gorm:delete").Register(uncommentCallbackName, removeSQLComment),
+		callback.Row().Before("gorm:row").Register(commentCallbackName, addSQLComment),
+		callback.Row().After("gorm:row").Register(uncommentCallbackName, removeSQLComment),
+		callback.Raw().Before("gorm:raw").Register(commentCallbackName, addSQLComment),
+		callback.Raw().After("gorm:raw").Register(uncommentCallbackName, removeSQLComment),
+	)
+}
+
+// addSQLComment makes the statement send its sql with the comment of the route of its context.
+func addSQLComment(db *gorm.DB) {
+	route := logger.RouteFromContext(statementContext(db))
+	if route == "" {
+		return
+	}
+	db.Statement.ConnPool = &commentedConnPool{ConnPool: db.Statement.ConnPool, comment: sqlComment(route)}
+}
+
+// removeSQLComment restores the connection of the statement, so the transaction is committed by it.
+func removeSQLComment(db *gorm.DB) {
+	if pool, ok := db.Statement.ConnPool.(*commentedConnPool); ok {
+		db.Statement.ConnPool = pool.ConnPool
+	}
+}
+
+// sqlComment returns the comment of the route. The value is URL-encoded as SQLCommenter specifies,
+// so it has neither quotes nor */, and can't break out of the comment.
+func sqlComment(route string) string {
+	return fmt.Sprintf(sqlCommentFormat, strings.ReplaceAll(url.QueryEscape(route), "+", "%20"))
+}
+
+// commentedConnPool is the gorm.ConnPool which appends the comment to the sqls.
+type commentedConnPool struct {
+	gorm.ConnPool
+	comment string
+}
+
+// PrepareContext prepares the sql with the comment.
+func (p *commentedConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.ConnPool.PrepareContext(ctx, query+" "+p.comment)
+}
+
+// ExecContext executes the sql with the comment.
+func (p *commentedConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query+" "+p.comment, args...)
+}
+
+// QueryContext queries the sql with the comment.
+func (p *commentedConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query+" "+p.comment, args...)
+}
+
+// QueryRowContext queries a row by the sql with the comment.
+func (p *commentedConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query+" "+p.comment, args...)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"gorm.io/gorm"
+)
+
+// sqlCommentPattern matches the well-formed comment at the end of a sql.
+var sqlCommentPattern = regexp.MustCompile(` /\* route='([A-Za-z0-9%._~-]*)' \*/$`)
+
+// recordingConnPool records the sqls sent to the database.
+type recordingConnPool struct {
+	gorm.ConnPool
+	queries []string
+}
+
+// recordQueries makes the repository send its sqls through the recorder.
+func recordQueries(rep Repository) *recordingConnPool {
+	recorder := &recordingConnPool{ConnPool: rep.DB().ConnPool}
+	rep.DB().ConnPool = recorder
+	rep.DB().Statement.ConnPool = recorder
+	return recorder
+}
+
+// GetDBConn returns the database, so the repository can be closed.
+func (p *recordingConnPool) GetDBConn() (*sql.DB, error) {
+	return p.ConnPool.(*sql.DB), nil
+}
+
+func (p *recordingConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.queries = append(p.queries, query)
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *recordingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.queries = append(p.queries, query)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *recordingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.queries = append(p.queries, query)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
+
+func TestSQLComment_Appended(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.SQLComment = true
+	rep, logs := prepareForObservedRepositoryTest(t, conf)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	recorder := recordQueries(rep)
+	route := "GET /api/categories?name='x' */ DROP TABLE unique_record; --"
+	rep = rep.WithContext(logger.WithRoute(context.Background(), route))
+
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "first"}).Error)
+	var records []uniqueRecord
+	assert.NoError(t, rep.Find(&records).Error)
+
+	require.Len(t, recorder.queries, 2)
+	for _, query := range recorder.queries {
+		match := sqlCommentPattern.FindStringSubmatch(query)
+		if assert.NotNil(t, match, query) {
+			assert.Equal(t, 1, strings.Count(query, "*/"))
+			decoded, err := url.QueryUnescape(match[1])
+			assert.NoError(t, err)
+			assert.Equal(t, route, decoded)
+		}
+	}
+	assert.Len(t, records, 1)
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.Message, "route=")
+	}
+}
+
+func TestSQLComment_NoRoute(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.SQLComment = true
+	rep, _ := prepareForObservedRepositoryTest(t, conf)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+	recorder := recordQueries(rep)
+
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "first"}).Error)
+
+	require.Len(t, recorder.queries, 1)
+	assert.NotContains(t, recorder.queries[0], "/*")
+}
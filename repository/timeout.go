@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	timeoutCallbackName       = "repository:timeout"
+	cancelTimeoutCallbackName = "repository:cancel_timeout"
+	cancelTimeoutKey          = "repository:cancel_timeout"
+)
+
+// ErrQueryTimeout is returned when a statement exceeds database.query_timeout,
+// or a transaction exceeds database.transaction_timeout.
+var ErrQueryTimeout = errors.New("query timeout")
+
+// registerQueryTimeout registers the callbacks which give the statements the deadline of the timeout,
+// unless their contexts have a stricter one. The statement which exceeds it fails with ErrQueryTimeout.
+// Row and Rows are exempt, because their rows are read after the callbacks, such as by StreamAll.
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	withTimeout := func(db *gorm.DB) {
+		ctx, cancel := context.WithTimeout(statementContext(db), timeout)
+		db.Statement.Context = ctx
+		db.InstanceSet(cancelTimeoutKey, cancel)
+	}
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register(timeoutCallbackName, withTimeout),
+		callback.Create().After("*").Register(cancelTimeoutCallbackName, cancelTimeout),
+		callback.Query().Before("gorm:query").Register(timeoutCallbackName, withTimeout),
+		callback.Query().After("*").Register(cancelTimeoutCallbackName, cancelTimeout),
+		callback.Update().Before("gorm:update").Register(timeoutCallbackName, withTimeout),
+		callback.Update().After("*").Register(cancelTimeoutCallbackName, cancelTimeout),
+		callback.Delete().Before("gorm:delete").Register(timeoutCallbackName, withTimeout),
+		callback.Delete().After("*").Register(cancelTimeoutCallbackName, cancelTimeout),
+		callback.Raw().Before("gorm:raw").Register(timeoutCallbackName, withTimeout),
+		callback.Raw().After("*").Register(cancelTimeoutCallbackName, cancelTimeout),
+	)
+}
+
+// cancelTimeout releases the deadline of the statement after all the other callbacks, such as the preloads,
+// and makes the error of the statement which exceeded it ErrQueryTimeout.
+func cancelTimeout(db *gorm.DB) {
+	cancel, ok := db.InstanceGet(cancelTimeoutKey)
+	if !ok {
+		return
+	}
+	if db.Error != nil && errors.Is(db.Statement.Context.Err(), context.DeadlineExceeded) {
+		db.Error = fmt.Errorf("%w: %w", ErrQueryTimeout, db.Error)
+	}
+	cancel.(context.CancelFunc)()
+}
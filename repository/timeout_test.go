@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ybkuroki/go-webapp-sample/config"
+	"gorm.io/gorm"
+)
+
+// slowSQL is the sql which takes seconds on SQLite.
+const slowSQL = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) " +
+	"SELECT count(*) FROM c"
+
+func TestQueryTimeout_Exceeded(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.QueryTimeout = config.Duration(50 * time.Millisecond)
+	rep, logs := prepareForObservedRepositoryTest(t, conf)
+
+	begin := time.Now()
+	err := rep.Exec(slowSQL).Error
+
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+	assert.Less(t, time.Since(begin), 5*time.Second)
+	entries := logs.FilterMessage("[gorm] sql_timeout").All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, slowSQL, fields["statement"])
+		assert.Contains(t, fields, "elapsed")
+	}
+}
+
+func TestQueryTimeout_StricterContext(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.QueryTimeout = config.Duration(time.Hour)
+	rep, _ := prepareForObservedRepositoryTest(t, conf)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	err := rep.WithContext(ctx).Exec(slowSQL).Error
+
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+	assert.Less(t, time.Since(begin), 5*time.Second)
+}
+
+func TestQueryTimeout_NotExceeded(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.QueryTimeout = config.Duration(time.Minute)
+	rep, logs := prepareForObservedRepositoryTest(t, conf)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	assert.NoError(t, rep.Create(&uniqueRecord{Name: "first"}).Error)
+	assert.Equal(t, []string{"first"}, recordNames(t, rep))
+	assert.Zero(t, logs.FilterMessage("[gorm] sql_timeout").Len())
+}
+
+func TestTransactionTimeout_Exceeded(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.TransactionTimeout = config.Duration(50 * time.Millisecond)
+	rep, _ := prepareForObservedRepositoryTest(t, conf)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Transaction(func(tx Repository) error {
+		if err := tx.Create(&uniqueRecord{Name: "first"}).Error; err != nil {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+		return tx.Create(&uniqueRecord{Name: "second"}).Error
+	})
+
+	assert.ErrorIs(t, err, ErrQueryTimeout)
+}
+
+func TestQueryTimeout_Disabled(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	hasDeadline := true
+	require.NoError(t, rep.DB().Callback().Raw().Before("gorm:raw").Register("test:deadline", func(db *gorm.DB) {
+		_, hasDeadline = db.Statement.Context.Deadline()
+	}))
+
+	assert.NoError(t, rep.Exec("SELECT 1").Error)
+	assert.False(t, hasDeadline)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSlowTransactionThreshold is the duration from which a transaction is logged as slow when it isn't configured.
+	defaultSlowTransactionThreshold = time.Second
+	countCallbackName               = "repository:count"
+)
+
+// transactionStatsKey is the context key of the statistics of the running transaction.
+type transactionStatsKey struct{}
+
+// transactionStats is the statistics of a transaction, which are logged when it is slow.
+type transactionStats struct {
+	statements atomic.Int64
+}
+
+// registerStatementCounter registers the callbacks which count the statements executed in the transactions.
+func registerStatementCounter(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().After("*").Register(countCallbackName, countStatement),
+		callback.Query().After("*").Register(countCallbackName, countStatement),
+		callback.Update().After("*").Register(countCallbackName, countStatement),
+		callback.Delete().After("*").Register(countCallbackName, countStatement),
+		callback.Row().After("*").Register(countCallbackName, countStatement),
+		callback.Raw().After("*").Register(countCallbackName, countStatement),
+	)
+}
+
+// countStatement counts the statement when it is executed in a transaction.
+func countStatement(db *gorm.DB) {
+	if stats, ok := db.Statement.Context.Value(transactionStatsKey{}).(*transactionStats); ok {
+		stats.statements.Add(1)
+	}
+}
+
+// withTransactionStats attaches the statistics to the context of the transaction.
+func withTransactionStats(tx *gorm.DB) (*gorm.DB, *transactionStats) {
+	stats := &transactionStats{}
+	return tx.WithContext(context.WithValue(statementContext(tx), transactionStatsKey{}, stats)), stats
+}
+
+// statementContext returns the context of the db, or the background context when it has none.
+func statementContext(db *gorm.DB) context.Context {
+	if db.Statement.Context == nil {
+		return context.Background()
+	}
+	return db.Statement.Context
+}
+
+// logTransaction logs the event of the transaction with its ID.
+func (rep *repository) logTransaction(tx *gorm.DB, event string) {
+	if rep.logger != nil {
+		logger.LogTransaction(statementContext(tx), rep.logger.GetZapLogger(), event)
+	}
+}
+
+// logSlowTransaction warns the transaction which has been open longer than the threshold,
+// because it holds the locks and causes the contention.
+func (rep *repository) logSlowTransaction(begin time.Time, stats *transactionStats) {
+	elapsed := clock.Since(begin)
+	threshold := rep.slowTransactionThreshold
+	if threshold == 0 {
+		threshold = defaultSlowTransactionThreshold
+	}
+	if rep.logger == nil || elapsed < threshold {
+		return
+	}
+	rep.logger.GetZapLogger().Warnw("Slow transaction",
+		"elapsed", elapsed, "statements", stats.statements.Load(), "threshold", threshold)
+}
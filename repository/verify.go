@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Verify checks at startup that the database is reachable and has the given tables,
+// instead of letting the first request fail on a wrong DSN or a missing schema.
+// It runs the query of the version of the database server, and logs the version with the dialect.
+// The error lists every missing table.
+func (rep *repository) Verify(ctx context.Context, tables ...string) error {
+	if err := rep.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to connect the database: %w", err)
+	}
+	d, ok := dialects[rep.dialect]
+	if !ok {
+		return fmt.Errorf("unsupported dialect: %s", rep.dialect)
+	}
+	db := rep.db.WithContext(ctx)
+	var version string
+	if err := db.Raw(d.version).Row().Scan(&version); err != nil {
+		return fmt.Errorf("failed to query the version of the database: %w", err)
+	}
+	rep.logger.GetZapLogger().Infow("Verified database connection", "dialect", rep.dialect, "version", version)
+
+	var errs []error
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			errs = append(errs, fmt.Errorf("the table %s doesn't exist", table))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify_SQLiteFile(t *testing.T) {
+	conf := createRepositoryTestConfig(t)
+	conf.Database.DSN = filepath.Join(t.TempDir(), "verify.db")
+	rep, logs := prepareForObservedRepositoryTest(t, conf)
+	require.NoError(t, rep.Exec("CREATE TABLE category_master (id integer primary key, name text)").Error)
+
+	assert.NoError(t, rep.Verify(context.Background(), "category_master"))
+
+	verified := logs.FilterMessage("Verified database connection").All()
+	require.Len(t, verified, 1)
+	assert.Equal(t, SQLITE, verified[0].ContextMap()["dialect"])
+	assert.NotEmpty(t, verified[0].ContextMap()["version"])
+}
+
+func TestVerify_MissingTable(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.AutoMigrate(&uniqueRecord{}))
+
+	err := rep.Verify(context.Background(), "unique_records", "category_master", "book")
+
+	assert.EqualError(t, err, "the table category_master doesn't exist\nthe table book doesn't exist")
+}
+
+func TestVerify_Unreachable(t *testing.T) {
+	rep := prepareForRepositoryTest(t)
+	require.NoError(t, rep.Close())
+
+	err := rep.Verify(context.Background())
+
+	assert.ErrorContains(t, err, "failed to connect the database")
+}
@@ -0,0 +1,10 @@
+// Package resources provides the files embedded into the binary.
+package resources
+
+import "embed"
+
+// ConfigFiles is the default configuration files for each environment.
+// They are used when the configuration files aren't found in the configuration directory.
+//
+//go:embed config/application.* config/zaplogger.*
+var ConfigFiles embed.FS
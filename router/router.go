@@ -8,6 +8,7 @@ import (
 	"github.com/ybkuroki/go-webapp-sample/config"
 	"github.com/ybkuroki/go-webapp-sample/container"
 	"github.com/ybkuroki/go-webapp-sample/controller"
+	"github.com/ybkuroki/go-webapp-sample/logger"
 
 	echoSwagger "github.com/swaggo/echo-swagger"
 	_ "github.com/ybkuroki/go-webapp-sample/docs" // for using echo-swagger
@@ -23,6 +24,7 @@ func Init(e *echo.Echo, container container.Container) {
 	setFormatController(e, container)
 	setAccountController(e, container)
 	setHealthController(e, container)
+	setDebug(e, container)
 
 	setSwagger(container, e)
 }
@@ -91,6 +93,20 @@ func setHealthController(e *echo.Echo, container container.Container) {
 	e.GET(config.APIHealth, func(c echo.Context) error { return health.GetHealthCheck(c) })
 }
 
+func setDebug(e *echo.Echo, container container.Container) {
+	conf := container.GetConfig()
+	if !conf.Extension.DebugEndpoints {
+		return
+	}
+	if !conf.Extension.SecurityEnabled {
+		container.GetLogger().GetZapLogger().Warnf("The debug endpoints are served without the authentication.")
+	}
+	e.GET(config.APIDebugLogger, echo.WrapHandler(logger.DiagnosticsHandler(container.GetLogger())))
+	if container.GetLogger().RecentBufferEnabled() {
+		e.GET(config.APIDebugLogs, echo.WrapHandler(logger.RecentEntriesHandler(container.GetLogger())))
+	}
+}
+
 func setSwagger(container container.Container, e *echo.Echo) {
 	if container.GetConfig().Swagger.Enabled {
 		e.GET("/swagger/*", echoSwagger.WrapHandler)
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+
 	"github.com/ybkuroki/go-webapp-sample/container"
 	"github.com/ybkuroki/go-webapp-sample/model"
 	"golang.org/x/crypto/bcrypt"
@@ -8,7 +10,7 @@ import (
 
 // AccountService is a service for managing user account.
 type AccountService interface {
-	AuthenticateByUsernameAndPassword(username string, password string) (bool, *model.Account)
+	AuthenticateByUsernameAndPassword(ctx context.Context, username string, password string) (bool, *model.Account)
 }
 
 type accountService struct {
@@ -21,8 +23,9 @@ func NewAccountService(container container.Container) AccountService {
 }
 
 // AuthenticateByUsernameAndPassword authenticates by using username and plain text password.
-func (a *accountService) AuthenticateByUsernameAndPassword(username string, password string) (bool, *model.Account) {
-	rep := a.container.GetRepository()
+func (a *accountService) AuthenticateByUsernameAndPassword(ctx context.Context,
+	username string, password string) (bool, *model.Account) {
+	rep := a.container.GetRepository().WithContext(ctx)
 	logger := a.container.GetLogger()
 	account := model.Account{}
 	result, err := account.FindByName(rep, username)
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,7 +13,7 @@ func TestAuthenticateByUsernameAndPassword_Success(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewAccountService(container)
-	result, account := service.AuthenticateByUsernameAndPassword("test", "test")
+	result, account := service.AuthenticateByUsernameAndPassword(context.Background(), "test", "test")
 
 	a := model.Account{}
 	data, _ := a.FindByName(container.GetRepository(), "test")
@@ -25,7 +26,7 @@ func TestAuthenticateByUsernameAndPassword_EntityNotFound(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewAccountService(container)
-	result, account := service.AuthenticateByUsernameAndPassword("abcde", "abcde")
+	result, account := service.AuthenticateByUsernameAndPassword(context.Background(), "abcde", "abcde")
 
 	assert.Nil(t, account)
 	assert.False(t, result)
@@ -35,7 +36,7 @@ func TestAuthenticateByUsernameAndPassword_AuthenticationFailure(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewAccountService(container)
-	result, account := service.AuthenticateByUsernameAndPassword("test", "abcde")
+	result, account := service.AuthenticateByUsernameAndPassword(context.Background(), "test", "abcde")
 
 	assert.Nil(t, account)
 	assert.False(t, result)
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"github.com/ybkuroki/go-webapp-sample/container"
@@ -8,17 +9,18 @@ import (
 	"github.com/ybkuroki/go-webapp-sample/model/dto"
 	"github.com/ybkuroki/go-webapp-sample/repository"
 	"github.com/ybkuroki/go-webapp-sample/util"
+	"go.uber.org/zap"
 )
 
 // BookService is a service for managing books.
 type BookService interface {
-	FindByID(id string) (*model.Book, error)
-	FindAllBooks() (*[]model.Book, error)
-	FindAllBooksByPage(page string, size string) (*model.Page, error)
-	FindBooksByTitle(title string, page string, size string) (*model.Page, error)
-	CreateBook(dto *dto.BookDto) (*model.Book, map[string]string)
-	UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[string]string)
-	DeleteBook(id string) (*model.Book, map[string]string)
+	FindByID(ctx context.Context, id string) (*model.Book, error)
+	FindAllBooks(ctx context.Context) (*[]model.Book, error)
+	FindAllBooksByPage(ctx context.Context, page string, size string) (*model.Page, error)
+	FindBooksByTitle(ctx context.Context, title string, page string, size string) (*model.Page, error)
+	CreateBook(ctx context.Context, dto *dto.BookDto) (*model.Book, map[string]string)
+	UpdateBook(ctx context.Context, dto *dto.BookDto, id string) (*model.Book, map[string]string)
+	DeleteBook(ctx context.Context, id string) (*model.Book, map[string]string)
 }
 
 type bookService struct {
@@ -31,12 +33,12 @@ func NewBookService(container container.Container) BookService {
 }
 
 // FindByID returns one record matched book's id.
-func (b *bookService) FindByID(id string) (*model.Book, error) {
+func (b *bookService) FindByID(ctx context.Context, id string) (*model.Book, error) {
 	if !util.IsNumeric(id) {
 		return nil, errors.New("failed to fetch data")
 	}
 
-	rep := b.container.GetRepository()
+	rep := b.container.GetRepository().WithContext(ctx)
 	book := model.Book{}
 	var result *model.Book
 	var err error
@@ -47,8 +49,8 @@ func (b *bookService) FindByID(id string) (*model.Book, error) {
 }
 
 // FindAllBooks returns the list of all books.
-func (b *bookService) FindAllBooks() (*[]model.Book, error) {
-	rep := b.container.GetRepository()
+func (b *bookService) FindAllBooks(ctx context.Context) (*[]model.Book, error) {
+	rep := b.container.GetRepository().WithContext(ctx)
 	book := model.Book{}
 	result, err := book.FindAll(rep)
 	if err != nil {
@@ -59,8 +61,8 @@ func (b *bookService) FindAllBooks() (*[]model.Book, error) {
 }
 
 // FindAllBooksByPage returns the page object of all books.
-func (b *bookService) FindAllBooksByPage(page string, size string) (*model.Page, error) {
-	rep := b.container.GetRepository()
+func (b *bookService) FindAllBooksByPage(ctx context.Context, page string, size string) (*model.Page, error) {
+	rep := b.container.GetRepository().WithContext(ctx)
 	book := model.Book{}
 	result, err := book.FindAllByPage(rep, page, size)
 	if err != nil {
@@ -71,8 +73,9 @@ func (b *bookService) FindAllBooksByPage(page string, size string) (*model.Page,
 }
 
 // FindBooksByTitle returns the page object of books matched given book title.
-func (b *bookService) FindBooksByTitle(title string, page string, size string) (*model.Page, error) {
-	rep := b.container.GetRepository()
+func (b *bookService) FindBooksByTitle(ctx context.Context,
+	title string, page string, size string) (*model.Page, error) {
+	rep := b.container.GetRepository().WithContext(ctx)
 	book := model.Book{}
 	result, err := book.FindByTitle(rep, title, page, size)
 	if err != nil {
@@ -83,12 +86,12 @@ func (b *bookService) FindBooksByTitle(title string, page string, size string) (
 }
 
 // CreateBook register the given book data.
-func (b *bookService) CreateBook(dto *dto.BookDto) (*model.Book, map[string]string) {
+func (b *bookService) CreateBook(ctx context.Context, dto *dto.BookDto) (*model.Book, map[string]string) {
 	if errors := dto.Validate(); errors != nil {
 		return nil, errors
 	}
 
-	rep := b.container.GetRepository()
+	rep := b.container.GetRepository().WithContext(ctx)
 	var result *model.Book
 	var err error
 
@@ -96,9 +99,14 @@ func (b *bookService) CreateBook(dto *dto.BookDto) (*model.Book, map[string]stri
 		result, err = txCreateBook(txrep, dto)
 		return err
 	}); trerr != nil {
+		var invalid model.ValidationErrors
+		if errors.As(trerr, &invalid) {
+			return nil, validationMessages(invalid)
+		}
 		b.container.GetLogger().GetZapLogger().Errorf(trerr.Error())
 		return nil, map[string]string{"error": "Failed to the registration"}
 	}
+	b.container.GetLogger().Audit("book.create", zap.Uint("id", result.ID), zap.String("title", result.Title))
 	return result, nil
 }
 
@@ -125,12 +133,12 @@ func txCreateBook(txrep repository.Repository, dto *dto.BookDto) (*model.Book, e
 }
 
 // UpdateBook updates the given book data.
-func (b *bookService) UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[string]string) {
+func (b *bookService) UpdateBook(ctx context.Context, dto *dto.BookDto, id string) (*model.Book, map[string]string) {
 	if errors := dto.Validate(); errors != nil {
 		return nil, errors
 	}
 
-	rep := b.container.GetRepository()
+	rep := b.container.GetRepository().WithContext(ctx)
 	var result *model.Book
 	var err error
 
@@ -138,9 +146,14 @@ func (b *bookService) UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[
 		result, err = txUpdateBook(txrep, dto, id)
 		return err
 	}); trerr != nil {
+		var invalid model.ValidationErrors
+		if errors.As(trerr, &invalid) {
+			return nil, validationMessages(invalid)
+		}
 		b.container.GetLogger().GetZapLogger().Errorf(trerr.Error())
 		return nil, map[string]string{"error": "Failed to the update"}
 	}
+	b.container.GetLogger().Audit("book.update", zap.Uint("id", result.ID), zap.String("title", result.Title))
 	return result, nil
 }
 
@@ -176,8 +189,8 @@ func txUpdateBook(txrep repository.Repository, dto *dto.BookDto, id string) (*mo
 }
 
 // DeleteBook deletes the given book data.
-func (b *bookService) DeleteBook(id string) (*model.Book, map[string]string) {
-	rep := b.container.GetRepository()
+func (b *bookService) DeleteBook(ctx context.Context, id string) (*model.Book, map[string]string) {
+	rep := b.container.GetRepository().WithContext(ctx)
 	var result *model.Book
 	var err error
 
@@ -188,6 +201,7 @@ func (b *bookService) DeleteBook(id string) (*model.Book, map[string]string) {
 		b.container.GetLogger().GetZapLogger().Errorf(trerr.Error())
 		return nil, map[string]string{"error": "Failed to the delete"}
 	}
+	b.container.GetLogger().Audit("book.delete", zap.Uint("id", result.ID), zap.String("title", result.Title))
 	return result, nil
 }
 
@@ -206,3 +220,13 @@ func txDeleteBook(txrep repository.Repository, id string) (*model.Book, error) {
 
 	return result, nil
 }
+
+// validationMessages returns the messages of the fields which violate the validation rules of the model,
+// such as the ISBN which isn't a valid ISBN-13 though it has the length allowed by the DTO.
+func validationMessages(invalid model.ValidationErrors) map[string]string {
+	messages := make(map[string]string, len(invalid))
+	for _, err := range invalid {
+		messages[err.Field] = err.Error()
+	}
+	return messages
+}
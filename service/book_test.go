@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestFindByID_Success(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindByID("1")
+	result, err := service.FindByID(context.Background(), "1")
 
 	assert.Equal(t, uint(1), result.ID)
 	assert.NoError(t, err)
@@ -29,7 +30,7 @@ func TestFindByID_IdNotNumeric(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindByID("ABCD")
+	result, err := service.FindByID(context.Background(), "ABCD")
 
 	assert.Nil(t, result)
 	assert.Error(t, err, "failed to fetch data")
@@ -41,7 +42,7 @@ func TestFindByID_EntityNotFound(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindByID("9999")
+	result, err := service.FindByID(context.Background(), "9999")
 
 	assert.Nil(t, result)
 	assert.Error(t, err, "failed to fetch data")
@@ -53,7 +54,7 @@ func TestFindAllBooks_Success(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindAllBooks()
+	result, err := service.FindAllBooks(context.Background())
 
 	assert.Len(t, *result, 2)
 	assert.NoError(t, err)
@@ -65,7 +66,7 @@ func TestFindAllBooksByPage_Success(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindAllBooksByPage("0", "5")
+	result, err := service.FindAllBooksByPage(context.Background(), "0", "5")
 
 	assert.Equal(t, 2, result.TotalElements)
 	assert.Equal(t, 1, result.TotalPages)
@@ -81,7 +82,7 @@ func TestFindBooksByTitle_Success(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.FindBooksByTitle("1", "0", "5")
+	result, err := service.FindBooksByTitle(context.Background(), "1", "0", "5")
 
 	assert.Equal(t, 1, result.TotalElements)
 	assert.Equal(t, 1, result.TotalPages)
@@ -95,7 +96,7 @@ func TestCreateBook_Success(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewBookService(container)
-	result, err := service.CreateBook(createBookForCreate())
+	result, err := service.CreateBook(context.Background(), createBookForCreate())
 
 	entity := &model.Book{}
 	data, _ := entity.FindByID(container.GetRepository(), 1).Take()
@@ -108,17 +109,29 @@ func TestCreateBook_ValidationError(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewBookService(container)
-	result, err := service.CreateBook(createBookForValidationError())
+	result, err := service.CreateBook(context.Background(), createBookForValidationError())
 
 	assert.Nil(t, result)
 	assert.NotEmpty(t, err)
 }
 
+func TestCreateBook_InvalidIsbn(t *testing.T) {
+	container := test.PrepareForServiceTest()
+
+	book := createBookForCreate()
+	book.Isbn = "978-4-87311-842-1"
+	service := NewBookService(container)
+	result, err := service.CreateBook(context.Background(), book)
+
+	assert.Nil(t, result)
+	assert.Equal(t, "isbn must be a valid ISBN-13", err["isbn"])
+}
+
 func TestCreateBook_NotCategory(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewBookService(container)
-	result, err := service.CreateBook(createBookForNotCategory())
+	result, err := service.CreateBook(context.Background(), createBookForNotCategory())
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the registration", err["error"])
@@ -128,7 +141,7 @@ func TestCreateBook_NotFormat(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewBookService(container)
-	result, err := service.CreateBook(createBookForNotFormat())
+	result, err := service.CreateBook(context.Background(), createBookForNotFormat())
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the registration", err["error"])
@@ -140,7 +153,7 @@ func TestUpdateBook_Success(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.UpdateBook(createBookForCreate(), "1")
+	result, err := service.UpdateBook(context.Background(), createBookForCreate(), "1")
 
 	entity := &model.Book{}
 	data, _ := entity.FindByID(container.GetRepository(), 1).Take()
@@ -155,7 +168,7 @@ func TestUpdateBook_ValidationError(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.UpdateBook(createBookForValidationError(), "1")
+	result, err := service.UpdateBook(context.Background(), createBookForValidationError(), "1")
 
 	assert.Nil(t, result)
 	assert.NotEmpty(t, err)
@@ -167,7 +180,7 @@ func TestUpdateBook_NotEntity(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.UpdateBook(createBookForNotCategory(), "99")
+	result, err := service.UpdateBook(context.Background(), createBookForNotCategory(), "99")
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the update", err["error"])
@@ -179,7 +192,7 @@ func TestUpdateBook_NotCategory(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.UpdateBook(createBookForNotCategory(), "1")
+	result, err := service.UpdateBook(context.Background(), createBookForNotCategory(), "1")
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the update", err["error"])
@@ -191,7 +204,7 @@ func TestUpdateBook_NotFormat(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.UpdateBook(createBookForNotFormat(), "1")
+	result, err := service.UpdateBook(context.Background(), createBookForNotFormat(), "1")
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the update", err["error"])
@@ -206,7 +219,7 @@ func TestDeleteBook_Success(t *testing.T) {
 	data, _ := entity.FindByID(container.GetRepository(), 1).Take()
 
 	service := NewBookService(container)
-	result, err := service.DeleteBook("1")
+	result, err := service.DeleteBook(context.Background(), "1")
 
 	assert.Equal(t, data, result)
 	assert.Empty(t, err)
@@ -218,25 +231,25 @@ func TestDeleteBook_Error(t *testing.T) {
 	setUpTestData(container)
 
 	service := NewBookService(container)
-	result, err := service.DeleteBook("99")
+	result, err := service.DeleteBook(context.Background(), "99")
 
 	assert.Nil(t, result)
 	assert.Equal(t, "Failed to the delete", err["error"])
 }
 
 func setUpTestData(container container.Container) {
-	entity := model.NewBook("Test1", "123-123-123-1", 1, 1)
+	entity := model.NewBook("Test1", "978-4-87311-842-0", 1, 1)
 	repo := container.GetRepository()
 	_, _ = entity.Create(repo)
 
-	entity = model.NewBook("Test2", "123-123-123-2", 2, 2)
+	entity = model.NewBook("Test2", "978-4-87311-843-7", 2, 2)
 	_, _ = entity.Create(repo)
 }
 
 func createBookForCreate() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   1,
 	}
@@ -254,7 +267,7 @@ func createBookForValidationError() *dto.BookDto {
 func createBookForNotCategory() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 99,
 		FormatID:   1,
 	}
@@ -263,7 +276,7 @@ func createBookForNotCategory() *dto.BookDto {
 func createBookForNotFormat() *dto.BookDto {
 	return &dto.BookDto{
 		Title:      "Test1",
-		Isbn:       "123-123-123-1",
+		Isbn:       "978-4-87311-842-0",
 		CategoryID: 1,
 		FormatID:   99,
 	}
@@ -1,13 +1,15 @@
 package service
 
 import (
+	"context"
 	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/logger"
 	"github.com/ybkuroki/go-webapp-sample/model"
 )
 
 // CategoryService is a service for managing master data such as format and category.
 type CategoryService interface {
-	FindAllCategories() *[]model.Category
+	FindAllCategories(ctx context.Context) *[]model.Category
 }
 
 type categoryService struct {
@@ -20,12 +22,12 @@ func NewCategoryService(container container.Container) CategoryService {
 }
 
 // FindAllCategories returns the list of all categories.
-func (m *categoryService) FindAllCategories() *[]model.Category {
-	rep := m.container.GetRepository()
+func (m *categoryService) FindAllCategories(ctx context.Context) *[]model.Category {
+	rep := m.container.GetRepository().WithContext(ctx)
 	category := model.Category{}
 	result, err := category.FindAll(rep)
 	if err != nil {
-		m.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		m.container.GetLogger().GetZapLogger().Errorw(err.Error(), logger.Err(err))
 		return nil
 	}
 	return result
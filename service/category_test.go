@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ func TestFindAllCategories_Success(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewCategoryService(container)
-	result := service.FindAllCategories()
+	result := service.FindAllCategories(context.Background())
 
 	assert.Len(t, *result, 3)
 }
@@ -1,13 +1,14 @@
 package service
 
 import (
+	"context"
 	"github.com/ybkuroki/go-webapp-sample/container"
 	"github.com/ybkuroki/go-webapp-sample/model"
 )
 
 // FormatService is a service for managing master data such as format and category.
 type FormatService interface {
-	FindAllFormats() *[]model.Format
+	FindAllFormats(ctx context.Context) *[]model.Format
 }
 
 type formatService struct {
@@ -20,8 +21,8 @@ func NewFormatService(container container.Container) FormatService {
 }
 
 // FindAllFormats returns the list of all formats.
-func (m *formatService) FindAllFormats() *[]model.Format {
-	rep := m.container.GetRepository()
+func (m *formatService) FindAllFormats(ctx context.Context) *[]model.Format {
+	rep := m.container.GetRepository().WithContext(ctx)
 	format := model.Format{}
 	result, err := format.FindAll(rep)
 	if err != nil {
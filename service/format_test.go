@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ func TestFindAllFormats_Success(t *testing.T) {
 	container := test.PrepareForServiceTest()
 
 	service := NewFormatService(container)
-	result := service.FindAllFormats()
+	result := service.FindAllFormats(context.Background())
 
 	assert.Len(t, *result, 2)
 }
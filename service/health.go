@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ybkuroki/go-webapp-sample/clock"
+	"github.com/ybkuroki/go-webapp-sample/container"
+)
+
+// errLoggerNotInitialized is the error of the logger which hasn't been initialized.
+var errLoggerNotInitialized = errors.New("the logger isn't initialized")
+
+// HealthStatus is the status of the components of this application, which renders a readiness response.
+type HealthStatus struct {
+	// Healthy is true when all components are healthy.
+	Healthy  bool            `json:"healthy"`
+	Logger   ComponentStatus `json:"logger"`
+	Database ComponentStatus `json:"database"`
+}
+
+// ComponentStatus is the status of a component of this application.
+type ComponentStatus struct {
+	Healthy bool `json:"healthy"`
+	// Latency is the time taken by the check of the component.
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthService is a service for checking the status of this application.
+type HealthService interface {
+	HealthStatus(ctx context.Context) (*HealthStatus, error)
+}
+
+type healthService struct {
+	container container.Container
+}
+
+// NewHealthService is constructor.
+func NewHealthService(container container.Container) HealthService {
+	return &healthService{container: container}
+}
+
+// HealthStatus checks whether the logger is initialized and the database is reachable.
+// It returns the status of each component, and the error of the unhealthy components.
+func (h *healthService) HealthStatus(ctx context.Context) (*HealthStatus, error) {
+	loggerStatus, loggerErr := checkComponent(func() error {
+		if log := h.container.GetLogger(); log == nil || log.GetZapLogger() == nil {
+			return errLoggerNotInitialized
+		}
+		return nil
+	})
+	databaseStatus, databaseErr := checkComponent(func() error {
+		return h.container.GetRepository().Ping(ctx)
+	})
+	status := &HealthStatus{
+		Healthy:  loggerStatus.Healthy && databaseStatus.Healthy,
+		Logger:   loggerStatus,
+		Database: databaseStatus,
+	}
+	var err error
+	if loggerErr != nil {
+		err = errors.Join(err, fmt.Errorf("logger: %w", loggerErr))
+	}
+	if databaseErr != nil {
+		err = errors.Join(err, fmt.Errorf("database: %w", databaseErr))
+	}
+	return status, err
+}
+
+// checkComponent runs the check of a component, and returns its status and its error.
+func checkComponent(check func() error) (ComponentStatus, error) {
+	begin := clock.Now()
+	err := check()
+	status := ComponentStatus{Healthy: err == nil, Latency: clock.Since(begin)}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status, err
+}
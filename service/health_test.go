@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ybkuroki/go-webapp-sample/container"
+	"github.com/ybkuroki/go-webapp-sample/repository"
+	"github.com/ybkuroki/go-webapp-sample/test"
+)
+
+// unreachableRepository is the repository whose database can't be reached.
+type unreachableRepository struct {
+	repository.Repository
+}
+
+func (unreachableRepository) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestHealthStatus_Healthy(t *testing.T) {
+	container := test.PrepareForServiceTest()
+
+	service := NewHealthService(container)
+	status, err := service.HealthStatus(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, status.Healthy)
+	assert.True(t, status.Logger.Healthy)
+	assert.True(t, status.Database.Healthy)
+	assert.Empty(t, status.Database.Error)
+}
+
+func TestHealthStatus_DatabaseUnreachable(t *testing.T) {
+	c := test.PrepareForServiceTest()
+	unreachable := container.NewContainer(unreachableRepository{c.GetRepository()}, c.GetSession(), c.GetConfig(),
+		c.GetMessages(), c.GetLogger(), c.GetEnv())
+
+	service := NewHealthService(unreachable)
+	status, err := service.HealthStatus(context.Background())
+
+	assert.ErrorContains(t, err, "database: connection refused")
+	assert.False(t, status.Healthy)
+	assert.True(t, status.Logger.Healthy)
+	assert.False(t, status.Database.Healthy)
+	assert.Equal(t, "connection refused", status.Database.Error)
+}